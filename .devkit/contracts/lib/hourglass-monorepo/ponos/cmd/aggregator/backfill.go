@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var backfillCmd = &cobra.Command{
+	Use:   "backfill",
+	Short: "Run the aggregator after replaying the tasks created in a range of blocks",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		initRunCmd(cmd)
+		log, _ := logger.NewLogger(&logger.LoggerConfig{Debug: Config.Debug})
+
+		fromBlock, _ := cmd.Flags().GetUint64("from")
+		toBlock, _ := cmd.Flags().GetUint64("to")
+		chainId, _ := cmd.Flags().GetUint64("chain-id")
+		if !cmd.Flags().Changed("from") || !cmd.Flags().Changed("to") {
+			return fmt.Errorf("--from and --to are required")
+		}
+		if fromBlock > toBlock {
+			return fmt.Errorf("--from must not be after --to")
+		}
+		if chainId == 0 {
+			chainId = uint64(Config.L1ChainId)
+		}
+
+		agg, err := buildAggregator(log)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(cmd.Context())
+
+		go func() {
+			if err := agg.Start(ctx); err != nil {
+				cancel()
+			}
+		}()
+
+		go func() {
+			if err := agg.Backfill(ctx, config.ChainId(chainId), fromBlock, toBlock); err != nil {
+				log.Sugar().Errorw("Backfill failed",
+					zap.Uint64("chainId", chainId),
+					zap.Uint64("fromBlock", fromBlock),
+					zap.Uint64("toBlock", toBlock),
+					zap.Error(err),
+				)
+				cancel()
+			}
+		}()
+
+		waitForShutdown(cancel, log)
+		return nil
+	},
+}
+
+func init() {
+	backfillCmd.Flags().Uint64("from", 0, "First block to replay")
+	backfillCmd.Flags().Uint64("to", 0, "Last block to replay")
+	backfillCmd.Flags().Uint64("chain-id", 0, "Chain to replay (defaults to the L1 chain)")
+}
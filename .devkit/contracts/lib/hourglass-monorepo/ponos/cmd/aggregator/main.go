@@ -38,8 +38,10 @@ func init() {
 
 	rootCmd.PersistentFlags().Bool(aggregatorConfig.Debug, false, `"true" or "false"`)
 	rootCmd.PersistentFlags().Lookup(aggregatorConfig.Debug)
+	rootCmd.PersistentFlags().String(aggregatorConfig.DataDir, "", fmt.Sprintf("Directory for state that must survive restarts (defaults to %q unless every chain is simulated)", aggregatorConfig.DefaultDataDir))
 
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(backfillCmd)
 	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
 		key := config.KebabToSnakeCase(f.Name)
 		if err := viper.BindPFlag(key, f); err != nil {
@@ -18,12 +18,17 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/aggregator/aggregatorConfig"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/aggregator/lifecycle/runnable"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/cachedPeeringDataFetcher"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/localPeeringDataFetcher"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/peeringDataFetcher"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signer/inMemorySigner"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/keystore"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/simulations/executor/service"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionLogParser"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -36,125 +41,153 @@ var runCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		initRunCmd(cmd)
 		log, _ := logger.NewLogger(&logger.LoggerConfig{Debug: Config.Debug})
-		sugar := log.Sugar()
 
-		if err := Config.Validate(); err != nil {
-			sugar.Errorw("Invalid configuration", "error", err)
-			return err
-		}
-
-		// Load up the keystore
-		storedKeys, err := keystore.ParseKeystoreJSON(Config.Operator.SigningKeys.BLS.Keystore)
+		agg, err := buildAggregator(log)
 		if err != nil {
-			return fmt.Errorf("failed to parse keystore JSON: %w", err)
-		}
-
-		privateSigningKey, err := storedKeys.GetBN254PrivateKey(Config.Operator.SigningKeys.BLS.Password)
-		if err != nil {
-			return fmt.Errorf("failed to get private key: %w", err)
+			return err
 		}
 
-		sig := inMemorySigner.NewInMemorySigner(privateSigningKey)
+		ctx, cancel := context.WithCancel(cmd.Context())
 
-		// load the contracts and create the store
-		var coreContracts []*contracts.Contract
-		if len(Config.Contracts) > 0 {
-			log.Sugar().Infow("Loading core contracts from runtime config")
-			coreContracts, err = eigenlayer.LoadContractsFromRuntime(string(Config.Contracts))
-			if err != nil {
-				return fmt.Errorf("failed to load core contracts from runtime: %w", err)
-			}
-		} else {
-			log.Sugar().Infow("Loading core contracts from embedded config")
-			coreContracts, err = eigenlayer.LoadContracts()
-			if err != nil {
-				return fmt.Errorf("failed to load core contracts: %w", err)
+		go func() {
+			if err := agg.Start(ctx); err != nil {
+				cancel()
 			}
-		}
+		}()
 
-		imContractStore := inMemoryContractStore.NewInMemoryContractStore(coreContracts, log)
+		waitForShutdown(cancel, log)
+		return nil
+	},
+}
 
-		tlp := transactionLogParser.NewTransactionLogParser(imContractStore, log)
+// buildAggregator validates the config and creates an initialized aggregator
+func buildAggregator(log *zap.Logger) (*aggregator.Aggregator, error) {
+	sugar := log.Sugar()
 
-		sugar.Infof("Aggregator config: %+v\n", Config)
-		sugar.Infow("Building aggregator components...")
+	if err := Config.Validate(); err != nil {
+		sugar.Errorw("Invalid configuration", "error", err)
+		return nil, err
+	}
 
-		var pdf *localPeeringDataFetcher.LocalPeeringDataFetcher
-		if Config.SimulationConfig.SimulatePeering.Enabled {
-			simulatedPeers, err := peers.NewSimulatedPeersFromConfig(Config.SimulationConfig.SimulatePeering.OperatorPeers)
-			if err != nil {
-				log.Sugar().Fatalw("Failed to create simulated peers", zap.Error(err))
-			}
+	// Load up the keystore
+	storedKeys, err := keystore.ParseKeystoreJSON(Config.Operator.SigningKeys.BLS.Keystore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keystore JSON: %w", err)
+	}
 
-			pdf = localPeeringDataFetcher.NewLocalPeeringDataFetcher(&localPeeringDataFetcher.LocalPeeringDataFetcherConfig{
-				OperatorPeers: simulatedPeers,
-			}, log)
-		} else {
-			return fmt.Errorf("peering data fetcher not implemented")
-		}
+	privateSigningKey, err := storedKeys.GetBN254PrivateKey(Config.Operator.SigningKeys.BLS.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key: %w", err)
+	}
 
-		if Config.SimulationConfig.SimulateExecutors {
-			log.Sugar().Infow("Loading simulated executors from runtime config")
-			c := &aggregatorConfig.AggregatorConfig{
-				Avss:             Config.Avss,
-				Chains:           Config.Chains,
-				Operator:         Config.Operator,
-				ServerConfig:     Config.ServerConfig,
-				SimulationConfig: Config.SimulationConfig,
-				L1ChainId:        Config.L1ChainId,
-			}
-			executors, err := buildSimulatedExecutors(context.Background(), c, log)
-			if err != nil {
-				return fmt.Errorf("failed to build executors: %w", err)
-			}
-			for _, executor := range executors {
-				err := executor.Start(context.Background())
-				if err != nil {
-					return err
-				}
-			}
+	sig := inMemorySigner.NewInMemorySigner(privateSigningKey)
+
+	// load the contracts and create the store
+	var coreContracts []*contracts.Contract
+	if len(Config.Contracts) > 0 {
+		log.Sugar().Infow("Loading core contracts from runtime config")
+		coreContracts, err = eigenlayer.LoadContractsFromRuntime(string(Config.Contracts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load core contracts from runtime: %w", err)
 		}
-		agg, err := aggregator.NewAggregatorWithRpcServer(
-			Config.ServerConfig.Port,
-			&aggregator.AggregatorConfig{
-				AVSs:              Config.Avss,
-				Chains:            Config.Chains,
-				Address:           Config.Operator.Address,
-				PrivateKey:        Config.Operator.OperatorPrivateKey,
-				AggregatorUrl:     Config.ServerConfig.AggregatorUrl,
-				WriteDelaySeconds: time.Duration(Config.SimulationConfig.WriteDelaySeconds) * time.Second,
-			},
-			imContractStore,
-			tlp,
-			pdf,
-			sig,
-			log,
-		)
+	} else {
+		log.Sugar().Infow("Loading core contracts from embedded config")
+		coreContracts, err = eigenlayer.LoadContracts()
 		if err != nil {
-			return fmt.Errorf("failed to create aggregator: %w", err)
+			return nil, fmt.Errorf("failed to load core contracts: %w", err)
 		}
+	}
+
+	imContractStore := inMemoryContractStore.NewInMemoryContractStore(coreContracts, log)
+
+	tlp := transactionLogParser.NewTransactionLogParser(imContractStore, log)
 
-		if err := agg.Initialize(); err != nil {
-			return fmt.Errorf("failed to initialize aggregator: %w", err)
+	sugar.Infof("Aggregator config: %+v\n", Config)
+	sugar.Infow("Building aggregator components...")
+
+	var pdf peering.IPeeringDataFetcher
+	if Config.SimulationConfig.SimulatePeering.Enabled {
+		simulatedPeers, err := peers.NewSimulatedPeersFromConfig(Config.SimulationConfig.SimulatePeering.OperatorPeers)
+		if err != nil {
+			log.Sugar().Fatalw("Failed to create simulated peers", zap.Error(err))
 		}
 
-		ctx, cancel := context.WithCancel(cmd.Context())
+		pdf = localPeeringDataFetcher.NewLocalPeeringDataFetcher(&localPeeringDataFetcher.LocalPeeringDataFetcherConfig{
+			OperatorPeers: simulatedPeers,
+		}, log)
+	} else {
+		l1Chain := util.Find(Config.Chains, func(c *aggregatorConfig.Chain) bool {
+			return c.ChainId == Config.L1ChainId
+		})
+		chainPdf, err := peeringDataFetcher.NewPeeringDataFetcherForChain(&peeringDataFetcher.ChainPeeringDataFetcherConfig{
+			ChainId:               l1Chain.ChainId,
+			RpcUrl:                l1Chain.RpcURL,
+			FallbackRpcUrls:       l1Chain.FallbackRpcURLs,
+			AVSRegistrarAddresses: config.AvsRegistrarsForChain(Config.AvsRegistrars, l1Chain.ChainId),
+		}, imContractStore, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create peering data fetcher: %w", err)
+		}
+		pdf = cachedPeeringDataFetcher.NewCachedPeeringDataFetcher(chainPdf, &cachedPeeringDataFetcher.CachedPeeringDataFetcherConfig{
+			RefreshInterval: time.Duration(Config.PeeringRefreshIntervalSeconds) * time.Second,
+		}, log)
+	}
 
-		go func() {
-			if err := agg.Start(ctx); err != nil {
-				cancel()
+	if Config.SimulationConfig.SimulateExecutors {
+		log.Sugar().Infow("Loading simulated executors from runtime config")
+		c := &aggregatorConfig.AggregatorConfig{
+			Avss:             Config.Avss,
+			Chains:           Config.Chains,
+			Operator:         Config.Operator,
+			ServerConfig:     Config.ServerConfig,
+			SimulationConfig: Config.SimulationConfig,
+			L1ChainId:        Config.L1ChainId,
+		}
+		executors, err := buildSimulatedExecutors(context.Background(), c, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build executors: %w", err)
+		}
+		for _, executor := range executors {
+			err := executor.Start(context.Background())
+			if err != nil {
+				return nil, err
 			}
-		}()
+		}
+	}
+	agg, err := aggregator.NewAggregatorWithRpcServer(
+		Config.ServerConfig.Port,
+		&aggregator.AggregatorConfig{
+			AVSs:          Config.Avss,
+			Chains:        Config.Chains,
+			Address:       Config.Operator.Address,
+			PrivateKey:    Config.Operator.OperatorPrivateKey,
+			AggregatorUrl: Config.ServerConfig.AggregatorUrl,
+			AVSRegistrars: Config.AvsRegistrars,
+			DataDir:       Config.GetDataDir(),
+		},
+		imContractStore,
+		tlp,
+		pdf,
+		sig,
+		log,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create aggregator: %w", err)
+	}
 
-		gracefulShutdownNotifier := shutdown.CreateGracefulShutdownChannel()
-		done := make(chan bool)
-		shutdown.ListenForShutdown(gracefulShutdownNotifier, done, func() {
-			log.Sugar().Info("Shutting down...")
-			cancel()
-		}, time.Second*5, log)
+	if err := agg.Initialize(); err != nil {
+		return nil, fmt.Errorf("failed to initialize aggregator: %w", err)
+	}
+	return agg, nil
+}
 
-		return nil
-	},
+func waitForShutdown(cancel context.CancelFunc, log *zap.Logger) {
+	gracefulShutdownNotifier := shutdown.CreateGracefulShutdownChannel()
+	done := make(chan bool)
+	shutdown.ListenForShutdown(gracefulShutdownNotifier, done, func() {
+		log.Sugar().Info("Shutting down...")
+		cancel()
+	}, time.Second*5, log)
 }
 
 func initRunCmd(cmd *cobra.Command) {
@@ -40,9 +40,11 @@ func init() {
 	rootCmd.PersistentFlags().Bool(executorConfig.Debug, false, `"true" or "false"`)
 	rootCmd.PersistentFlags().Int(executorConfig.GrpcPort, 9090, "gRPC port")
 	rootCmd.PersistentFlags().String(executorConfig.PerformerNetworkName, "", "Docker network name for executor (leave blank if using localhost)")
+	rootCmd.PersistentFlags().String(executorConfig.DataDir, "", "Directory for state that must survive restarts (leave blank to keep it in memory)")
 
 	// setup sub commands
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(signingHistoryCmd)
 
 	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
 		key := config.KebabToSnakeCase(f.Name)
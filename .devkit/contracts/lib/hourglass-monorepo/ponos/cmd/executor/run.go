@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractStore/inMemoryContractStore"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contracts"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/eigenlayer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/cachedPeeringDataFetcher"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/localPeeringDataFetcher"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/peeringDataFetcher"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/rpcServer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/shutdown"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signer/inMemorySigner"
@@ -52,8 +58,8 @@ var runCmd = &cobra.Command{
 			l.Sugar().Fatal("Failed to setup RPC server", zap.Error(err))
 		}
 
-		var pdf *localPeeringDataFetcher.LocalPeeringDataFetcher
-		if Config.Simulation.SimulatePeering.Enabled {
+		var pdf peering.IPeeringDataFetcher
+		if Config.IsPeeringSimulated() {
 			simulatedPeers, err := peers.NewSimulatedPeersFromConfig(Config.Simulation.SimulatePeering.AggregatorPeers)
 			if err != nil {
 				l.Sugar().Fatalw("Failed to create simulated peers", zap.Error(err))
@@ -62,7 +68,33 @@ var runCmd = &cobra.Command{
 				AggregatorPeers: simulatedPeers,
 			}, l)
 		} else {
-			return fmt.Errorf("peering data fetcher not implemented")
+			var coreContracts []*contracts.Contract
+			if len(Config.Contracts) > 0 {
+				l.Sugar().Infow("Loading core contracts from runtime config")
+				coreContracts, err = eigenlayer.LoadContractsFromRuntime(string(Config.Contracts))
+				if err != nil {
+					return fmt.Errorf("failed to load core contracts from runtime: %w", err)
+				}
+			} else {
+				l.Sugar().Infow("Loading core contracts from embedded config")
+				coreContracts, err = eigenlayer.LoadContracts()
+				if err != nil {
+					return fmt.Errorf("failed to load core contracts: %w", err)
+				}
+			}
+			imContractStore := inMemoryContractStore.NewInMemoryContractStore(coreContracts, l)
+
+			chainPdf, err := peeringDataFetcher.NewPeeringDataFetcherForChain(&peeringDataFetcher.ChainPeeringDataFetcherConfig{
+				ChainId:               Config.L1Chain.ChainId,
+				RpcUrl:                Config.L1Chain.RpcUrl,
+				AVSRegistrarAddresses: config.AvsRegistrarsForChain(Config.AvsRegistrars, Config.L1Chain.ChainId),
+			}, imContractStore, l)
+			if err != nil {
+				return fmt.Errorf("failed to create peering data fetcher: %w", err)
+			}
+			pdf = cachedPeeringDataFetcher.NewCachedPeeringDataFetcher(chainPdf, &cachedPeeringDataFetcher.CachedPeeringDataFetcherConfig{
+				RefreshInterval: time.Duration(Config.PeeringRefreshIntervalSeconds) * time.Second,
+			}, l)
 		}
 
 		exec := executor.NewExecutor(Config, baseRpcServer, l, sig, pdf)
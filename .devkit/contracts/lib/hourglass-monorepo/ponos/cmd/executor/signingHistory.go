@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/signingHistory"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var signingHistoryCmd = &cobra.Command{
+	Use:   "signing-history",
+	Short: "Export or import the history of signed task results",
+}
+
+var signingHistoryExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the signing history as JSON. The executor must not be running.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		initRunCmd(cmd)
+
+		history, err := openSigningHistory()
+		if err != nil {
+			return err
+		}
+		defer history.Close()
+
+		output, _ := cmd.Flags().GetString("output")
+		if output == "" {
+			return history.Export(os.Stdout)
+		}
+		f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer f.Close()
+		return history.Export(f)
+	},
+}
+
+var signingHistoryImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a signing history exported from another host. The executor must not be running.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		initRunCmd(cmd)
+
+		input, _ := cmd.Flags().GetString("input")
+		if input == "" {
+			return fmt.Errorf("--input is required")
+		}
+		f, err := os.Open(input)
+		if err != nil {
+			return fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer f.Close()
+
+		history, err := openSigningHistory()
+		if err != nil {
+			return err
+		}
+		defer history.Close()
+
+		imported, err := history.Import(f)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d signing records\n", imported)
+		return nil
+	},
+}
+
+func openSigningHistory() (*signingHistory.SigningHistory, error) {
+	if Config.DataDir == "" {
+		return nil, fmt.Errorf("dataDir must be set to use the signing history")
+	}
+	l, _ := logger.NewLogger(&logger.LoggerConfig{Debug: Config.Debug})
+	return signingHistory.NewSigningHistory(&signingHistory.SigningHistoryConfig{
+		Dir: Config.DataDir,
+	}, l)
+}
+
+func init() {
+	signingHistoryExportCmd.Flags().String("output", "", "File to write the export to (defaults to stdout)")
+	signingHistoryImportCmd.Flags().String("input", "", "Exported signing history to import")
+
+	signingHistoryCmd.AddCommand(signingHistoryExportCmd)
+	signingHistoryCmd.AddCommand(signingHistoryImportCmd)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/internal/testUtils"
 	chainPoller2 "github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/EVMChainPoller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/checkpointStore"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller/caller"
@@ -62,12 +63,17 @@ func Test_EVMChainPollerIntegration(t *testing.T) {
 
 	logsChan := make(chan *chainPoller2.LogWithBlock)
 
+	checkpoints, err := checkpointStore.NewCheckpointStore(&checkpointStore.CheckpointStoreConfig{}, l)
+	if err != nil {
+		t.Fatalf("Failed to create checkpoint store: %v", err)
+	}
+
 	poller := EVMChainPoller.NewEVMChainPoller(ethereumClient, logsChan, tlp, &EVMChainPoller.EVMChainPollerConfig{
 		ChainId:                 config.ChainId_EthereumAnvil,
 		PollingInterval:         time.Duration(10) * time.Second,
 		EigenLayerCoreContracts: imContractStore.ListContractAddresses(),
 		InterestingContracts:    []string{},
-	}, l)
+	}, checkpoints, l)
 
 	ethClient, err := ethereumClient.GetEthereumContractCaller()
 	if err != nil {
@@ -155,7 +161,7 @@ func Test_EVMChainPollerIntegration(t *testing.T) {
 				task.TaskId,
 				task.BlockNumber,
 				task.OperatorSetId,
-				100,
+				aggregation.MaxThresholdBips,
 				task.Payload,
 				task.DeadlineUnixSeconds,
 				operators,
@@ -9,32 +9,43 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/aggregator/avsExecutionManager"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/EVMChainPoller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/checkpointStore"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/manualPushChainPoller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/simulatedChainPoller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/subscriptionChainPoller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller/caller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractStore"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contracts"
+	cryptoUtils "github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/crypto"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/rpcServer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionLogParser"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionManager"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"github.com/ethereum/go-ethereum/crypto"
 	"go.uber.org/zap"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 )
 
 type AggregatorConfig struct {
-	Address           string
-	AggregatorUrl     string
-	PrivateKey        string
-	WriteDelaySeconds time.Duration
-	AVSs              []*aggregatorConfig.AggregatorAvs
-	Chains            []*aggregatorConfig.Chain
+	Address       string
+	AggregatorUrl string
+	PrivateKey    string
+	// AVSRegistrars override the TaskAVSRegistrar found in the contract store per chain and AVS
+	AVSRegistrars []*config.AvsRegistrarConfig
+	AVSs          []*aggregatorConfig.AggregatorAvs
+	Chains        []*aggregatorConfig.Chain
+	// DataDir is where state that must survive restarts is kept. When empty, it is kept in memory.
+	DataDir string
 }
 
 type Aggregator struct {
@@ -54,6 +65,9 @@ type Aggregator struct {
 	// chainContractCallers is a future-proof placeholder for the ContractCaller in another PR
 	chainContractCallers map[config.ChainId]contractCaller.IContractCaller
 
+	// transactionManagers send the aggregator's transactions on each chain
+	transactionManagers map[config.ChainId]*transactionManager.TransactionManager
+
 	// avsExecutionManagers map of avsAddress to its AvsExecutionManager
 	avsExecutionManagers map[string]*avsExecutionManager.AvsExecutionManager
 
@@ -69,6 +83,9 @@ type Aggregator struct {
 	// chainEventsChan is a channel for receiving events from the chain pollers and
 	// sequentially processing them
 	chainEventsChan chan *chainPoller.LogWithBlock
+
+	// started is closed once the execution managers are initialized and the chain pollers started
+	started chan struct{}
 }
 
 func NewAggregatorWithRpcServer(
@@ -108,9 +125,11 @@ func NewAggregator(
 		signer:               signer,
 		peeringDataFetcher:   peeringDataFetcher,
 		chainContractCallers: make(map[config.ChainId]contractCaller.IContractCaller),
+		transactionManagers:  make(map[config.ChainId]*transactionManager.TransactionManager),
 		chainPollers:         make(map[config.ChainId]chainPoller.IChainPoller),
 		chainEventsChan:      make(chan *chainPoller.LogWithBlock, 10000),
 		avsExecutionManagers: make(map[string]*avsExecutionManager.AvsExecutionManager),
+		started:              make(chan struct{}),
 	}
 
 	aggregatorV1.RegisterAggregatorServiceServer(rpcServer.GetGrpcServer(), agg)
@@ -154,7 +173,7 @@ func (a *Aggregator) Initialize() error {
 			}, make(map[config.ChainId]string)),
 			AggregatorAddress: a.config.Address,
 			AggregatorUrl:     a.config.AggregatorUrl,
-			WriteDelaySeconds: a.config.WriteDelaySeconds,
+			SigningCurve:      avs.SigningCurve,
 		},
 			a.chainContractCallers,
 			a.signer,
@@ -167,19 +186,56 @@ func (a *Aggregator) Initialize() error {
 	return nil
 }
 
+// eventTopics returns the topic0 hashes of the named events declared in the contracts' ABIs
+func eventTopics(contracts []*contracts.Contract, eventNames []string) ([]string, error) {
+	topics := make([]string, 0)
+	for _, c := range contracts {
+		contractAbi, err := c.GetAbi()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ABI of contract %s: %w", c.Name, err)
+		}
+		for _, event := range contractAbi.Events {
+			topic := strings.ToLower(event.ID.Hex())
+			if slices.Contains(eventNames, event.RawName) && !slices.Contains(topics, topic) {
+				topics = append(topics, topic)
+			}
+		}
+	}
+	slices.Sort(topics)
+	return topics, nil
+}
+
 func (a *Aggregator) initializePollers() error {
 	a.logger.Sugar().Infow("Initializing chain pollers...",
 		zap.Any("chains", a.config.Chains),
 	)
 
+	var checkpointDir string
+	if a.config.DataDir != "" {
+		checkpointDir = filepath.Join(a.config.DataDir, "checkpoints")
+	}
+	checkpoints, err := checkpointStore.NewCheckpointStore(&checkpointStore.CheckpointStoreConfig{
+		Dir: checkpointDir,
+	}, a.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint store: %w", err)
+	}
+
+	topics, err := eventTopics(a.contractStore.ListContracts(), avsExecutionManager.HandledEvents)
+	if err != nil {
+		return fmt.Errorf("failed to build event topics: %w", err)
+	}
+
 	for _, chain := range a.config.Chains {
 		if _, ok := a.chainPollers[chain.ChainId]; ok {
 			a.logger.Sugar().Warnw("Chain poller already exists for chain", "chainId", chain.ChainId)
 			continue
 		}
 		ec := ethereum.NewEthereumClient(&ethereum.EthereumClientConfig{
-			BaseUrl:   chain.RpcURL,
-			BlockType: ethereum.BlockType_Latest,
+			BaseUrl:        chain.RpcURL,
+			FallbackUrls:   chain.FallbackRpcURLs,
+			BlockType:      ethereum.BlockType(chain.GetFinality()),
+			RequestTimeout: chain.GetRequestTimeout(),
 		}, a.logger)
 
 		var poller chainPoller.IChainPoller
@@ -214,8 +270,23 @@ func (a *Aggregator) initializePollers() error {
 				PollingInterval:         time.Duration(chain.PollIntervalSeconds) * time.Second,
 				EigenLayerCoreContracts: a.contractStore.ListContractAddresses(),
 				InterestingContracts:    []string{},
+				Confirmations:           chain.Confirmations,
+				EventTopics:             topics,
+			}
+			evmPoller := EVMChainPoller.NewEVMChainPoller(ec, a.chainEventsChan, a.transactionLogParser, pCfg, checkpoints, a.logger)
+			poller = evmPoller
+			if chain.WsURL != "" {
+				poller = subscriptionChainPoller.NewSubscriptionChainPoller(
+					evmPoller,
+					ec,
+					subscriptionChainPoller.NewWebSocketDialer(chain.WsURL),
+					&subscriptionChainPoller.SubscriptionChainPollerConfig{
+						ChainId:                 chain.ChainId,
+						FallbackPollingInterval: pCfg.PollingInterval,
+					},
+					a.logger,
+				)
 			}
-			poller = EVMChainPoller.NewEVMChainPoller(ec, a.chainEventsChan, a.transactionLogParser, pCfg, a.logger)
 		}
 
 		a.chainPollers[chain.ChainId] = poller
@@ -228,8 +299,10 @@ func (a *Aggregator) initializeContractCallers() (map[config.ChainId]contractCal
 	contractCallers := make(map[config.ChainId]contractCaller.IContractCaller)
 	for _, chain := range a.config.Chains {
 		ec := ethereum.NewEthereumClient(&ethereum.EthereumClientConfig{
-			BaseUrl:   chain.RpcURL,
-			BlockType: ethereum.BlockType_Latest,
+			BaseUrl:        chain.RpcURL,
+			FallbackUrls:   chain.FallbackRpcURLs,
+			BlockType:      ethereum.BlockType_Latest,
+			RequestTimeout: chain.GetRequestTimeout(),
 		}, a.logger)
 
 		var mailboxContractAddress string
@@ -248,17 +321,40 @@ func (a *Aggregator) initializeContractCallers() (map[config.ChainId]contractCal
 			mailboxContractAddress = mailboxContract.Address
 		}
 
+		avsRegistrarOverrides := config.AvsRegistrarsForChain(a.config.AVSRegistrars, chain.ChainId)
+		avsRegistrarAddress, err := contractStore.ResolveContractAddress(a.contractStore, "", config.ContractName_TaskAVSRegistrar, chain.ChainId)
+		if err != nil {
+			if chain.Simulation != nil && chain.Simulation.Enabled {
+				avsRegistrarAddress = config.AVSRegistrarSimulationAddress
+			} else if a.chainHasAvsWithoutRegistrar(chain.ChainId, avsRegistrarOverrides) {
+				return nil, fmt.Errorf("failed to find AVS registrar for chain %s: %w", chain.Name, err)
+			} else {
+				avsRegistrarAddress = ""
+			}
+		}
+
 		ethereumContractCaller, err := ec.GetEthereumContractCaller()
 		if err != nil {
 			a.logger.Sugar().Errorw("failed to get ethereum contract caller", "error", err)
 			return nil, err
 		}
 
-		cc, err := caller.NewContractCaller(&caller.ContractCallerConfig{
-			PrivateKey:          a.config.PrivateKey,
-			AVSRegistrarAddress: config.AVSRegistrarSimulationAddress, // TODO: this address should be dynamically discovered at some point
-			TaskMailboxAddress:  mailboxContractAddress,
-		}, ethereumContractCaller, a.logger)
+		tm, err := a.newTransactionManager(chain, ethereumContractCaller)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transaction manager for chain %s: %w", chain.Name, err)
+		}
+
+		ccConfig := &caller.ContractCallerConfig{
+			PrivateKey:            a.config.PrivateKey,
+			AVSRegistrarAddress:   avsRegistrarAddress,
+			AVSRegistrarAddresses: avsRegistrarOverrides,
+			TaskMailboxAddress:    mailboxContractAddress,
+		}
+		if tm != nil {
+			a.transactionManagers[chain.ChainId] = tm
+			ccConfig.TransactionManager = tm
+		}
+		cc, err := caller.NewContractCaller(ccConfig, ethereumContractCaller, a.logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create contract caller: %w", err)
 		}
@@ -268,6 +364,63 @@ func (a *Aggregator) initializeContractCallers() (map[config.ChainId]contractCal
 	return contractCallers, nil
 }
 
+// chainHasAvsWithoutRegistrar returns true if an AVS on the chain has no registrar override
+func (a *Aggregator) chainHasAvsWithoutRegistrar(chainId config.ChainId, overrides map[string]string) bool {
+	for _, avs := range a.config.AVSs {
+		if !slices.Contains(avs.ChainIds, uint(chainId)) {
+			continue
+		}
+		if _, ok := overrides[strings.ToLower(avs.Address)]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// newTransactionManager creates the manager that sends the aggregator's transactions on the chain,
+// or returns nil when the aggregator has no private key
+func (a *Aggregator) newTransactionManager(chain *aggregatorConfig.Chain, backend transactionManager.Backend) (*transactionManager.TransactionManager, error) {
+	if a.config.PrivateKey == "" {
+		return nil, nil
+	}
+	privateKey, err := cryptoUtils.StringToECDSAPrivateKey(a.config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	tmConfig := &transactionManager.TransactionManagerConfig{
+		ChainId:         chain.ChainId,
+		PrivateKey:      privateKey,
+		MaxGasFeeCap:    chain.GetMaxGasFeeCap(),
+		FeeBumpInterval: time.Duration(chain.FeeBumpIntervalSeconds) * time.Second,
+	}
+	if a.config.DataDir != "" {
+		address := crypto.PubkeyToAddress(privateKey.PublicKey)
+		tmConfig.Dir = filepath.Join(a.config.DataDir, "transactions", fmt.Sprintf("%d", chain.ChainId), strings.ToLower(address.Hex()))
+	}
+	return transactionManager.NewTransactionManager(backend, tmConfig, a.logger)
+}
+
+// Backfill replays the logs of the blocks in [fromBlock, toBlock] on the chain through the same
+// pipeline as the chain poller. It waits until Start has initialized the execution managers and
+// started the chain pollers, so that the replayed tasks are sent to the known peers.
+func (a *Aggregator) Backfill(ctx context.Context, chainId config.ChainId, fromBlock uint64, toBlock uint64) error {
+	poller, ok := a.chainPollers[chainId]
+	if !ok {
+		return fmt.Errorf("no chain poller for chain %d", chainId)
+	}
+	backfillPoller, ok := poller.(chainPoller.IBackfillChainPoller)
+	if !ok {
+		return fmt.Errorf("the chain poller for chain %d does not support backfilling", chainId)
+	}
+	select {
+	case <-a.started:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return backfillPoller.Backfill(ctx, fromBlock, toBlock)
+}
+
 // Start starts the aggregator and its components
 func (a *Aggregator) Start(ctx context.Context) error {
 	ctx, cancel := context.WithCancel(ctx)
@@ -288,10 +441,22 @@ func (a *Aggregator) Start(ctx context.Context) error {
 		}
 	}()
 
+	// resume the transactions that were pending when the aggregator stopped
+	for _, tm := range a.transactionManagers {
+		if err := tm.Start(ctx); err != nil {
+			a.logger.Sugar().Errorw("Transaction manager failed to start", "error", err)
+			cancel()
+		}
+	}
+
 	// run execution managers
+	var initialized sync.WaitGroup
 	for _, avsExec := range a.avsExecutionManagers {
+		initialized.Add(1)
 		go func(avsExec *avsExecutionManager.AvsExecutionManager) {
-			if err := avsExec.Init(ctx); err != nil {
+			err := avsExec.Init(ctx)
+			initialized.Done()
+			if err != nil {
 				a.logger.Sugar().Errorw("AVS Execution Manager failed to initialize", "error", err)
 				cancel()
 			}
@@ -311,6 +476,10 @@ func (a *Aggregator) Start(ctx context.Context) error {
 			cancel()
 		}
 	}
+	initialized.Wait()
+	if ctx.Err() == nil {
+		close(a.started)
+	}
 
 	<-ctx.Done()
 	a.logger.Sugar().Infow("Aggregator context done, stopping")
@@ -340,6 +509,7 @@ func (a *Aggregator) processLog(lwb *chainPoller.LogWithBlock) error {
 			return err
 		}
 	}
+	lwb.Handled()
 	return nil
 }
 
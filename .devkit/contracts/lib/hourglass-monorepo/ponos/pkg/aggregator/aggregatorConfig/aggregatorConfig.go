@@ -4,16 +4,30 @@ import (
 	"encoding/json"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"math/big"
 	"sigs.k8s.io/yaml"
 	"slices"
 	"strings"
+	"time"
 )
 
 const (
-	Debug = "debug"
+	Debug   = "debug"
+	DataDir = "data-dir"
+)
+
+// DefaultDataDir is where state that must survive restarts is kept when dataDir is not set and the
+// aggregator follows a real chain
+const DefaultDataDir = "aggregator-data"
+
+const (
+	Finality_Latest    = "latest"
+	Finality_Safe      = "safe"
+	Finality_Finalized = "finalized"
 )
 
 type ChainSimulation struct {
@@ -29,6 +43,56 @@ type Chain struct {
 	RpcURL              string           `json:"rpcUrl" yaml:"rpcUrl"`
 	PollIntervalSeconds int              `json:"pollIntervalSeconds" yaml:"pollIntervalSeconds"`
 	Simulation          *ChainSimulation `json:"simulation" yaml:"simulation"`
+
+	// FallbackRpcURLs are more RPC endpoints for the chain. Reads go to the healthiest endpoint and
+	// fail over to the others.
+	FallbackRpcURLs []string `json:"fallbackRpcUrls" yaml:"fallbackRpcUrls"`
+
+	// WsURL is an optional WebSocket RPC url. When set, new blocks are processed as soon as the node
+	// announces them, and the chain is polled every pollIntervalSeconds only while disconnected.
+	WsURL string `json:"wsUrl" yaml:"wsUrl"`
+
+	// Finality is the head the chain poller follows before emitting a block's logs: latest, safe or
+	// finalized. Defaults to latest.
+	Finality string `json:"finality" yaml:"finality"`
+
+	// Confirmations is how many blocks a block must be behind the latest block before its logs are
+	// emitted. Only applies to the latest finality.
+	Confirmations uint64 `json:"confirmations" yaml:"confirmations"`
+
+	// MaxFeePerGasGwei is the highest fee per gas stuck transactions are replaced with. 0 means no
+	// limit.
+	MaxFeePerGasGwei uint64 `json:"maxFeePerGasGwei" yaml:"maxFeePerGasGwei"`
+
+	// FeeBumpIntervalSeconds is how long a transaction may stay pending before it is replaced with
+	// higher fees. Defaults to 30.
+	FeeBumpIntervalSeconds int `json:"feeBumpIntervalSeconds" yaml:"feeBumpIntervalSeconds"`
+
+	// RequestTimeoutSeconds bounds every request to an RPC endpoint, before it is retried on the
+	// next one. Defaults to 10. Raise it for providers that are slow to answer large eth_getLogs
+	// queries.
+	RequestTimeoutSeconds int `json:"requestTimeoutSeconds" yaml:"requestTimeoutSeconds"`
+}
+
+// GetFinality returns the configured finality, defaulting to latest
+func (c *Chain) GetFinality() string {
+	if c.Finality == "" {
+		return Finality_Latest
+	}
+	return c.Finality
+}
+
+// GetRequestTimeout returns the RPC request timeout, or 0 to use the client's default
+func (c *Chain) GetRequestTimeout() time.Duration {
+	return time.Duration(c.RequestTimeoutSeconds) * time.Second
+}
+
+// GetMaxGasFeeCap returns the max fee per gas in wei, or nil when there is no limit
+func (c *Chain) GetMaxGasFeeCap() *big.Int {
+	if c.MaxFeePerGasGwei == 0 {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(c.MaxFeePerGasGwei), big.NewInt(params.GWei))
 }
 
 func (c *Chain) Validate() field.ErrorList {
@@ -45,6 +109,21 @@ func (c *Chain) Validate() field.ErrorList {
 	if c.RpcURL == "" {
 		allErrors = append(allErrors, field.Required(field.NewPath("rpcUrl"), "rpcUrl is required"))
 	}
+	if c.WsURL != "" && !strings.HasPrefix(c.WsURL, "ws://") && !strings.HasPrefix(c.WsURL, "wss://") {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("wsUrl"), c.WsURL, "wsUrl must be a ws:// or wss:// url"))
+	}
+	finalities := []string{Finality_Latest, Finality_Safe, Finality_Finalized}
+	if !slices.Contains(finalities, c.GetFinality()) {
+		allErrors = append(allErrors, field.NotSupported(field.NewPath("finality"), c.Finality, finalities))
+	} else if c.Confirmations > 0 && c.GetFinality() != Finality_Latest {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("confirmations"), c.Confirmations, "confirmations can only be used with the latest finality"))
+	}
+	if c.FeeBumpIntervalSeconds < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("feeBumpIntervalSeconds"), c.FeeBumpIntervalSeconds, "feeBumpIntervalSeconds must not be negative"))
+	}
+	if c.RequestTimeoutSeconds < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("requestTimeoutSeconds"), c.RequestTimeoutSeconds, "requestTimeoutSeconds must not be negative"))
+	}
 	return allErrors
 }
 
@@ -90,9 +169,6 @@ type SimulationConfig struct {
 
 	// SimulatePeering is used by the LocalPeeringDataFetcher to simulate fetching peering data on-chain
 	SimulatePeering *config.SimulatedPeeringConfig `json:"simulatePeering" yaml:"simulatePeering"`
-
-	// WriteDelaySeconds is used to slow the aggregator's submission to meet TaskMailbox validation requirements.
-	WriteDelaySeconds int64 `json:"writeDelaySeconds" yaml:"writeDelaySeconds"`
 }
 
 type ServerConfig struct {
@@ -123,6 +199,32 @@ type AggregatorConfig struct {
 
 	// Contracts is an optional field to override the addresses and ABIs for the core contracts that are loaded
 	Contracts json.RawMessage `json:"contracts" yaml:"contracts"`
+
+	// AvsRegistrars override the TaskAVSRegistrar of an AVS on a chain. AVSs without an entry use the
+	// registrar found in the contract store.
+	AvsRegistrars []*config.AvsRegistrarConfig `json:"avsRegistrars" yaml:"avsRegistrars"`
+
+	// PeeringRefreshIntervalSeconds is how long peers read from the chain are cached. Defaults to 60.
+	PeeringRefreshIntervalSeconds int `json:"peeringRefreshIntervalSeconds" yaml:"peeringRefreshIntervalSeconds"`
+
+	// DataDir is where the aggregator keeps state that must survive restarts, such as the last
+	// processed block of each chain. Defaults to DefaultDataDir unless every chain is simulated.
+	DataDir string `json:"dataDir" yaml:"dataDir"`
+}
+
+// GetDataDir returns the configured data dir. When it is not set, state is kept in memory if every
+// chain is simulated and in DefaultDataDir otherwise, so that checkpoints of real chains survive
+// restarts.
+func (arc *AggregatorConfig) GetDataDir() string {
+	if arc.DataDir != "" {
+		return arc.DataDir
+	}
+	for _, chain := range arc.Chains {
+		if chain.Simulation == nil || !chain.Simulation.Enabled {
+			return DefaultDataDir
+		}
+	}
+	return ""
 }
 
 func (arc *AggregatorConfig) Validate() error {
@@ -156,6 +258,14 @@ func (arc *AggregatorConfig) Validate() error {
 		}
 	}
 
+	for i, registrar := range arc.AvsRegistrars {
+		allErrors = append(allErrors, registrar.Validate(field.NewPath("avsRegistrars").Index(i))...)
+	}
+
+	if arc.PeeringRefreshIntervalSeconds < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("peeringRefreshIntervalSeconds"), arc.PeeringRefreshIntervalSeconds, "peeringRefreshIntervalSeconds must not be negative"))
+	}
+
 	if len(arc.Avss) == 0 {
 		allErrors = append(allErrors, field.Required(field.NewPath("avss"), "at least one avs is required"))
 	} else {
@@ -186,7 +296,8 @@ func NewAggregatorConfigFromYamlBytes(data []byte) (*AggregatorConfig, error) {
 
 func NewAggregatorConfig() *AggregatorConfig {
 	return &AggregatorConfig{
-		Debug: viper.GetBool(config.NormalizeFlagName(Debug)),
+		Debug:   viper.GetBool(config.NormalizeFlagName(Debug)),
+		DataDir: viper.GetString(config.NormalizeFlagName(DataDir)),
 		SimulationConfig: SimulationConfig{
 			Enabled: viper.GetBool("enabled"),
 		},
@@ -72,6 +72,75 @@ func Test_AggregatorConfig(t *testing.T) {
 	})
 }
 
+func Test_ChainFinality(t *testing.T) {
+	newChain := func(finality string, confirmations uint64) *Chain {
+		return &Chain{
+			Name:          "ethereum",
+			ChainId:       1,
+			RpcURL:        "http://localhost:8545",
+			Finality:      finality,
+			Confirmations: confirmations,
+		}
+	}
+
+	t.Run("Should default to the latest finality", func(t *testing.T) {
+		c := newChain("", 0)
+		assert.Empty(t, c.Validate())
+		assert.Equal(t, Finality_Latest, c.GetFinality())
+	})
+	t.Run("Should accept confirmations with the latest finality", func(t *testing.T) {
+		assert.Empty(t, newChain(Finality_Latest, 12).Validate())
+	})
+	t.Run("Should accept safe and finalized", func(t *testing.T) {
+		assert.Empty(t, newChain(Finality_Safe, 0).Validate())
+		assert.Empty(t, newChain(Finality_Finalized, 0).Validate())
+	})
+	t.Run("Should reject an unknown finality", func(t *testing.T) {
+		assert.NotEmpty(t, newChain("pending", 0).Validate())
+	})
+	t.Run("Should reject confirmations with the finalized finality", func(t *testing.T) {
+		assert.NotEmpty(t, newChain(Finality_Finalized, 2).Validate())
+	})
+	t.Run("Should reject a non websocket wsUrl", func(t *testing.T) {
+		c := newChain("", 0)
+		c.WsURL = "wss://localhost:8546"
+		assert.Empty(t, c.Validate())
+		c.WsURL = "http://localhost:8546"
+		assert.NotEmpty(t, c.Validate())
+	})
+	t.Run("Should parse finality from yaml", func(t *testing.T) {
+		c, err := NewAggregatorConfigFromYamlBytes([]byte(`
+chains:
+  - name: ethereum
+    chainId: 1
+    rpcUrl: http://localhost:8545
+    finality: latest
+    confirmations: 6
+`))
+		assert.Nil(t, err)
+		assert.Equal(t, Finality_Latest, c.Chains[0].Finality)
+		assert.Equal(t, uint64(6), c.Chains[0].Confirmations)
+	})
+}
+
+func Test_AggregatorDataDir(t *testing.T) {
+	simulated := &Chain{ChainId: 1, Simulation: &ChainSimulation{Enabled: true}}
+	real := &Chain{ChainId: 1, RpcURL: "http://localhost:8545"}
+
+	t.Run("Should use the configured data dir", func(t *testing.T) {
+		c := &AggregatorConfig{DataDir: "/var/lib/aggregator", Chains: []*Chain{real}}
+		assert.Equal(t, "/var/lib/aggregator", c.GetDataDir())
+	})
+	t.Run("Should default the data dir for a real chain", func(t *testing.T) {
+		c := &AggregatorConfig{Chains: []*Chain{simulated, real}}
+		assert.Equal(t, DefaultDataDir, c.GetDataDir())
+	})
+	t.Run("Should keep state in memory when every chain is simulated", func(t *testing.T) {
+		c := &AggregatorConfig{Chains: []*Chain{simulated}}
+		assert.Empty(t, c.GetDataDir())
+	})
+}
+
 const (
 	validJsonChainsOnly = `
 {
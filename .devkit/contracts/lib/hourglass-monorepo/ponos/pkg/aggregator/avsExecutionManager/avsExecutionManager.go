@@ -10,22 +10,57 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/taskSession"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 	"go.uber.org/zap"
+	"math/big"
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// HandledEvents are the events HandleLog acts on
+var HandledEvents = []string{"TaskCreated", "OperatorAddedToOperatorSet", "OperatorRemovedFromOperatorSet"}
+
+const (
+	DefaultTaskRetryDelay = time.Second
+
+	// maxTaskRetryDelay caps the delay between attempts to handle a task that keeps failing
+	maxTaskRetryDelay = 30 * time.Second
+)
+
+// errTaskInflight is returned by HandleTask for a task that already has a session
+var errTaskInflight = errors.New("task is already being processed")
+
 type AvsExecutionManagerConfig struct {
 	AvsAddress               string
 	SupportedChainIds        []config.ChainId
 	MailboxContractAddresses map[config.ChainId]string
 	AggregatorAddress        string
 	AggregatorUrl            string
-	WriteDelaySeconds        time.Duration
+
+	// SigningCurve is the curve executors sign results with (bn254, bls381)
+	SigningCurve string
+
+	// BroadcastRetryPolicy controls how tasks are sent again to operators that did not accept them
+	// or did not return a result. Nil uses the defaults.
+	BroadcastRetryPolicy *taskSession.BroadcastRetryPolicy
+
+	// SubmissionConcurrency is how many task results are submitted on chain at the same time.
+	// Defaults to 4.
+	SubmissionConcurrency int
+
+	// HeadPollInterval is how often the chain head is checked while a result waits for the chain to
+	// move past the block its task was created in. Defaults to 1s.
+	HeadPollInterval time.Duration
+
+	// TaskRetryDelay is how long a task that failed to be handled, e.g. because an RPC call failed,
+	// waits before it is handled again. The delay doubles with every attempt. Defaults to 1s.
+	TaskRetryDelay time.Duration
 }
 
 type operatorSetRegistrationData struct {
@@ -47,11 +82,30 @@ type AvsExecutionManager struct {
 
 	operatorPeers map[string]*peering.OperatorPeerInfo
 
-	taskQueue chan *types.Task
+	taskQueue chan *queuedTask
 
 	resultsQueue chan *taskSession.TaskSession
 
+	// submissionQueue holds the results waiting for a submitter
+	submissionQueue chan *taskSession.TaskSession
+
 	inflightTasks sync.Map
+
+	// orphanedTasks are tasks (taskId --> block hash) whose creating block was orphaned by a chain
+	// reorganization before a session was started for them
+	orphanedTasks sync.Map
+
+	// expiredTasks counts the tasks skipped because their deadline passed before they were handled
+	expiredTasks atomic.Uint64
+}
+
+// queuedTask is a task waiting to be handled and the callback marking its log as handled
+type queuedTask struct {
+	task    *types.Task
+	handled func()
+
+	// retryDelay is the delay before the last retry, zero until the task failed to be handled
+	retryDelay time.Duration
 }
 
 func NewAvsExecutionManager(
@@ -68,8 +122,9 @@ func NewAvsExecutionManager(
 		signer:               signer,
 		peeringDataFetcher:   peeringDataFetcher,
 		inflightTasks:        sync.Map{},
-		taskQueue:            make(chan *types.Task, 10000),
+		taskQueue:            make(chan *queuedTask, 10000),
 		resultsQueue:         make(chan *taskSession.TaskSession, 10000),
+		submissionQueue:      make(chan *taskSession.TaskSession, 10000),
 	}
 	return manager
 }
@@ -111,43 +166,49 @@ func (em *AvsExecutionManager) Start(ctx context.Context) error {
 		zap.Any("supportedChainIds", em.config.SupportedChainIds),
 		zap.String("avsAddress", em.config.AvsAddress),
 	)
+	for i := 0; i < em.submissionConcurrency(); i++ {
+		go em.runSubmitter(ctx)
+	}
 	for {
 		select {
-		case task := <-em.taskQueue:
+		case queued := <-em.taskQueue:
+			task := queued.task
 			em.logger.Sugar().Infow("Received task from queue",
 				zap.String("taskId", task.TaskId),
 			)
-			if err := em.HandleTask(ctx, task); err != nil {
-				em.logger.Sugar().Errorw("Failed to handle task",
-					"taskId", task.TaskId,
-					"error", err,
+			err := em.HandleTask(ctx, task)
+			if err != nil && !errors.Is(err, errTaskInflight) {
+				em.retryTask(ctx, queued, err)
+				continue
+			}
+			if err != nil {
+				em.logger.Sugar().Warnw("Task is already being processed",
+					zap.String("taskId", task.TaskId),
 				)
 			}
+			queued.handled()
 		case result := <-em.resultsQueue:
 			em.logger.Sugar().Infow("Received task result", zap.Any("taskSession", result))
 
-			if chainCaller, ok := em.chainContractCallers[result.Task.ChainId]; ok {
-				em.logger.Sugar().Infow("Calling chain contract", zap.Uint("chainId", uint(result.Task.ChainId)))
-
-				// TODO: (brandon c) remove this and handle case of submission to same block task was created.
-				time.Sleep(em.config.WriteDelaySeconds)
+			if current, ok := em.inflightTasks.Load(result.Task.TaskId); !ok || current != result {
+				em.logger.Sugar().Warnw("Dropping result of a task that was orphaned by a chain reorganization",
+					zap.String("taskId", result.Task.TaskId),
+					zap.String("blockHash", result.Task.BlockHash),
+				)
+				continue
+			}
 
+			if _, ok := em.chainContractCallers[result.Task.ChainId]; ok {
 				if result.AggregateCertificate == nil {
 					em.logger.Sugar().Errorw("Received nil aggregate certificate", zap.String("taskId", result.Task.TaskId))
 					return fmt.Errorf("received nil aggregate certificate")
 				}
 
-				receipt, err := chainCaller.SubmitTaskResult(ctx, result.AggregateCertificate)
-				if err != nil {
-					// TODO: emit metric
-					em.logger.Sugar().Errorw("Failed to submit task result", "error", err)
-				} else {
-					em.logger.Sugar().Infow("Successfully submitted task result",
-						zap.String("taskId", result.Task.TaskId),
-						zap.String("transactionHash", receipt.TxHash.String()),
-					)
-				}
-
+				em.submissionQueue <- result
+				em.logger.Sugar().Infow("Scheduled task result for submission",
+					zap.String("taskId", result.Task.TaskId),
+					zap.Uint64("taskBlockNumber", result.Task.BlockNumber),
+				)
 				continue
 			}
 			// TODO: emit metric
@@ -160,6 +221,42 @@ func (em *AvsExecutionManager) Start(ctx context.Context) error {
 	}
 }
 
+// retryTask queues a task that failed to be handled again once a delay has passed, which doubles with
+// every attempt. Its log stays unhandled meanwhile, so that the block of the task is not checkpointed
+// and the task is backfilled if the aggregator stops before it was handled. A task whose deadline
+// passes while it is retried is dropped the next time it is handled.
+func (em *AvsExecutionManager) retryTask(ctx context.Context, queued *queuedTask, err error) {
+	if queued.retryDelay == 0 {
+		queued.retryDelay = em.taskRetryDelay()
+	} else {
+		queued.retryDelay = min(queued.retryDelay*2, maxTaskRetryDelay)
+	}
+	delay := queued.retryDelay
+	em.logger.Sugar().Errorw("Failed to handle task, retrying",
+		zap.String("taskId", queued.task.TaskId),
+		zap.Duration("retryIn", delay),
+		zap.Error(err),
+	)
+	go func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		select {
+		case em.taskQueue <- queued:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+func (em *AvsExecutionManager) taskRetryDelay() time.Duration {
+	if em.config.TaskRetryDelay <= 0 {
+		return DefaultTaskRetryDelay
+	}
+	return em.config.TaskRetryDelay
+}
+
 // HandleLog processes logs from the chain poller
 func (em *AvsExecutionManager) HandleLog(lwb *chainPoller.LogWithBlock) error {
 	em.logger.Sugar().Infow("Received log from chain poller",
@@ -170,6 +267,21 @@ func (em *AvsExecutionManager) HandleLog(lwb *chainPoller.LogWithBlock) error {
 		return nil
 	}
 
+	if lwb.Removed {
+		switch lg.EventName {
+		case "TaskCreated":
+			return em.processRemovedTask(lwb)
+		case "OperatorAddedToOperatorSet", "OperatorRemovedFromOperatorSet":
+			// operator set changes are not undone, the peers are refetched when the aggregator restarts
+			em.logger.Sugar().Warnw("Ignoring removed operator registration event",
+				zap.String("eventName", lg.EventName),
+				zap.Uint64("blockNumber", lwb.Block.Number.Value()),
+				zap.String("blockHash", lwb.Block.Hash.Value()),
+			)
+		}
+		return nil
+	}
+
 	switch lg.EventName {
 	case "TaskCreated":
 		return em.processTask(lwb)
@@ -192,7 +304,24 @@ func (em *AvsExecutionManager) HandleTask(ctx context.Context, task *types.Task)
 		zap.String("taskId", task.TaskId),
 	)
 	if _, ok := em.inflightTasks.Load(task.TaskId); ok {
-		return fmt.Errorf("task %s is already being processed", task.TaskId)
+		return fmt.Errorf("%w: %s", errTaskInflight, task.TaskId)
+	}
+	if em.isOrphaned(task) {
+		em.logger.Sugar().Warnw("Skipping task created in an orphaned block",
+			zap.String("taskId", task.TaskId),
+			zap.String("blockHash", task.BlockHash),
+		)
+		return nil
+	}
+	if task.DeadlineUnixSeconds != nil && !time.Now().Before(*task.DeadlineUnixSeconds) {
+		expired := em.expiredTasks.Add(1)
+		em.logger.Sugar().Warnw("Skipping task whose deadline has passed",
+			zap.String("taskId", task.TaskId),
+			zap.Uint64("blockNumber", task.BlockNumber),
+			zap.Time("deadline", *task.DeadlineUnixSeconds),
+			zap.Uint64("expiredTasks", expired),
+		)
+		return nil
 	}
 	ctx, cancel := context.WithDeadline(ctx, *task.DeadlineUnixSeconds)
 
@@ -202,6 +331,18 @@ func (em *AvsExecutionManager) HandleTask(ctx context.Context, task *types.Task)
 		return fmt.Errorf("failed to sign task payload: %w", err)
 	}
 
+	thresholdBips, operatorWeights, err := em.getSigningThreshold(ctx, task)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to get signing threshold: %w", err)
+	}
+
+	operatorTable, err := em.getOperatorTable(ctx, task)
+	if err != nil {
+		cancel()
+		return fmt.Errorf("failed to get operator table: %w", err)
+	}
+
 	ts, err := taskSession.NewTaskSession(
 		ctx,
 		cancel,
@@ -209,6 +350,11 @@ func (em *AvsExecutionManager) HandleTask(ctx context.Context, task *types.Task)
 		em.config.AggregatorAddress,
 		em.config.AggregatorUrl,
 		sig,
+		em.config.SigningCurve,
+		thresholdBips,
+		operatorWeights,
+		operatorTable,
+		em.config.BroadcastRetryPolicy,
 		em.resultsQueue,
 		em.logger,
 	)
@@ -227,6 +373,17 @@ func (em *AvsExecutionManager) HandleTask(ctx context.Context, task *types.Task)
 
 	em.inflightTasks.Store(task.TaskId, ts)
 
+	// the creating block may have been orphaned while the session was set up
+	if em.isOrphaned(task) {
+		em.inflightTasks.CompareAndDelete(task.TaskId, ts)
+		ts.Cancel()
+		em.logger.Sugar().Warnw("Cancelled task created in an orphaned block",
+			zap.String("taskId", task.TaskId),
+			zap.String("blockHash", task.BlockHash),
+		)
+		return nil
+	}
+
 	go func() {
 		if err := ts.Process(); err != nil {
 			em.logger.Sugar().Errorw("Failed to process task",
@@ -251,6 +408,53 @@ func (em *AvsExecutionManager) HandleTask(ctx context.Context, task *types.Task)
 	return nil
 }
 
+// getSigningThreshold returns the stake proportion the task's operator set must sign, in basis points,
+// and the stake of each recipient operator (lowercased address --> stake) at the task's block
+func (em *AvsExecutionManager) getSigningThreshold(ctx context.Context, task *types.Task) (uint16, map[string]*big.Int, error) {
+	cc, ok := em.chainContractCallers[task.ChainId]
+	if !ok {
+		return 0, nil, fmt.Errorf("no contract caller for chain %d", task.ChainId)
+	}
+	taskConfig, err := cc.GetTaskConfigForExecutorOperatorSet(task.AVSAddress, task.OperatorSetId)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get task config for operator set %d: %w", task.OperatorSetId, err)
+	}
+
+	operators := util.Map(task.RecipientOperators, func(peer *peering.OperatorPeerInfo, i uint64) string {
+		return peer.OperatorAddress
+	})
+	weights, err := cc.GetOperatorStakeWeights(ctx, task.AVSAddress, task.OperatorSetId, operators, task.BlockNumber)
+	if err != nil {
+		return 0, nil, err
+	}
+	operatorWeights := make(map[string]*big.Int, len(operators))
+	for i, operator := range operators {
+		operatorWeights[strings.ToLower(operator)] = weights[i]
+	}
+
+	em.logger.Sugar().Infow("Fetched signing threshold for task",
+		zap.String("taskId", task.TaskId),
+		zap.Uint32("operatorSetId", task.OperatorSetId),
+		zap.Uint16("stakeProportionThreshold", taskConfig.StakeProportionThreshold),
+		zap.Uint64("referenceBlock", task.BlockNumber),
+	)
+	return taskConfig.StakeProportionThreshold, operatorWeights, nil
+}
+
+// getOperatorTable returns the operator table of the task's operator set at the task's block, which
+// non-signers of the certificate are indexed and proven against. Only bn254 certificates are verified
+// against it, so it is not fetched for other curves.
+func (em *AvsExecutionManager) getOperatorTable(ctx context.Context, task *types.Task) ([]*aggregation.OperatorTableEntry, error) {
+	if !strings.EqualFold(em.config.SigningCurve, aggregation.SigningCurve_BN254) {
+		return nil, nil
+	}
+	cc, ok := em.chainContractCallers[task.ChainId]
+	if !ok {
+		return nil, fmt.Errorf("no contract caller for chain %d", task.ChainId)
+	}
+	return cc.GetOperatorTable(ctx, task.AVSAddress, task.OperatorSetId, task.BlockNumber)
+}
+
 func (em *AvsExecutionManager) HandleTaskResultFromExecutor(taskResult *types.TaskResult) error {
 	task, ok := em.inflightTasks.Load(taskResult.TaskId)
 	if !ok {
@@ -299,11 +503,53 @@ func (em *AvsExecutionManager) processTask(lwb *chainPoller.LogWithBlock) error
 		}
 	}
 	task.RecipientOperators = peers
-	em.taskQueue <- task
+	// the block of the task is checkpointed only once the task was handled
+	em.taskQueue <- &queuedTask{task: task, handled: lwb.Retain()}
 	em.logger.Sugar().Infow("Added task to queue")
 	return nil
 }
 
+// processRemovedTask cancels the session of a task whose creating block was orphaned. If the
+// task has not been picked up from the queue yet, it is remembered so that it is skipped.
+func (em *AvsExecutionManager) processRemovedTask(lwb *chainPoller.LogWithBlock) error {
+	task, err := types.NewTaskFromLog(lwb.Log, lwb.Block, lwb.Log.Address)
+	if err != nil {
+		return fmt.Errorf("failed to convert removed task: %w", err)
+	}
+	if task.AVSAddress != strings.ToLower(em.config.AvsAddress) {
+		return nil
+	}
+
+	em.orphanedTasks.Store(task.TaskId, strings.ToLower(task.BlockHash))
+	if inflight, ok := em.inflightTasks.Load(task.TaskId); ok {
+		ts := inflight.(*taskSession.TaskSession)
+		em.orphanedTasks.CompareAndDelete(task.TaskId, strings.ToLower(task.BlockHash))
+		if !strings.EqualFold(ts.Task.BlockHash, task.BlockHash) {
+			// the session is for the task included again in the new chain
+			return nil
+		}
+		em.inflightTasks.CompareAndDelete(task.TaskId, ts)
+		ts.Cancel()
+		em.logger.Sugar().Warnw("Cancelled task session for a task created in an orphaned block",
+			zap.String("taskId", task.TaskId),
+			zap.Uint64("blockNumber", task.BlockNumber),
+			zap.String("blockHash", task.BlockHash),
+		)
+	}
+	return nil
+}
+
+// ExpiredTasks returns how many tasks were skipped because their deadline passed before they were
+// handled
+func (em *AvsExecutionManager) ExpiredTasks() uint64 {
+	return em.expiredTasks.Load()
+}
+
+// isOrphaned reports whether the task was created in a block that was orphaned by a reorg
+func (em *AvsExecutionManager) isOrphaned(task *types.Task) bool {
+	return em.orphanedTasks.CompareAndDelete(task.TaskId, strings.ToLower(task.BlockHash))
+}
+
 func (em *AvsExecutionManager) parseOperatorSetData(
 	lwb *chainPoller.LogWithBlock,
 ) (operatorSetRegistrationData, error) {
@@ -0,0 +1,142 @@
+package avsExecutionManager
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signer/fauxSigner"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_HandleExpiredTask(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	em := NewAvsExecutionManager(&AvsExecutionManagerConfig{AvsAddress: "0xavs"}, nil, nil, nil, l)
+
+	deadline := time.Now().Add(-time.Second)
+	err = em.HandleTask(context.Background(), &types.Task{
+		TaskId:              "expired",
+		ChainId:             config.ChainId_EthereumAnvil,
+		DeadlineUnixSeconds: &deadline,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(1), em.ExpiredTasks())
+	_, inflight := em.inflightTasks.Load("expired")
+	assert.False(t, inflight)
+}
+
+// flakyTaskConfigCaller fails to read the task config until failures is used up
+type flakyTaskConfigCaller struct {
+	contractCaller.IContractCaller
+
+	failures atomic.Int64
+	calls    atomic.Int64
+}
+
+func (f *flakyTaskConfigCaller) GetTaskConfigForExecutorOperatorSet(avsAddress string, operatorSetId uint32) (*contractCaller.ExecutorOperatorSetTaskConfig, error) {
+	f.calls.Add(1)
+	if f.failures.Add(-1) >= 0 {
+		return nil, fmt.Errorf("connection refused")
+	}
+	return &contractCaller.ExecutorOperatorSetTaskConfig{StakeProportionThreshold: 10_000}, nil
+}
+
+func (f *flakyTaskConfigCaller) GetOperatorStakeWeights(ctx context.Context, avsAddress string, operatorSetId uint32, operators []string, blockNumber uint64) ([]*big.Int, error) {
+	return util.Map(operators, func(op string, i uint64) *big.Int {
+		return big.NewInt(1)
+	}), nil
+}
+
+func (f *flakyTaskConfigCaller) GetOperatorTable(ctx context.Context, avsAddress string, operatorSetId uint32, blockNumber uint64) ([]*aggregation.OperatorTableEntry, error) {
+	return nil, nil
+}
+
+func Test_HandleTaskRetries(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	_, pubKey, err := bn254.GenerateKeyPair()
+	require.NoError(t, err)
+
+	start := func(t *testing.T, cc *flakyTaskConfigCaller) *AvsExecutionManager {
+		em := NewAvsExecutionManager(&AvsExecutionManagerConfig{
+			AvsAddress:     "0xavs",
+			SigningCurve:   aggregation.SigningCurve_BN254,
+			TaskRetryDelay: 10 * time.Millisecond,
+		}, map[config.ChainId]contractCaller.IContractCaller{
+			config.ChainId_EthereumAnvil: cc,
+		}, fauxSigner.NewFauxSigner(), nil, l)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go func() {
+			_ = em.Start(ctx)
+		}()
+		return em
+	}
+
+	queue := func(em *AvsExecutionManager, taskId string, deadline time.Time) chan struct{} {
+		handled := make(chan struct{})
+		em.taskQueue <- &queuedTask{
+			task: &types.Task{
+				TaskId:              taskId,
+				AVSAddress:          "0xavs",
+				ChainId:             config.ChainId_EthereumAnvil,
+				DeadlineUnixSeconds: &deadline,
+				RecipientOperators: []*peering.OperatorPeerInfo{
+					{OperatorAddress: "0x1", PublicKey: pubKey, CurveType: aggregation.SigningCurve_BN254, NetworkAddress: "127.0.0.1:1"},
+				},
+			},
+			handled: func() { close(handled) },
+		}
+		return handled
+	}
+
+	t.Run("retries a task that failed to be handled before marking it handled", func(t *testing.T) {
+		cc := &flakyTaskConfigCaller{}
+		cc.failures.Store(2)
+		em := start(t, cc)
+
+		handled := queue(em, "0x1", time.Now().Add(time.Minute))
+		select {
+		case <-handled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Task was never handled")
+		}
+		assert.Equal(t, int64(3), cc.calls.Load())
+		_, inflight := em.inflightTasks.Load("0x1")
+		assert.True(t, inflight)
+	})
+
+	t.Run("marks a task handled once its deadline passed while it was retried", func(t *testing.T) {
+		cc := &flakyTaskConfigCaller{}
+		cc.failures.Store(math.MaxInt64)
+		em := start(t, cc)
+
+		handled := queue(em, "0x2", time.Now().Add(200*time.Millisecond))
+		select {
+		case <-handled:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Task was never handled")
+		}
+		assert.Greater(t, cc.calls.Load(), int64(1))
+		assert.Equal(t, uint64(1), em.ExpiredTasks())
+		_, inflight := em.inflightTasks.Load("0x2")
+		assert.False(t, inflight)
+	})
+}
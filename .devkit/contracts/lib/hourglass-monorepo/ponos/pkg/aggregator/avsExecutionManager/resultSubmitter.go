@@ -0,0 +1,103 @@
+package avsExecutionManager
+
+import (
+	"context"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/taskSession"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	DefaultSubmissionConcurrency = 4
+	DefaultHeadPollInterval      = time.Second
+)
+
+// runSubmitter submits the results from the submission queue one at a time until ctx is done.
+// Start runs SubmissionConcurrency submitters, so a result waiting on the chain head or a slow
+// transaction does not hold up other tasks.
+func (em *AvsExecutionManager) runSubmitter(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case result := <-em.submissionQueue:
+			em.submitResult(ctx, result)
+		}
+	}
+}
+
+// submitResult submits the aggregated certificate of the session once the chain head has moved
+// past the block the task was created in. The TaskMailbox rejects results submitted in the same
+// block as their task.
+func (em *AvsExecutionManager) submitResult(ctx context.Context, result *taskSession.TaskSession) {
+	task := result.Task
+	chainCaller := em.chainContractCallers[task.ChainId]
+
+	if err := em.waitForBlockAfter(ctx, chainCaller, task.BlockNumber); err != nil {
+		em.logger.Sugar().Errorw("Stopped waiting to submit task result",
+			zap.String("taskId", task.TaskId),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// the task may have been orphaned by a reorg while waiting
+	if current, ok := em.inflightTasks.Load(task.TaskId); !ok || current != result {
+		em.logger.Sugar().Warnw("Dropping result of a task that was orphaned by a chain reorganization",
+			zap.String("taskId", task.TaskId),
+			zap.String("blockHash", task.BlockHash),
+		)
+		return
+	}
+
+	em.logger.Sugar().Infow("Calling chain contract", zap.Uint("chainId", uint(task.ChainId)))
+	receipt, err := chainCaller.SubmitTaskResult(ctx, result.AggregateCertificate)
+	if err != nil {
+		// TODO: emit metric
+		em.logger.Sugar().Errorw("Failed to submit task result",
+			zap.String("taskId", task.TaskId),
+			zap.Error(err),
+		)
+		return
+	}
+	em.logger.Sugar().Infow("Successfully submitted task result",
+		zap.String("taskId", task.TaskId),
+		zap.String("transactionHash", receipt.TxHash.String()),
+	)
+}
+
+// waitForBlockAfter blocks until the chain head is past blockNumber
+func (em *AvsExecutionManager) waitForBlockAfter(ctx context.Context, chainCaller contractCaller.IContractCaller, blockNumber uint64) error {
+	ticker := time.NewTicker(em.headPollInterval())
+	defer ticker.Stop()
+
+	for {
+		head, err := chainCaller.GetBlockNumber(ctx)
+		if err != nil {
+			em.logger.Sugar().Warnw("Failed to get chain head, retrying", zap.Error(err))
+		} else if head > blockNumber {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (em *AvsExecutionManager) submissionConcurrency() int {
+	if em.config.SubmissionConcurrency <= 0 {
+		return DefaultSubmissionConcurrency
+	}
+	return em.config.SubmissionConcurrency
+}
+
+func (em *AvsExecutionManager) headPollInterval() time.Duration {
+	if em.config.HeadPollInterval <= 0 {
+		return DefaultHeadPollInterval
+	}
+	return em.config.HeadPollInterval
+}
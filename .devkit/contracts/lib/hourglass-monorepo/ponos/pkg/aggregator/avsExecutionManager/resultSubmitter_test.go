@@ -0,0 +1,138 @@
+package avsExecutionManager
+
+import (
+	"context"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/taskSession"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
+	ethereumTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeSubmitCaller is a chain whose head is moved by the test. Submitting the result of the task
+// "blocking" hangs until ctx is done.
+type fakeSubmitCaller struct {
+	contractCaller.IContractCaller
+
+	head atomic.Uint64
+
+	mu        sync.Mutex
+	submitted []string
+}
+
+func (f *fakeSubmitCaller) GetBlockNumber(ctx context.Context) (uint64, error) {
+	return f.head.Load(), nil
+}
+
+func (f *fakeSubmitCaller) SubmitTaskResult(ctx context.Context, cert *aggregation.AggregatedCertificate) (*ethereumTypes.Receipt, error) {
+	if string(cert.TaskId) == "blocking" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submitted = append(f.submitted, string(cert.TaskId))
+	return &ethereumTypes.Receipt{}, nil
+}
+
+func (f *fakeSubmitCaller) submittedTasks() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string{}, f.submitted...)
+}
+
+func Test_ResultSubmission(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	newManager := func(t *testing.T, cc *fakeSubmitCaller) *AvsExecutionManager {
+		em := NewAvsExecutionManager(&AvsExecutionManagerConfig{
+			AvsAddress:            "0xavs",
+			SubmissionConcurrency: 3,
+			HeadPollInterval:      5 * time.Millisecond,
+		}, map[config.ChainId]contractCaller.IContractCaller{
+			config.ChainId_EthereumAnvil: cc,
+		}, nil, nil, l)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go func() {
+			_ = em.Start(ctx)
+		}()
+		return em
+	}
+
+	newResult := func(em *AvsExecutionManager, taskId string, blockNumber uint64) *taskSession.TaskSession {
+		ts := &taskSession.TaskSession{
+			Task: &types.Task{
+				TaskId:      taskId,
+				ChainId:     config.ChainId_EthereumAnvil,
+				BlockNumber: blockNumber,
+			},
+			AggregateCertificate: &aggregation.AggregatedCertificate{TaskId: []byte(taskId)},
+		}
+		em.inflightTasks.Store(taskId, ts)
+		return ts
+	}
+
+	t.Run("waits for the chain head to move past the task's block", func(t *testing.T) {
+		cc := &fakeSubmitCaller{}
+		cc.head.Store(100)
+		em := newManager(t, cc)
+
+		em.resultsQueue <- newResult(em, "task", 100)
+		time.Sleep(50 * time.Millisecond)
+		assert.Empty(t, cc.submittedTasks())
+
+		cc.head.Store(101)
+		require.Eventually(t, func() bool {
+			return len(cc.submittedTasks()) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("a pending submission does not hold up other results", func(t *testing.T) {
+		cc := &fakeSubmitCaller{}
+		cc.head.Store(100)
+		em := newManager(t, cc)
+
+		em.resultsQueue <- newResult(em, "blocking", 10)
+		em.resultsQueue <- newResult(em, "waiting", 100)
+		em.resultsQueue <- newResult(em, "ready", 50)
+
+		require.Eventually(t, func() bool {
+			return slices.Equal(cc.submittedTasks(), []string{"ready"})
+		}, time.Second, 5*time.Millisecond)
+
+		cc.head.Store(101)
+		require.Eventually(t, func() bool {
+			return slices.Equal(cc.submittedTasks(), []string{"ready", "waiting"})
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("drops results of tasks orphaned while waiting", func(t *testing.T) {
+		cc := &fakeSubmitCaller{}
+		cc.head.Store(100)
+		em := newManager(t, cc)
+
+		em.resultsQueue <- newResult(em, "orphaned", 100)
+		time.Sleep(20 * time.Millisecond)
+		em.inflightTasks.Delete("orphaned")
+		em.resultsQueue <- newResult(em, "kept", 100)
+
+		cc.head.Store(101)
+		require.Eventually(t, func() bool {
+			return len(cc.submittedTasks()) == 1
+		}, time.Second, 5*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		assert.Equal(t, []string{"kept"}, cc.submittedTasks())
+	})
+}
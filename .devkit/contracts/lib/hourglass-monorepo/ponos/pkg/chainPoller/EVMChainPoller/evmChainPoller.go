@@ -2,8 +2,10 @@ package EVMChainPoller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/checkpointStore"
 	"slices"
 	"strings"
 	"sync"
@@ -16,20 +18,98 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	// DefaultReorgWindowSize is the number of recent blocks kept to detect chain reorganizations
+	DefaultReorgWindowSize = 64
+
+	// DefaultLogRangeSize is the largest block range fetched with a single eth_getLogs call
+	DefaultLogRangeSize uint64 = 500
+
+	// DefaultBlockFetchConcurrency is how many blocks are fetched in parallel
+	DefaultBlockFetchConcurrency = 8
+)
+
+// errBlockReplaced is returned when a block is replaced by a reorg while its logs are fetched
+var errBlockReplaced = errors.New("block was replaced while fetching its logs")
+
 type EVMChainPollerConfig struct {
 	ChainId                 config.ChainId
 	PollingInterval         time.Duration
 	EigenLayerCoreContracts []string
 	InterestingContracts    []string
+
+	// Confirmations is how many blocks a block must be behind the head reported by the client
+	// before its logs are emitted
+	Confirmations uint64
+
+	// ReorgWindowSize is how many recent blocks are remembered to find the common ancestor after a
+	// chain reorganization. Defaults to DefaultReorgWindowSize.
+	ReorgWindowSize int
+
+	// EventTopics limits the fetched logs to events with one of these topic0 hashes. When empty,
+	// every log of the contracts is fetched.
+	EventTopics []string
+
+	// LogRangeSize is the largest block range fetched with a single eth_getLogs call. The range
+	// shrinks while the provider reports too many results. Defaults to DefaultLogRangeSize.
+	LogRangeSize uint64
+
+	// BlockFetchConcurrency is how many blocks are fetched in parallel. Defaults to
+	// DefaultBlockFetchConcurrency.
+	BlockFetchConcurrency int
+}
+
+// PollerStats counts the RPC calls made by the poller and the blocks it processed
+type PollerStats struct {
+	BlocksProcessed uint64
+	BlockRequests   uint64
+	LogRequests     uint64
+
+	// LogRangeSize is the current block range of an eth_getLogs call
+	LogRangeSize uint64
+}
+
+// blockWithLogs is a fetched block and its raw logs
+type blockWithLogs struct {
+	block *ethereum.EthereumBlock
+	logs  []*ethereum.EthereumEventLog
+}
+
+// observedBlock is a processed block and the logs that were delivered for it
+type observedBlock struct {
+	block *ethereum.EthereumBlock
+	logs  []*chainPoller.LogWithBlock
+}
+
+// pendingBlock is a delivered block that is not checkpointed yet. pending counts the logs that were
+// not handled yet, plus one while the logs are still being delivered.
+type pendingBlock struct {
+	block   *ethereum.EthereumBlock
+	pending int
 }
 
 type EVMChainPoller struct {
-	ethClient         *ethereum.Client
+	ethClient         ethereum.IEthereumClient
 	lastObservedBlock *ethereum.EthereumBlock
+	recentBlocks      []*observedBlock
 	chainEventsChan   chan *chainPoller.LogWithBlock
 	logParser         *transactionLogParser.TransactionLogParser
+	checkpointStore   *checkpointStore.CheckpointStore
 	config            *EVMChainPollerConfig
 	logger            *zap.Logger
+
+	// handledMu guards pendingBlocks and checkpointedBlock, which are updated by the consumers of
+	// the delivered logs
+	handledMu sync.Mutex
+	// pendingBlocks are the delivered blocks that are not checkpointed yet, in block order. A block
+	// is checkpointed once the logs of every block up to it were handled.
+	pendingBlocks     []*pendingBlock
+	checkpointedBlock *ethereum.EthereumBlock
+
+	logRangeSize    atomic.Uint64
+	blocksProcessed atomic.Uint64
+	blockRequests   atomic.Uint64
+	logRequests     atomic.Uint64
 }
 
 func NewEVMChainPollerDefaultConfig(chainId config.ChainId, inboxAddr string) *EVMChainPollerConfig {
@@ -40,22 +120,26 @@ func NewEVMChainPollerDefaultConfig(chainId config.ChainId, inboxAddr string) *E
 }
 
 func NewEVMChainPoller(
-	ethClient *ethereum.Client,
+	ethClient ethereum.IEthereumClient,
 	chainEventsChan chan *chainPoller.LogWithBlock,
 	logParser *transactionLogParser.TransactionLogParser,
 	config *EVMChainPollerConfig,
+	checkpointStore *checkpointStore.CheckpointStore,
 	logger *zap.Logger,
 ) *EVMChainPoller {
 	for i, contract := range config.EigenLayerCoreContracts {
 		fmt.Printf("Contract %d: %s\n", i, contract)
 	}
-	return &EVMChainPoller{
+	ecp := &EVMChainPoller{
 		ethClient:       ethClient,
 		logger:          logger,
 		chainEventsChan: chainEventsChan,
 		logParser:       logParser,
+		checkpointStore: checkpointStore,
 		config:          config,
 	}
+	ecp.logRangeSize.Store(ecp.maxLogRangeSize())
+	return ecp
 }
 
 func (ecp *EVMChainPoller) Start(ctx context.Context) error {
@@ -79,10 +163,19 @@ func (ecp *EVMChainPoller) pollForBlocks(ctx context.Context) {
 		for !shouldStop.Load() {
 			ecp.logger.Sugar().Infow("Tick")
 			err := ecp.processNextBlock(ctx)
-			if err != nil {
-				ecp.logger.Sugar().Errorw("Error processing Ethereum block.", err)
-				cancel()
-				return
+			if err != nil && ethereum.IsRetryable(err) {
+				// resumes from the last processed block on the next tick
+				ecp.logger.Sugar().Errorw("RPC failure while processing Ethereum blocks, retrying",
+					zap.Any("chainId", ecp.config.ChainId),
+					zap.Error(err),
+				)
+			} else if err != nil {
+				// stopping would silently stop picking up tasks, so keep trying from the last
+				// processed block
+				ecp.logger.Sugar().Errorw("Error processing Ethereum blocks, retrying",
+					zap.Any("chainId", ecp.config.ChainId),
+					zap.Error(err),
+				)
 			}
 			time.Sleep(ecp.config.PollingInterval)
 		}
@@ -104,16 +197,64 @@ func (ecp *EVMChainPoller) isInterestingLog(log *ethereum.EthereumEventLog) bool
 	return false
 }
 
+// getHeadBlock returns the newest block whose logs may be emitted: the head the client follows
+// (latest, safe or finalized) minus the configured confirmations. ok is false while the chain is
+// shorter than the confirmation depth.
+func (ecp *EVMChainPoller) getHeadBlock(ctx context.Context) (blockNum uint64, ok bool, err error) {
+	head, err := ecp.ethClient.GetLatestBlock(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	if head < ecp.config.Confirmations {
+		return 0, false, nil
+	}
+	return head - ecp.config.Confirmations, true, nil
+}
+
+// ProcessNewBlocks delivers the logs of every block between the last processed block and the chain
+// head. It lets other pollers decide when to look for new blocks.
+func (ecp *EVMChainPoller) ProcessNewBlocks(ctx context.Context) error {
+	return ecp.processNextBlock(ctx)
+}
+
 func (ecp *EVMChainPoller) processNextBlock(ctx context.Context) error {
-	latestBlockNum, err := ecp.ethClient.GetLatestBlock(ctx)
+	latestBlockNum, ok, err := ecp.getHeadBlock(ctx)
 	if err != nil {
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if !ok {
 		return nil
 	}
 
 	if ecp.lastObservedBlock == nil {
-		ecp.logger.Sugar().Infow("no lastObservedBlock set, initializing last observed block to latest - 1")
-		ecp.lastObservedBlock = &ethereum.EthereumBlock{
-			Number: ethereum.EthereumQuantity(latestBlockNum - 1),
+		checkpoint, err := ecp.checkpointStore.GetCheckpoint(ecp.config.ChainId)
+		if err != nil {
+			return fmt.Errorf("failed to load block checkpoint: %w", err)
+		}
+		if checkpoint != nil {
+			ecp.logger.Sugar().Infow("Resuming from block checkpoint",
+				zap.Uint64("checkpointBlock", checkpoint.BlockNumber),
+				zap.Uint64("latestBlock", latestBlockNum),
+			)
+			ecp.lastObservedBlock = &ethereum.EthereumBlock{
+				Number: ethereum.EthereumQuantity(checkpoint.BlockNumber),
+				Hash:   ethereum.EthereumHexString(checkpoint.BlockHash),
+			}
+			ecp.handledMu.Lock()
+			ecp.checkpointedBlock = ecp.lastObservedBlock
+			ecp.handledMu.Unlock()
+			if checkpoint.BlockHash != "" {
+				ecp.recentBlocks = []*observedBlock{{block: ecp.lastObservedBlock}}
+			}
+		} else if latestBlockNum == 0 {
+			// only the genesis block exists, there is no block before it to start after
+			ecp.logger.Sugar().Infow("Waiting for the first block after genesis")
+			return nil
+		} else {
+			ecp.logger.Sugar().Infow("no lastObservedBlock set, initializing last observed block to latest - 1")
+			ecp.lastObservedBlock = &ethereum.EthereumBlock{
+				Number: ethereum.EthereumQuantity(latestBlockNum - 1),
+			}
 		}
 	} else {
 		ecp.logger.Sugar().Infow("latest on chain block",
@@ -140,55 +281,318 @@ func (ecp *EVMChainPoller) processNextBlock(ctx context.Context) error {
 		return nil
 	}
 
-	var blocksToFetch []uint64
-	if latestBlockNum >= ecp.lastObservedBlock.Number.Value()+1 {
-		for i := ecp.lastObservedBlock.Number.Value() + 1; i <= latestBlockNum; i++ {
-			blocksToFetch = append(blocksToFetch, i)
-		}
-	}
+	fromBlock := ecp.lastObservedBlock.Number.Value() + 1
 	ecp.logger.Sugar().Infow("Fetching blocks with logs",
-		zap.Any("blocksToFetch", blocksToFetch),
+		zap.Uint64("fromBlock", fromBlock),
+		zap.Uint64("toBlock", latestBlockNum),
 	)
 
-	for _, blockNum := range blocksToFetch {
-		_, _, err = ecp.getBlockWithLogs(ctx, blockNum)
+	started := time.Now()
+	statsBefore := ecp.Stats()
+	defer func() {
+		stats := ecp.Stats()
+		blocks := stats.BlocksProcessed - statsBefore.BlocksProcessed
+		elapsed := time.Since(started)
+		ecp.logger.Sugar().Infow("Processed blocks",
+			zap.Any("chainId", ecp.config.ChainId),
+			zap.Uint64("blocks", blocks),
+			zap.Uint64("blockRequests", stats.BlockRequests-statsBefore.BlockRequests),
+			zap.Uint64("logRequests", stats.LogRequests-statsBefore.LogRequests),
+			zap.Duration("elapsed", elapsed),
+			zap.Float64("blocksPerSecond", float64(blocks)/max(elapsed.Seconds(), 1e-9)),
+		)
+	}()
+
+	blockNum := fromBlock
+	for blockNum <= latestBlockNum {
+		batch, err := ecp.fetchBlocksWithLogs(ctx, blockNum, latestBlockNum)
 		if err != nil {
-			ecp.logger.Sugar().Errorw("Error fetching block with logs",
-				zap.Uint64("blockNumber", blockNum),
+			ecp.logger.Sugar().Errorw("Error fetching blocks with logs",
+				zap.Uint64("fromBlock", blockNum),
 				zap.Error(err),
 			)
 			return err
 		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		for _, b := range batch {
+			if ecp.isOrphanedBy(b.block) {
+				if err := ecp.rewind(ctx); err != nil {
+					return fmt.Errorf("failed to rewind after chain reorganization: %w", err)
+				}
+				break
+			}
+
+			pb := ecp.trackBlock(b.block)
+			logs, err := ecp.deliverBlockLogs(ctx, b.block, b.logs, pb)
+			if err != nil {
+				ecp.untrackBlock(pb)
+			}
+			if errors.Is(err, errBlockReplaced) {
+				// the reorg is picked up through the parent hash on the next poll
+				ecp.logger.Sugar().Warnw("Block was replaced while fetching its logs, retrying",
+					zap.Uint64("blockNumber", b.block.Number.Value()),
+				)
+				return nil
+			}
+			if err != nil {
+				ecp.logger.Sugar().Errorw("Error delivering block logs",
+					zap.Uint64("blockNumber", b.block.Number.Value()),
+					zap.Error(err),
+				)
+				return err
+			}
+			ecp.observeBlock(b.block, logs)
+			ecp.blockLogHandled(pb)
+		}
+		blockNum = ecp.lastObservedBlock.Number.Value() + 1
 	}
 	ecp.logger.Sugar().Infow("All blocks processed",
-		zap.Any("blocksToFetch", blocksToFetch),
+		zap.Uint64("fromBlock", fromBlock),
+		zap.Uint64("toBlock", latestBlockNum),
 	)
 
 	return nil
 }
 
-func (ecp *EVMChainPoller) getBlockWithLogs(ctx context.Context, blockNum uint64) (*ethereum.EthereumBlock, []*ethereum.EthereumEventLog, error) {
-	ecp.logger.Sugar().Infow("Fetching Ethereum block with logs",
-		zap.Uint64("blockNumber", blockNum),
-	)
-	block, err := ecp.ethClient.GetBlockByNumber(ctx, blockNum)
+// Backfill replays the logs of the blocks in [fromBlock, toBlock] through the same pipeline as
+// polling. It does not move the checkpoint.
+func (ecp *EVMChainPoller) Backfill(ctx context.Context, fromBlock uint64, toBlock uint64) error {
+	if fromBlock > toBlock {
+		return fmt.Errorf("fromBlock %d is after toBlock %d", fromBlock, toBlock)
+	}
+	latestBlockNum, ok, err := ecp.getHeadBlock(ctx)
 	if err != nil {
-		return nil, nil, err
+		return fmt.Errorf("failed to get latest block: %w", err)
+	}
+	if !ok || toBlock > latestBlockNum {
+		return fmt.Errorf("toBlock %d is after the latest block %d", toBlock, latestBlockNum)
 	}
 
-	logs, err := ecp.fetchLogsForInterestingContractsForBlock(block.Number.Value())
-	if err != nil {
-		ecp.logger.Sugar().Errorw("Error fetching logs for block",
-			zap.Uint64("blockNumber", block.Number.Value()),
+	ecp.logger.Sugar().Infow("Backfilling blocks",
+		zap.Any("chainId", ecp.config.ChainId),
+		zap.Uint64("fromBlock", fromBlock),
+		zap.Uint64("toBlock", toBlock),
+	)
+	for blockNum := fromBlock; blockNum <= toBlock; {
+		batch, err := ecp.fetchBlocksWithLogs(ctx, blockNum, toBlock)
+		if err != nil {
+			return fmt.Errorf("failed to backfill blocks from %d: %w", blockNum, err)
+		}
+		if len(batch) == 0 {
+			return fmt.Errorf("failed to backfill block %d: block not found", blockNum)
+		}
+		for _, b := range batch {
+			if _, err := ecp.deliverBlockLogs(ctx, b.block, b.logs, nil); err != nil {
+				return fmt.Errorf("failed to backfill block %d: %w", b.block.Number.Value(), err)
+			}
+		}
+		blockNum += uint64(len(batch))
+	}
+	ecp.logger.Sugar().Infow("Backfill complete",
+		zap.Any("chainId", ecp.config.ChainId),
+		zap.Uint64("fromBlock", fromBlock),
+		zap.Uint64("toBlock", toBlock),
+	)
+	return nil
+}
+
+// isOrphanedBy returns true if the block does not build on the last observed block
+func (ecp *EVMChainPoller) isOrphanedBy(block *ethereum.EthereumBlock) bool {
+	lastHash := ecp.lastObservedBlock.Hash.Value()
+	if lastHash == "" || block.Number.Value() != ecp.lastObservedBlock.Number.Value()+1 {
+		return false
+	}
+	return !strings.EqualFold(block.ParentHash.Value(), lastHash)
+}
+
+// rewind walks back through the recent blocks to the newest one that is still on the canonical
+// chain, delivers removed events for the logs of every orphaned block and moves the checkpoint back
+// when it is past the ancestor, so that the replacement blocks are processed next.
+func (ecp *EVMChainPoller) rewind(ctx context.Context) error {
+	orphaned := make([]*observedBlock, 0)
+	var ancestor *observedBlock
+	for i := len(ecp.recentBlocks) - 1; i >= 0; i-- {
+		recent := ecp.recentBlocks[i]
+		canonical, err := ecp.ethClient.GetBlockByNumber(ctx, recent.block.Number.Value())
+		if err != nil {
+			return fmt.Errorf("failed to get block %d: %w", recent.block.Number.Value(), err)
+		}
+		if canonical != nil && strings.EqualFold(canonical.Hash.Value(), recent.block.Hash.Value()) {
+			ancestor = recent
+			break
+		}
+		orphaned = append(orphaned, recent)
+	}
+
+	// orphaned blocks are ordered newest first, undo their logs in reverse order
+	for _, ob := range orphaned {
+		for i := len(ob.logs) - 1; i >= 0; i-- {
+			removed := &chainPoller.LogWithBlock{
+				Log:     ob.logs[i].Log,
+				Block:   ob.block,
+				Removed: true,
+			}
+			select {
+			case ecp.chainEventsChan <- removed:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	var rewindTo *ethereum.EthereumBlock
+	if ancestor != nil {
+		rewindTo = ancestor.block
+		ecp.recentBlocks = ecp.recentBlocks[:len(ecp.recentBlocks)-len(orphaned)]
+	} else {
+		oldest := ecp.lastObservedBlock.Number.Value()
+		if len(orphaned) > 0 {
+			oldest = orphaned[len(orphaned)-1].block.Number.Value()
+		}
+		if oldest > 0 {
+			oldest--
+		}
+		ecp.logger.Sugar().Errorw("Chain reorganization is deeper than the recent block window, logs before it may be stale",
+			zap.Any("chainId", ecp.config.ChainId),
+			zap.Int("reorgWindowSize", len(ecp.recentBlocks)),
+			zap.Uint64("resumeBlock", oldest+1),
+		)
+		rewindTo = &ethereum.EthereumBlock{Number: ethereum.EthereumQuantity(oldest)}
+		ecp.recentBlocks = nil
+	}
+
+	ecp.logger.Sugar().Warnw("Chain reorganization detected, replaying from the common ancestor",
+		zap.Any("chainId", ecp.config.ChainId),
+		zap.Uint64("ancestorBlock", rewindTo.Number.Value()),
+		zap.String("ancestorHash", rewindTo.Hash.Value()),
+		zap.Int("orphanedBlocks", len(orphaned)),
+	)
+	if err := ecp.rewindCheckpoint(rewindTo); err != nil {
+		return err
+	}
+	ecp.lastObservedBlock = rewindTo
+	return nil
+}
+
+// rewindCheckpoint forgets the pending blocks after the ancestor and moves the checkpoint back to it
+// if the checkpoint is past it
+func (ecp *EVMChainPoller) rewindCheckpoint(ancestor *ethereum.EthereumBlock) error {
+	ecp.handledMu.Lock()
+	defer ecp.handledMu.Unlock()
+
+	ecp.pendingBlocks = slices.DeleteFunc(ecp.pendingBlocks, func(pb *pendingBlock) bool {
+		return pb.block.Number.Value() > ancestor.Number.Value()
+	})
+	if ecp.checkpointedBlock == nil || ecp.checkpointedBlock.Number.Value() <= ancestor.Number.Value() {
+		return nil
+	}
+	if err := ecp.saveCheckpoint(ancestor); err != nil {
+		return err
+	}
+	ecp.checkpointedBlock = ancestor
+	return nil
+}
+
+// trackBlock adds a block whose logs are about to be delivered to the pending blocks
+func (ecp *EVMChainPoller) trackBlock(block *ethereum.EthereumBlock) *pendingBlock {
+	ecp.handledMu.Lock()
+	defer ecp.handledMu.Unlock()
+	pb := &pendingBlock{block: block, pending: 1}
+	ecp.pendingBlocks = append(ecp.pendingBlocks, pb)
+	return pb
+}
+
+// untrackBlock forgets a block whose logs could not all be delivered, so that it is never
+// checkpointed and is delivered again after a restart
+func (ecp *EVMChainPoller) untrackBlock(pb *pendingBlock) {
+	ecp.handledMu.Lock()
+	defer ecp.handledMu.Unlock()
+	ecp.pendingBlocks = slices.DeleteFunc(ecp.pendingBlocks, func(p *pendingBlock) bool {
+		return p == pb
+	})
+}
+
+// blockLogHandled marks a log of the block as handled and checkpoints the newest block up to which
+// every block was handled
+func (ecp *EVMChainPoller) blockLogHandled(pb *pendingBlock) {
+	ecp.handledMu.Lock()
+	defer ecp.handledMu.Unlock()
+
+	pb.pending--
+	var handled *ethereum.EthereumBlock
+	for len(ecp.pendingBlocks) > 0 && ecp.pendingBlocks[0].pending == 0 {
+		handled = ecp.pendingBlocks[0].block
+		ecp.pendingBlocks = ecp.pendingBlocks[1:]
+	}
+	if handled == nil {
+		return
+	}
+	if err := ecp.saveCheckpoint(handled); err != nil {
+		// the next handled block saves a newer checkpoint
+		ecp.logger.Sugar().Errorw("Failed to save block checkpoint",
+			zap.Any("chainId", ecp.config.ChainId),
+			zap.Uint64("blockNumber", handled.Number.Value()),
 			zap.Error(err),
 		)
-		return nil, nil, err
+		return
+	}
+	ecp.checkpointedBlock = handled
+}
+
+// observeBlock remembers a block whose logs were delivered for reorg detection
+func (ecp *EVMChainPoller) observeBlock(block *ethereum.EthereumBlock, logs []*chainPoller.LogWithBlock) {
+	ecp.lastObservedBlock = block
+	ecp.blocksProcessed.Add(1)
+
+	windowSize := ecp.config.ReorgWindowSize
+	if windowSize <= 0 {
+		windowSize = DefaultReorgWindowSize
+	}
+	ecp.recentBlocks = append(ecp.recentBlocks, &observedBlock{block: block, logs: logs})
+	if len(ecp.recentBlocks) > windowSize {
+		ecp.recentBlocks = slices.Clone(ecp.recentBlocks[len(ecp.recentBlocks)-windowSize:])
+	}
+}
+
+func (ecp *EVMChainPoller) saveCheckpoint(block *ethereum.EthereumBlock) error {
+	if err := ecp.checkpointStore.SaveCheckpoint(&checkpointStore.Checkpoint{
+		ChainId:     ecp.config.ChainId,
+		BlockNumber: block.Number.Value(),
+		BlockHash:   block.Hash.Value(),
+	}); err != nil {
+		return fmt.Errorf("failed to save block checkpoint: %w", err)
 	}
+	return nil
+}
 
+func (ecp *EVMChainPoller) fetchBlock(ctx context.Context, blockNum uint64) (*ethereum.EthereumBlock, error) {
+	ecp.logger.Sugar().Debugw("Fetching Ethereum block",
+		zap.Uint64("blockNumber", blockNum),
+	)
+	ecp.blockRequests.Add(1)
+	block, err := ecp.ethClient.GetBlockByNumber(ctx, blockNum)
+	if err != nil {
+		return nil, err
+	}
 	if block == nil {
-		return nil, nil, nil
+		return nil, nil
 	}
 	block.ChainId = ecp.config.ChainId
+	return block, nil
+}
+
+// deliverBlockLogs decodes the interesting logs of the block and sends them to the chain events
+// channel, returning the delivered logs. When pb is set, each log counts towards the block's pending
+// logs until its consumer marks it handled.
+func (ecp *EVMChainPoller) deliverBlockLogs(ctx context.Context, block *ethereum.EthereumBlock, logs []*ethereum.EthereumEventLog, pb *pendingBlock) ([]*chainPoller.LogWithBlock, error) {
+	for _, l := range logs {
+		if l.BlockHash.Value() != "" && !strings.EqualFold(l.BlockHash.Value(), block.Hash.Value()) {
+			return nil, errBlockReplaced
+		}
+	}
 
 	ecp.logger.Sugar().Infow("Block fetched with logs",
 		"latestBlockNum", block.Number.Value(),
@@ -196,6 +600,7 @@ func (ecp *EVMChainPoller) getBlockWithLogs(ctx context.Context, blockNum uint64
 		"logCount", len(logs),
 	)
 
+	delivered := make([]*chainPoller.LogWithBlock, 0)
 	for _, l := range logs {
 		if !ecp.isInterestingLog(l) {
 			continue
@@ -209,13 +614,19 @@ func (ecp *EVMChainPoller) getBlockWithLogs(ctx context.Context, blockNum uint64
 				zap.Uint64("logIndex", l.LogIndex.Value()),
 				zap.Error(err),
 			)
-			return nil, nil, err
+			return nil, err
 		}
 
-		lwb := &chainPoller.LogWithBlock{
-			Block: block,
-			Log:   decodedLog,
+		var onHandled func()
+		if pb != nil {
+			ecp.handledMu.Lock()
+			pb.pending++
+			ecp.handledMu.Unlock()
+			onHandled = func() {
+				ecp.blockLogHandled(pb)
+			}
 		}
+		lwb := chainPoller.NewLogWithBlock(decodedLog, block, onHandled)
 		select {
 		case ecp.chainEventsChan <- lwb:
 			ecp.logger.Sugar().Infow("Enqueued log for processing",
@@ -224,20 +635,19 @@ func (ecp *EVMChainPoller) getBlockWithLogs(ctx context.Context, blockNum uint64
 				zap.String("logAddress", l.Address.Value()),
 				zap.Uint64("logIndex", l.LogIndex.Value()),
 			)
-		case <-time.After(100 * time.Millisecond):
-			ecp.logger.Sugar().Warnw("Failed to enqueue log (channel full or closed)",
-				zap.Uint64("blockNumber", block.Number.Value()),
-				zap.String("transactionHash", l.TransactionHash.Value()),
-				zap.String("logAddress", l.Address.Value()),
-				zap.Uint64("logIndex", l.LogIndex.Value()),
-			)
+		case <-ctx.Done():
+			// the block is not checkpointed, so its logs are delivered again after a restart
+			if pb != nil {
+				ecp.blockLogHandled(pb)
+			}
+			return nil, ctx.Err()
 		}
+		delivered = append(delivered, lwb)
 	}
 	ecp.logger.Sugar().Infow("Processed logs",
 		zap.Uint64("blockNumber", block.Number.Value()),
 	)
-	ecp.lastObservedBlock = block
-	return block, logs, nil
+	return delivered, nil
 }
 
 func (ecp *EVMChainPoller) listAllInterestingContracts() []string {
@@ -255,74 +665,117 @@ func (ecp *EVMChainPoller) listAllInterestingContracts() []string {
 	return contracts
 }
 
-func (ecp *EVMChainPoller) fetchLogsForInterestingContractsForBlock(blockNumber uint64) ([]*ethereum.EthereumEventLog, error) {
-	var wg sync.WaitGroup
-
-	allContracts := ecp.listAllInterestingContracts()
-	ecp.logger.Sugar().Infow("Fetching logs for interesting contracts",
-		zap.Any("contracts", allContracts),
-	)
-	logResultsChan := make(chan []*ethereum.EthereumEventLog, len(allContracts))
-	errorsChan := make(chan error, len(allContracts))
+// fetchBlocksWithLogs fetches the blocks from fromBlock up to at most toBlock together with the
+// logs of the interesting contracts. The logs of the whole range are fetched with one eth_getLogs
+// call while the blocks are fetched in parallel. The result is in block order and may end before
+// toBlock when the log range was shrunk or the node does not have a block yet.
+func (ecp *EVMChainPoller) fetchBlocksWithLogs(ctx context.Context, fromBlock uint64, toBlock uint64) ([]*blockWithLogs, error) {
+	logs, toBlock, err := ecp.fetchLogs(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := ecp.fetchBlocks(ctx, fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, contract := range allContracts {
-		wg.Add(1)
-		go func(contract string, wg *sync.WaitGroup) {
-			defer wg.Done()
+	logsByBlock := make(map[uint64][]*ethereum.EthereumEventLog)
+	for _, l := range logs {
+		logsByBlock[l.BlockNumber.Value()] = append(logsByBlock[l.BlockNumber.Value()], l)
+	}
+	batch := make([]*blockWithLogs, 0, len(blocks))
+	for _, block := range blocks {
+		batch = append(batch, &blockWithLogs{block: block, logs: logsByBlock[block.Number.Value()]})
+	}
+	return batch, nil
+}
 
-			ecp.logger.Sugar().Infow("Fetching logs for contract",
-				zap.String("contract", contract),
-				zap.Uint64("blockNumber", blockNumber),
-			)
-			logs, err := ecp.ethClient.GetLogs(context.Background(), contract, blockNumber, blockNumber)
-			if err != nil {
-				ecp.logger.Sugar().Errorw("Failed to fetch logs for contract",
-					zap.String("contract", contract),
-					zap.Uint64("blockNumber", blockNumber),
-					zap.Error(err),
-				)
-				errorsChan <- fmt.Errorf("failed to fetch logs for contract %s: %w", contract, err)
-				return
-			}
-			if len(logs) == 0 {
-				ecp.logger.Sugar().Infow("No logs found for contract",
-					zap.String("contract", contract),
-					zap.Uint64("blockNumber", blockNumber),
-				)
-				logResultsChan <- []*ethereum.EthereumEventLog{}
-				return
+// fetchLogs fetches the logs of the interesting contracts in the block range with a single
+// eth_getLogs call. While the provider reports too many results the range is halved, and it grows
+// back after successful calls. It returns the last block the logs cover.
+func (ecp *EVMChainPoller) fetchLogs(ctx context.Context, fromBlock uint64, toBlock uint64) ([]*ethereum.EthereumEventLog, uint64, error) {
+	contracts := ecp.listAllInterestingContracts()
+	if len(contracts) == 0 {
+		return []*ethereum.EthereumEventLog{}, toBlock, nil
+	}
+	for {
+		if rangeSize := ecp.logRangeSize.Load(); toBlock-fromBlock+1 > rangeSize {
+			toBlock = fromBlock + rangeSize - 1
+		}
+		ecp.logRequests.Add(1)
+		logs, err := ecp.ethClient.GetLogsForAddresses(ctx, contracts, ecp.config.EventTopics, fromBlock, toBlock)
+		if err == nil {
+			if rangeSize := ecp.logRangeSize.Load(); rangeSize < ecp.maxLogRangeSize() {
+				ecp.logRangeSize.Store(min(rangeSize*2, ecp.maxLogRangeSize()))
 			}
-			ecp.logger.Sugar().Infow("Fetched logs for contract",
-				zap.String("contract", contract),
-				zap.Uint64("blockNumber", blockNumber),
+			ecp.logger.Sugar().Debugw("Fetched logs for block range",
+				zap.Uint64("fromBlock", fromBlock),
+				zap.Uint64("toBlock", toBlock),
 				zap.Int("logCount", len(logs)),
 			)
-			logResultsChan <- logs
-		}(contract, &wg)
+			return logs, toBlock, nil
+		}
+		if !errors.Is(err, ethereum.ErrTooManyResults) || fromBlock == toBlock {
+			return nil, 0, fmt.Errorf("failed to fetch logs for blocks %d-%d: %w", fromBlock, toBlock, err)
+		}
+
+		rangeSize := max((toBlock-fromBlock+1)/2, 1)
+		ecp.logRangeSize.Store(rangeSize)
+		ecp.logger.Sugar().Warnw("Provider returned too many results, shrinking the log range",
+			zap.Uint64("fromBlock", fromBlock),
+			zap.Uint64("toBlock", toBlock),
+			zap.Uint64("logRangeSize", rangeSize),
+		)
 	}
-	wg.Wait()
-	close(logResultsChan)
-	close(errorsChan)
-	ecp.logger.Sugar().Infow("All logs fetched for contracts",
-		zap.Uint64("blockNumber", blockNumber),
-	)
+}
 
-	allErrors := make([]error, 0)
-	for err := range errorsChan {
-		allErrors = append(allErrors, err)
+// fetchBlocks fetches the blocks in the range with at most BlockFetchConcurrency requests in flight.
+// The result is in block order and ends before the first block the node does not have yet.
+func (ecp *EVMChainPoller) fetchBlocks(ctx context.Context, fromBlock uint64, toBlock uint64) ([]*ethereum.EthereumBlock, error) {
+	concurrency := ecp.config.BlockFetchConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBlockFetchConcurrency
 	}
-	if len(allErrors) > 0 {
-		return nil, fmt.Errorf("failed to fetch logs for contracts: %v", allErrors)
+
+	blocks := make([]*ethereum.EthereumBlock, toBlock-fromBlock+1)
+	errs := make([]error, len(blocks))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range blocks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			blocks[i], errs[i] = ecp.fetchBlock(ctx, fromBlock+uint64(i))
+		}(i)
 	}
+	wg.Wait()
 
-	allLogs := make([]*ethereum.EthereumEventLog, 0)
-	for contractLogs := range logResultsChan {
-		allLogs = append(allLogs, contractLogs...)
+	for i, block := range blocks {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to fetch block %d: %w", fromBlock+uint64(i), errs[i])
+		}
+		if block == nil {
+			return blocks[:i], nil
+		}
 	}
-	ecp.logger.Sugar().Infow("All logs fetched for contracts",
-		zap.Uint64("blockNumber", blockNumber),
-		zap.Int("logCount", len(allLogs)),
-	)
+	return blocks, nil
+}
 
-	return allLogs, nil
+func (ecp *EVMChainPoller) maxLogRangeSize() uint64 {
+	if ecp.config.LogRangeSize == 0 {
+		return DefaultLogRangeSize
+	}
+	return ecp.config.LogRangeSize
+}
+
+// Stats returns the RPC calls the poller made and the blocks it processed since it was created
+func (ecp *EVMChainPoller) Stats() PollerStats {
+	return PollerStats{
+		BlocksProcessed: ecp.blocksProcessed.Load(),
+		BlockRequests:   ecp.blockRequests.Load(),
+		LogRequests:     ecp.logRequests.Load(),
+		LogRangeSize:    ecp.logRangeSize.Load(),
+	}
 }
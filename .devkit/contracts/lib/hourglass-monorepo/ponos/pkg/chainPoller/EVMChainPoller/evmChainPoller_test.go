@@ -0,0 +1,533 @@
+package EVMChainPoller
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller/checkpointStore"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractStore/inMemoryContractStore"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contracts"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionLogParser"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	pingContractAddress = "0x00000000000000000000000000000000000000aa"
+	pingAbi             = `[{"type":"event","name":"Ping","anonymous":false,"inputs":[{"name":"value","type":"uint256","indexed":false}]}]`
+)
+
+var pingTopic = crypto.Keccak256Hash([]byte("Ping(uint256)")).Hex()
+
+// fakeChain is an in-memory chain that emits a Ping log for every value added to a block
+type fakeChain struct {
+	mu       sync.Mutex
+	blocks   []*ethereum.EthereumBlock
+	logs     map[string][]*ethereum.EthereumEventLog
+	nextHash uint64
+
+	// latestErr makes GetLatestBlock fail
+	latestErr error
+
+	// maxLogs makes GetLogsForAddresses fail with too many results above this many logs
+	maxLogs       int
+	blockRequests int
+	logRequests   int
+}
+
+func newFakeChain() *fakeChain {
+	fc := &fakeChain{logs: make(map[string][]*ethereum.EthereumEventLog)}
+	fc.addBlock()
+	return fc
+}
+
+// addBlock appends a block with one Ping log per value and returns its number
+func (fc *fakeChain) addBlock(values ...uint64) uint64 {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+
+	fc.nextHash++
+	number := uint64(len(fc.blocks))
+	block := &ethereum.EthereumBlock{
+		Hash:   ethereum.EthereumHexString(common.BigToHash(new(big.Int).SetUint64(fc.nextHash)).Hex()),
+		Number: ethereum.EthereumQuantity(number),
+	}
+	if number > 0 {
+		block.ParentHash = fc.blocks[number-1].Hash
+	}
+	fc.blocks = append(fc.blocks, block)
+
+	for i, value := range values {
+		fc.logs[block.Hash.Value()] = append(fc.logs[block.Hash.Value()], &ethereum.EthereumEventLog{
+			LogIndex:        ethereum.EthereumQuantity(i),
+			TransactionHash: block.Hash,
+			BlockHash:       block.Hash,
+			BlockNumber:     block.Number,
+			Address:         ethereum.EthereumHexString(pingContractAddress),
+			Data:            ethereum.EthereumHexString(hexutil.Encode(common.BigToHash(new(big.Int).SetUint64(value)).Bytes())),
+			Topics:          []ethereum.EthereumHexString{ethereum.EthereumHexString(pingTopic)},
+		})
+	}
+	return number
+}
+
+// fork drops every block after the given one, so the blocks added next replace them
+func (fc *fakeChain) fork(atBlock uint64) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.blocks = fc.blocks[:atBlock+1]
+}
+
+func (fc *fakeChain) GetLatestBlock(ctx context.Context) (uint64, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.latestErr != nil {
+		return 0, fc.latestErr
+	}
+	return uint64(len(fc.blocks) - 1), nil
+}
+
+func (fc *fakeChain) GetBlockByNumber(ctx context.Context, blockNumber uint64) (*ethereum.EthereumBlock, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.blockRequests++
+	if blockNumber >= uint64(len(fc.blocks)) {
+		return nil, nil
+	}
+	block := *fc.blocks[blockNumber]
+	return &block, nil
+}
+
+func (fc *fakeChain) GetLogsForAddresses(ctx context.Context, addresses []string, topics []string, fromBlock uint64, toBlock uint64) ([]*ethereum.EthereumEventLog, error) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.logRequests++
+	logs := make([]*ethereum.EthereumEventLog, 0)
+	for n := fromBlock; n <= toBlock && n < uint64(len(fc.blocks)); n++ {
+		for _, l := range fc.logs[fc.blocks[n].Hash.Value()] {
+			addressMatches := slices.ContainsFunc(addresses, func(a string) bool {
+				return strings.EqualFold(a, l.Address.Value())
+			})
+			if addressMatches && (len(topics) == 0 || slices.Contains(topics, l.Topics[0].Value())) {
+				logs = append(logs, l)
+			}
+		}
+	}
+	if fc.maxLogs > 0 && len(logs) > fc.maxLogs {
+		return nil, fmt.Errorf("%w: query returned more than %d results", ethereum.ErrTooManyResults, fc.maxLogs)
+	}
+	return logs, nil
+}
+
+// requests returns the number of block and log requests made so far
+func (fc *fakeChain) requests() (int, int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return fc.blockRequests, fc.logRequests
+}
+
+func newTestPoller(t *testing.T, chain *fakeChain, checkpoints *checkpointStore.CheckpointStore) (*EVMChainPoller, chan *chainPoller.LogWithBlock) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	store := inMemoryContractStore.NewInMemoryContractStore([]*contracts.Contract{
+		{Name: "Ping", Address: pingContractAddress, AbiVersions: []string{pingAbi}, ChainId: config.ChainId_EthereumAnvil},
+	}, l)
+	logsChan := make(chan *chainPoller.LogWithBlock, 100)
+	poller := NewEVMChainPoller(chain, logsChan, transactionLogParser.NewTransactionLogParser(store, l), &EVMChainPollerConfig{
+		ChainId:              config.ChainId_EthereumAnvil,
+		InterestingContracts: []string{pingContractAddress},
+	}, checkpoints, zap.NewNop())
+	return poller, logsChan
+}
+
+func newCheckpointStore(t *testing.T, dir string) *checkpointStore.CheckpointStore {
+	cs, err := checkpointStore.NewCheckpointStore(&checkpointStore.CheckpointStoreConfig{Dir: dir}, zap.NewNop())
+	require.NoError(t, err)
+	return cs
+}
+
+// drainBlockNumbers returns the block numbers of the logs waiting in the channel and marks the logs
+// handled, like the aggregator does
+func drainBlockNumbers(logsChan chan *chainPoller.LogWithBlock) []uint64 {
+	numbers := make([]uint64, 0)
+	for {
+		select {
+		case lwb := <-logsChan:
+			numbers = append(numbers, lwb.Block.Number.Value())
+			lwb.Handled()
+		default:
+			return numbers
+		}
+	}
+}
+
+// drainEvents returns the events waiting in the channel and marks them handled
+func drainEvents(logsChan chan *chainPoller.LogWithBlock) []*chainPoller.LogWithBlock {
+	events := make([]*chainPoller.LogWithBlock, 0)
+	for {
+		select {
+		case lwb := <-logsChan:
+			events = append(events, lwb)
+			lwb.Handled()
+		default:
+			return events
+		}
+	}
+}
+
+type eventSummary struct {
+	blockNumber uint64
+	blockHash   string
+	removed     bool
+}
+
+func summarize(events []*chainPoller.LogWithBlock) []eventSummary {
+	summaries := make([]eventSummary, 0, len(events))
+	for _, e := range events {
+		summaries = append(summaries, eventSummary{blockNumber: e.Block.Number.Value(), blockHash: e.Block.Hash.Value(), removed: e.Removed})
+	}
+	return summaries
+}
+
+func blockHash(t *testing.T, chain *fakeChain, number uint64) string {
+	block, err := chain.GetBlockByNumber(context.Background(), number)
+	require.NoError(t, err)
+	return block.Hash.Value()
+}
+
+func Test_EVMChainPollerCheckpoints(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("starts at the chain head without a checkpoint", func(t *testing.T) {
+		chain := newFakeChain()
+		for i := uint64(1); i <= 5; i++ {
+			chain.addBlock(i)
+		}
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, []uint64{5}, drainBlockNumbers(logsChan))
+	})
+
+	t.Run("waits for the first block on a chain with only the genesis block", func(t *testing.T) {
+		chain := newFakeChain()
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Nil(t, poller.lastObservedBlock)
+
+		chain.addBlock(1)
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, []uint64{1}, drainBlockNumbers(logsChan))
+	})
+
+	t.Run("catches up from the checkpoint after a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		chain := newFakeChain()
+		chain.addBlock(1)
+		chain.addBlock(2)
+
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, dir))
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, []uint64{2}, drainBlockNumbers(logsChan))
+
+		// blocks produced while the poller is down
+		chain.addBlock(3)
+		chain.addBlock()
+		chain.addBlock(5, 5)
+
+		restarted, logsChan := newTestPoller(t, chain, newCheckpointStore(t, dir))
+		require.NoError(t, restarted.processNextBlock(ctx))
+		assert.Equal(t, []uint64{3, 5, 5}, drainBlockNumbers(logsChan))
+
+		checkpoint, err := newCheckpointStore(t, dir).GetCheckpoint(config.ChainId_EthereumAnvil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), checkpoint.BlockNumber)
+	})
+
+	t.Run("backfills a range without moving the checkpoint", func(t *testing.T) {
+		chain := newFakeChain()
+		for i := uint64(1); i <= 6; i++ {
+			chain.addBlock(i)
+		}
+		checkpoints := newCheckpointStore(t, "")
+		poller, logsChan := newTestPoller(t, chain, checkpoints)
+		require.NoError(t, poller.processNextBlock(ctx))
+		drainBlockNumbers(logsChan)
+
+		require.NoError(t, poller.Backfill(ctx, 2, 4))
+		assert.Equal(t, []uint64{2, 3, 4}, drainBlockNumbers(logsChan))
+
+		checkpoint, err := checkpoints.GetCheckpoint(config.ChainId_EthereumAnvil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(6), checkpoint.BlockNumber)
+
+		assert.ErrorContains(t, poller.Backfill(ctx, 4, 2), "after toBlock")
+		assert.ErrorContains(t, poller.Backfill(ctx, 2, 7), "after the latest block")
+	})
+
+	t.Run("surfaces a failure to get the latest block", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.latestErr = &ethereum.TransportError{Url: "http://localhost:8545", StatusCode: 502}
+		poller, _ := newTestPoller(t, chain, newCheckpointStore(t, ""))
+
+		err := poller.processNextBlock(ctx)
+		assert.ErrorContains(t, err, "failed to get latest block")
+		assert.True(t, ethereum.IsRetryable(err))
+	})
+
+	t.Run("does not checkpoint a block whose logs were not delivered", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		checkpoints := newCheckpointStore(t, "")
+		poller, _ := newTestPoller(t, chain, checkpoints)
+		poller.chainEventsChan = make(chan *chainPoller.LogWithBlock)
+
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		assert.ErrorIs(t, poller.processNextBlock(cancelled), context.Canceled)
+
+		checkpoint, err := checkpoints.GetCheckpoint(config.ChainId_EthereumAnvil)
+		require.NoError(t, err)
+		assert.Nil(t, checkpoint)
+	})
+
+	t.Run("checkpoints a block once its logs and the earlier blocks were handled", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		checkpoints := newCheckpointStore(t, "")
+		poller, logsChan := newTestPoller(t, chain, checkpoints)
+		require.NoError(t, poller.processNextBlock(ctx))
+		drainBlockNumbers(logsChan)
+
+		chain.addBlock(2)
+		chain.addBlock()
+		chain.addBlock(4, 4)
+		require.NoError(t, poller.processNextBlock(ctx))
+
+		var delivered []*chainPoller.LogWithBlock
+		for len(logsChan) > 0 {
+			delivered = append(delivered, <-logsChan)
+		}
+		require.Len(t, delivered, 3)
+
+		checkpointNumber := func() uint64 {
+			checkpoint, err := checkpoints.GetCheckpoint(config.ChainId_EthereumAnvil)
+			require.NoError(t, err)
+			return checkpoint.BlockNumber
+		}
+		assert.Equal(t, uint64(1), checkpointNumber())
+
+		// a task queued from block 4 holds the checkpoint back
+		release := delivered[1].Retain()
+		delivered[1].Handled()
+		delivered[2].Handled()
+		assert.Equal(t, uint64(1), checkpointNumber())
+
+		delivered[0].Handled()
+		assert.Equal(t, uint64(3), checkpointNumber())
+
+		release()
+		assert.Equal(t, uint64(4), checkpointNumber())
+	})
+}
+
+func Test_EVMChainPollerConfirmations(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("waits for the confirmation depth before emitting a block", func(t *testing.T) {
+		chain := newFakeChain()
+		for i := uint64(1); i <= 5; i++ {
+			chain.addBlock(i)
+		}
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		poller.config.Confirmations = 2
+
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, []uint64{3}, drainBlockNumbers(logsChan))
+
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Empty(t, drainBlockNumbers(logsChan))
+
+		chain.addBlock(6)
+		chain.addBlock(7)
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, []uint64{4, 5}, drainBlockNumbers(logsChan))
+
+		assert.ErrorContains(t, poller.Backfill(ctx, 4, 6), "after the latest block")
+	})
+
+	t.Run("emits nothing while the chain is shorter than the confirmation depth", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		poller.config.Confirmations = 3
+
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Empty(t, drainBlockNumbers(logsChan))
+		assert.Nil(t, poller.lastObservedBlock)
+	})
+}
+
+func Test_EVMChainPollerReorgs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("removes the logs of orphaned blocks and replays the new chain", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		require.NoError(t, poller.processNextBlock(ctx))
+
+		chain.addBlock(2)
+		chain.addBlock(3, 3)
+		require.NoError(t, poller.processNextBlock(ctx))
+		drainEvents(logsChan)
+		orphaned2, orphaned3 := blockHash(t, chain, 2), blockHash(t, chain, 3)
+
+		chain.fork(1)
+		chain.addBlock(20)
+		chain.addBlock()
+		chain.addBlock(40)
+		require.NoError(t, poller.processNextBlock(ctx))
+
+		assert.Equal(t, []eventSummary{
+			{blockNumber: 3, blockHash: orphaned3, removed: true},
+			{blockNumber: 3, blockHash: orphaned3, removed: true},
+			{blockNumber: 2, blockHash: orphaned2, removed: true},
+			{blockNumber: 2, blockHash: blockHash(t, chain, 2)},
+			{blockNumber: 4, blockHash: blockHash(t, chain, 4)},
+		}, summarize(drainEvents(logsChan)))
+		assert.Equal(t, blockHash(t, chain, 4), poller.lastObservedBlock.Hash.Value())
+	})
+
+	t.Run("rewinds to the start of the window when the reorg is deeper", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		poller.config.ReorgWindowSize = 2
+		require.NoError(t, poller.processNextBlock(ctx))
+		for i := uint64(2); i <= 5; i++ {
+			chain.addBlock(i)
+		}
+		require.NoError(t, poller.processNextBlock(ctx))
+		drainEvents(logsChan)
+		assert.Len(t, poller.recentBlocks, 2)
+
+		chain.fork(1)
+		for i := uint64(20); i <= 60; i += 10 {
+			chain.addBlock(i)
+		}
+		require.NoError(t, poller.processNextBlock(ctx))
+
+		// blocks 2 and 3 were replaced too but are outside the window
+		numbers, removed := make([]uint64, 0), make([]bool, 0)
+		for _, e := range drainEvents(logsChan) {
+			numbers = append(numbers, e.Block.Number.Value())
+			removed = append(removed, e.Removed)
+		}
+		assert.Equal(t, []uint64{5, 4, 4, 5, 6}, numbers)
+		assert.Equal(t, []bool{true, true, false, false, false}, removed)
+	})
+
+	t.Run("detects a reorg of the checkpointed block after a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		chain := newFakeChain()
+		chain.addBlock(1)
+		chain.addBlock(2)
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, dir))
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, []uint64{2}, drainBlockNumbers(logsChan))
+
+		chain.fork(1)
+		chain.addBlock(20)
+		chain.addBlock(30)
+
+		restarted, logsChan := newTestPoller(t, chain, newCheckpointStore(t, dir))
+		require.NoError(t, restarted.processNextBlock(ctx))
+		assert.Equal(t, []eventSummary{
+			{blockNumber: 2, blockHash: blockHash(t, chain, 2)},
+			{blockNumber: 3, blockHash: blockHash(t, chain, 3)},
+		}, summarize(drainEvents(logsChan)))
+
+		checkpoint, err := newCheckpointStore(t, dir).GetCheckpoint(config.ChainId_EthereumAnvil)
+		require.NoError(t, err)
+		assert.Equal(t, blockHash(t, chain, 3), checkpoint.BlockHash)
+	})
+}
+
+func Test_EVMChainPollerLogRanges(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("fetches the logs of a catch-up with one call per range", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		poller.config.LogRangeSize = 20
+		poller.logRangeSize.Store(20)
+		require.NoError(t, poller.processNextBlock(ctx))
+		drainBlockNumbers(logsChan)
+
+		expected := make([]uint64, 0)
+		for i := uint64(2); i <= 51; i++ {
+			chain.addBlock(i)
+			expected = append(expected, i)
+		}
+		blockRequests, logRequests := chain.requests()
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, expected, drainBlockNumbers(logsChan))
+
+		blockRequestsAfter, logRequestsAfter := chain.requests()
+		assert.Equal(t, 3, logRequestsAfter-logRequests)
+		assert.Equal(t, 50, blockRequestsAfter-blockRequests)
+		assert.Equal(t, uint64(51), poller.Stats().BlocksProcessed)
+	})
+
+	t.Run("shrinks the range while the provider returns too many results", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		chain.maxLogs = 4
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		require.NoError(t, poller.processNextBlock(ctx))
+		drainBlockNumbers(logsChan)
+
+		expected := make([]uint64, 0)
+		for i := uint64(2); i <= 21; i++ {
+			chain.addBlock(i, i)
+			expected = append(expected, i, i)
+		}
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Equal(t, expected, drainBlockNumbers(logsChan))
+		assert.Less(t, poller.Stats().LogRangeSize, DefaultLogRangeSize)
+	})
+
+	t.Run("fails when a single block has too many results", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1, 2, 3)
+		chain.maxLogs = 2
+		poller, _ := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		assert.ErrorIs(t, poller.processNextBlock(ctx), ethereum.ErrTooManyResults)
+	})
+
+	t.Run("only fetches logs with the configured topics", func(t *testing.T) {
+		chain := newFakeChain()
+		chain.addBlock(1)
+		chain.addBlock(2)
+		poller, logsChan := newTestPoller(t, chain, newCheckpointStore(t, ""))
+		poller.config.EventTopics = []string{crypto.Keccak256Hash([]byte("Pong(uint256)")).Hex()}
+		require.NoError(t, poller.processNextBlock(ctx))
+		assert.Empty(t, drainBlockNumbers(logsChan))
+	})
+}
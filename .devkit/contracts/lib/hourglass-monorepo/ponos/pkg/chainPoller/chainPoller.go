@@ -4,13 +4,58 @@ import (
 	"context"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionLogParser/log"
+	"sync/atomic"
 )
 
 type IChainPoller interface {
 	Start(ctx context.Context) error
 }
 
+// IBackfillChainPoller is implemented by pollers that can replay the logs of a range of blocks
+type IBackfillChainPoller interface {
+	Backfill(ctx context.Context, fromBlock uint64, toBlock uint64) error
+}
+
 type LogWithBlock struct {
 	Log   *log.DecodedLog
 	Block *ethereum.EthereumBlock
+
+	// Removed is set when the block was orphaned by a chain reorganization after the log was delivered
+	Removed bool
+
+	// onHandled is called once every holder of the log released it
+	onHandled func()
+	// refs counts the holders of the log, starting with the consumer of the events channel
+	refs atomic.Int64
+}
+
+// NewLogWithBlock creates a log whose onHandled callback is called once the consumer of the events
+// channel and everyone it passed the log on to have handled it. Pollers use it to only checkpoint
+// blocks whose logs were handled.
+func NewLogWithBlock(l *log.DecodedLog, block *ethereum.EthereumBlock, onHandled func()) *LogWithBlock {
+	lwb := &LogWithBlock{
+		Log:       l,
+		Block:     block,
+		onHandled: onHandled,
+	}
+	lwb.refs.Store(1)
+	return lwb
+}
+
+// Retain takes another hold on the log for work that continues after the consumer returns, such as
+// a queued task. The returned function releases it and must be called exactly once.
+func (lwb *LogWithBlock) Retain() func() {
+	lwb.refs.Add(1)
+	return lwb.Handled
+}
+
+// Handled releases a hold on the log. The consumer of the events channel calls it once it is done
+// with the log; it is a no-op for logs that were not created with NewLogWithBlock.
+func (lwb *LogWithBlock) Handled() {
+	if lwb.onHandled == nil {
+		return
+	}
+	if lwb.refs.Add(-1) == 0 {
+		lwb.onHandled()
+	}
 }
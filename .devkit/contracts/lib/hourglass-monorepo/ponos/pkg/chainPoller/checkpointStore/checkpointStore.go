@@ -0,0 +1,136 @@
+// Package checkpointStore keeps the last block a chain poller fully processed for each chain, so
+// that a restarted poller resumes where it stopped instead of at the chain head.
+//
+// Each chain's checkpoint is written atomically to <dir>/<chainId>.json:
+//
+//	{
+//	  "chainId": 1,
+//	  "blockNumber": 22000000,
+//	  "blockHash": "0x..."
+//	}
+package checkpointStore
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"go.uber.org/zap"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Checkpoint is the last block that was fully processed for a chain
+type Checkpoint struct {
+	ChainId     config.ChainId `json:"chainId"`
+	BlockNumber uint64         `json:"blockNumber"`
+	BlockHash   string         `json:"blockHash"`
+}
+
+type CheckpointStoreConfig struct {
+	// Dir is where checkpoints are persisted. When empty, checkpoints are only kept in memory.
+	Dir string
+}
+
+type CheckpointStore struct {
+	config *CheckpointStoreConfig
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	checkpoints map[config.ChainId]*Checkpoint
+}
+
+func NewCheckpointStore(cfg *CheckpointStoreConfig, logger *zap.Logger) (*CheckpointStore, error) {
+	cs := &CheckpointStore{
+		config:      cfg,
+		logger:      logger,
+		checkpoints: make(map[config.ChainId]*Checkpoint),
+	}
+	if cfg.Dir == "" {
+		return cs, nil
+	}
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	return cs, nil
+}
+
+// GetCheckpoint returns the checkpoint for the chain, or nil if the chain was never processed
+func (cs *CheckpointStore) GetCheckpoint(chainId config.ChainId) (*Checkpoint, error) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if checkpoint, ok := cs.checkpoints[chainId]; ok {
+		c := *checkpoint
+		return &c, nil
+	}
+	if cs.config.Dir == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(cs.checkpointPath(chainId))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var checkpoint *Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("checkpoint for chain %d is corrupt: %w", chainId, err)
+	}
+	if checkpoint.ChainId != chainId {
+		return nil, fmt.Errorf("checkpoint for chain %d belongs to chain %d", chainId, checkpoint.ChainId)
+	}
+	cs.checkpoints[chainId] = checkpoint
+	c := *checkpoint
+	return &c, nil
+}
+
+// SaveCheckpoint records the block as fully processed for its chain
+func (cs *CheckpointStore) SaveCheckpoint(checkpoint *Checkpoint) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	c := *checkpoint
+	if err := cs.persist(&c); err != nil {
+		return err
+	}
+	cs.checkpoints[c.ChainId] = &c
+	return nil
+}
+
+// persist atomically writes the checkpoint to the checkpoint directory. The caller must hold mu.
+func (cs *CheckpointStore) persist(checkpoint *Checkpoint) error {
+	if cs.config.Dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	tmp, err := os.CreateTemp(cs.config.Dir, ".checkpoint-*")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), cs.checkpointPath(checkpoint.ChainId)); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (cs *CheckpointStore) checkpointPath(chainId config.ChainId) string {
+	return filepath.Join(cs.config.Dir, fmt.Sprintf("%d.json", chainId))
+}
@@ -0,0 +1,71 @@
+package checkpointStore
+
+import (
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_CheckpointStore(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	newStore := func(t *testing.T, dir string) *CheckpointStore {
+		cs, err := NewCheckpointStore(&CheckpointStoreConfig{Dir: dir}, l)
+		if err != nil {
+			t.Fatalf("Failed to create checkpoint store: %v", err)
+		}
+		return cs
+	}
+
+	t.Run("returns nil for a chain that was never checkpointed", func(t *testing.T) {
+		cs := newStore(t, t.TempDir())
+		checkpoint, err := cs.GetCheckpoint(config.ChainId_EthereumAnvil)
+		assert.Nil(t, err)
+		assert.Nil(t, checkpoint)
+	})
+
+	t.Run("keeps checkpoints per chain across restarts", func(t *testing.T) {
+		dir := t.TempDir()
+		cs := newStore(t, dir)
+		assert.Nil(t, cs.SaveCheckpoint(&Checkpoint{ChainId: config.ChainId_EthereumAnvil, BlockNumber: 10, BlockHash: "0xa"}))
+		assert.Nil(t, cs.SaveCheckpoint(&Checkpoint{ChainId: config.ChainId_EthereumAnvil, BlockNumber: 11, BlockHash: "0xb"}))
+		assert.Nil(t, cs.SaveCheckpoint(&Checkpoint{ChainId: config.ChainId_EthereumHolesky, BlockNumber: 3, BlockHash: "0xc"}))
+
+		restarted := newStore(t, dir)
+		checkpoint, err := restarted.GetCheckpoint(config.ChainId_EthereumAnvil)
+		assert.Nil(t, err)
+		assert.Equal(t, &Checkpoint{ChainId: config.ChainId_EthereumAnvil, BlockNumber: 11, BlockHash: "0xb"}, checkpoint)
+
+		checkpoint, err = restarted.GetCheckpoint(config.ChainId_EthereumHolesky)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(3), checkpoint.BlockNumber)
+
+		// no temporary files are left behind
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Len(t, entries, 2)
+	})
+
+	t.Run("refuses a corrupt checkpoint", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "31337.json"), []byte(`{"chainId":31337,"blockNum`), 0600); err != nil {
+			t.Fatalf("Failed to write checkpoint: %v", err)
+		}
+		_, err := newStore(t, dir).GetCheckpoint(config.ChainId_EthereumAnvil)
+		assert.ErrorContains(t, err, "corrupt")
+	})
+
+	t.Run("keeps checkpoints in memory without a directory", func(t *testing.T) {
+		cs := newStore(t, "")
+		assert.Nil(t, cs.SaveCheckpoint(&Checkpoint{ChainId: config.ChainId_EthereumAnvil, BlockNumber: 5}))
+		checkpoint, err := cs.GetCheckpoint(config.ChainId_EthereumAnvil)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(5), checkpoint.BlockNumber)
+	})
+}
@@ -0,0 +1,190 @@
+package subscriptionChainPoller
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/chainPoller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	DefaultFallbackPollingInterval = 12 * time.Second
+	DefaultReconnectInterval       = 5 * time.Second
+)
+
+// IBlockProcessor delivers the logs of the blocks up to the chain head, e.g. an EVMChainPoller
+type IBlockProcessor interface {
+	chainPoller.IBackfillChainPoller
+	ProcessNewBlocks(ctx context.Context) error
+}
+
+// HeadSubscriberDialer connects to the node that new heads are subscribed on
+type HeadSubscriberDialer func(ctx context.Context) (ethereum.HeadSubscriber, func(), error)
+
+type SubscriptionChainPollerConfig struct {
+	ChainId config.ChainId
+
+	// FallbackPollingInterval is how often blocks are polled while the head subscription is down
+	FallbackPollingInterval time.Duration
+
+	// ReconnectInterval is how long to wait before resubscribing after the subscription failed
+	ReconnectInterval time.Duration
+}
+
+// SubscriptionChainPoller processes new blocks as soon as the node announces a new head over a
+// WebSocket subscription. While the subscription is down it polls on an interval instead, and
+// every trigger catches up from the last processed block, so blocks missed while disconnected are
+// delivered through the same decoding path.
+type SubscriptionChainPoller struct {
+	blockProcessor IBlockProcessor
+	ethClient      *ethereum.Client
+	dial           HeadSubscriberDialer
+	config         *SubscriptionChainPollerConfig
+	logger         *zap.Logger
+}
+
+func NewSubscriptionChainPoller(
+	blockProcessor IBlockProcessor,
+	ethClient *ethereum.Client,
+	dial HeadSubscriberDialer,
+	config *SubscriptionChainPollerConfig,
+	logger *zap.Logger,
+) *SubscriptionChainPoller {
+	return &SubscriptionChainPoller{
+		blockProcessor: blockProcessor,
+		ethClient:      ethClient,
+		dial:           dial,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+// NewWebSocketDialer returns a dialer that connects to the WebSocket RPC url
+func NewWebSocketDialer(wsUrl string) HeadSubscriberDialer {
+	return func(ctx context.Context) (ethereum.HeadSubscriber, func(), error) {
+		wsc, err := ethclient.DialContext(ctx, wsUrl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to dial websocket rpc: %w", err)
+		}
+		return wsc, wsc.Close, nil
+	}
+}
+
+func (scp *SubscriptionChainPoller) Start(ctx context.Context) error {
+	scp.logger.Sugar().Infow("Starting subscription chain poller",
+		zap.Any("chainId", scp.config.ChainId),
+		zap.Duration("fallbackPollingInterval", scp.fallbackPollingInterval()),
+	)
+	go scp.run(ctx)
+	return nil
+}
+
+// Backfill replays the logs of the blocks in [fromBlock, toBlock]
+func (scp *SubscriptionChainPoller) Backfill(ctx context.Context, fromBlock uint64, toBlock uint64) error {
+	return scp.blockProcessor.Backfill(ctx, fromBlock, toBlock)
+}
+
+func (scp *SubscriptionChainPoller) run(ctx context.Context) {
+	heads := make(chan struct{}, 1)
+	subscriptionDone := make(chan error, 1)
+	subscribe := func() {
+		go func() {
+			subscriptionDone <- scp.listen(ctx, heads)
+		}()
+	}
+
+	ticker := time.NewTicker(scp.fallbackPollingInterval())
+	defer ticker.Stop()
+
+	var reconnect <-chan time.Time
+	subscribed := true
+	subscribe()
+
+	// catch up on the blocks produced since the last run
+	scp.processNewBlocks(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			scp.logger.Sugar().Infow("Subscription chain poller context cancelled, exiting")
+			return
+		case <-heads:
+			scp.processNewBlocks(ctx)
+		case err := <-subscriptionDone:
+			if ctx.Err() != nil {
+				return
+			}
+			scp.logger.Sugar().Warnw("Head subscription lost, falling back to polling",
+				zap.Any("chainId", scp.config.ChainId),
+				zap.Error(err),
+			)
+			subscribed = false
+			reconnect = time.After(scp.reconnectInterval())
+			scp.processNewBlocks(ctx)
+		case <-reconnect:
+			reconnect = nil
+			subscribed = true
+			scp.logger.Sugar().Infow("Resubscribing to new heads",
+				zap.Any("chainId", scp.config.ChainId),
+			)
+			subscribe()
+			// fills the gap between the last poll and the new subscription
+			scp.processNewBlocks(ctx)
+		case <-ticker.C:
+			if !subscribed {
+				scp.processNewBlocks(ctx)
+			}
+		}
+	}
+}
+
+// listen subscribes to new heads and signals every head on the heads channel until the
+// subscription fails or ctx is done
+func (scp *SubscriptionChainPoller) listen(ctx context.Context, heads chan<- struct{}) error {
+	wsc, closeConn, err := scp.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+
+	return scp.ethClient.ListenForNewBlocks(ctx, wsc, nil, func(header *types.Header) error {
+		scp.logger.Sugar().Debugw("Received new head",
+			zap.Any("chainId", scp.config.ChainId),
+			zap.Uint64("blockNumber", header.Number.Uint64()),
+		)
+		// a pending signal already covers this head
+		select {
+		case heads <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+}
+
+func (scp *SubscriptionChainPoller) processNewBlocks(ctx context.Context) {
+	if err := scp.blockProcessor.ProcessNewBlocks(ctx); err != nil {
+		// the next head or poll retries from the last processed block
+		scp.logger.Sugar().Errorw("Failed to process new blocks",
+			zap.Any("chainId", scp.config.ChainId),
+			zap.Error(err),
+		)
+	}
+}
+
+func (scp *SubscriptionChainPoller) fallbackPollingInterval() time.Duration {
+	if scp.config.FallbackPollingInterval <= 0 {
+		return DefaultFallbackPollingInterval
+	}
+	return scp.config.FallbackPollingInterval
+}
+
+func (scp *SubscriptionChainPoller) reconnectInterval() time.Duration {
+	if scp.config.ReconnectInterval <= 0 {
+		return DefaultReconnectInterval
+	}
+	return scp.config.ReconnectInterval
+}
@@ -0,0 +1,136 @@
+package subscriptionChainPoller
+
+import (
+	"context"
+	"errors"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeBlockProcessor signals every ProcessNewBlocks call
+type fakeBlockProcessor struct {
+	processed chan struct{}
+}
+
+func (f *fakeBlockProcessor) ProcessNewBlocks(ctx context.Context) error {
+	f.processed <- struct{}{}
+	return nil
+}
+
+func (f *fakeBlockProcessor) Backfill(ctx context.Context, fromBlock uint64, toBlock uint64) error {
+	return nil
+}
+
+// fakeHeads announces the headers sent on heads and fails the active subscription on fail
+type fakeHeads struct {
+	heads chan *types.Header
+	fail  chan error
+	dials atomic.Int32
+}
+
+func (f *fakeHeads) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (goethereum.Subscription, error) {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case header := <-f.heads:
+				select {
+				case ch <- header:
+				case <-quit:
+					return nil
+				}
+			case err := <-f.fail:
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func (f *fakeHeads) dial(ctx context.Context) (ethereum.HeadSubscriber, func(), error) {
+	f.dials.Add(1)
+	return f, func() {}, nil
+}
+
+func waitForProcessing(t *testing.T, processed chan struct{}, timeout time.Duration) {
+	select {
+	case <-processed:
+	case <-time.After(timeout):
+		t.Fatalf("blocks were not processed within %s", timeout)
+	}
+}
+
+func drain(processed chan struct{}) {
+	for {
+		select {
+		case <-processed:
+		default:
+			return
+		}
+	}
+}
+
+func Test_SubscriptionChainPoller(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	newPoller := func(t *testing.T, cfg *SubscriptionChainPollerConfig) (*fakeBlockProcessor, *fakeHeads) {
+		processor := &fakeBlockProcessor{processed: make(chan struct{}, 100)}
+		heads := &fakeHeads{heads: make(chan *types.Header), fail: make(chan error)}
+		ethClient := ethereum.NewEthereumClient(&ethereum.EthereumClientConfig{}, l)
+		poller := NewSubscriptionChainPoller(processor, ethClient, heads.dial, cfg, l)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		require.NoError(t, poller.Start(ctx))
+		return processor, heads
+	}
+
+	t.Run("processes blocks when a new head arrives", func(t *testing.T) {
+		processor, heads := newPoller(t, &SubscriptionChainPollerConfig{
+			ChainId:                 config.ChainId_EthereumAnvil,
+			FallbackPollingInterval: time.Hour,
+		})
+		// catch up on start
+		waitForProcessing(t, processor.processed, time.Second)
+
+		heads.heads <- &types.Header{Number: big.NewInt(10)}
+		waitForProcessing(t, processor.processed, time.Second)
+		heads.heads <- &types.Header{Number: big.NewInt(11)}
+		waitForProcessing(t, processor.processed, time.Second)
+		assert.Equal(t, int32(1), heads.dials.Load())
+	})
+
+	t.Run("polls while disconnected and resubscribes", func(t *testing.T) {
+		processor, heads := newPoller(t, &SubscriptionChainPollerConfig{
+			ChainId:                 config.ChainId_EthereumAnvil,
+			FallbackPollingInterval: 10 * time.Millisecond,
+			ReconnectInterval:       200 * time.Millisecond,
+		})
+		waitForProcessing(t, processor.processed, time.Second)
+
+		heads.fail <- errors.New("websocket: close 1006 (abnormal closure)")
+
+		// the gap is filled right away and then on every poll interval
+		for i := 0; i < 5; i++ {
+			waitForProcessing(t, processor.processed, time.Second)
+		}
+
+		require.Eventually(t, func() bool {
+			return heads.dials.Load() == 2
+		}, time.Second, 10*time.Millisecond)
+		drain(processor.processed)
+		heads.heads <- &types.Header{Number: big.NewInt(12)}
+		waitForProcessing(t, processor.processed, time.Second)
+	})
+}
@@ -1,10 +1,15 @@
 package avsPerformerClient
 
 import (
+	"context"
+	"fmt"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"time"
 )
 
+const readyPollInterval = 250 * time.Millisecond
+
 func NewAvsPerformerClient(fullUrl string, insecureConn bool) (performerV1.PerformerServiceClient, error) {
 	grpcClient, err := clients.NewGrpcClient(fullUrl, insecureConn)
 	if err != nil {
@@ -12,3 +17,22 @@ func NewAvsPerformerClient(fullUrl string, insecureConn bool) (performerV1.Perfo
 	}
 	return performerV1.NewPerformerServiceClient(grpcClient), nil
 }
+
+// WaitForReady polls the performer's health check until it reports it is ready for a task
+// or the context is done.
+func WaitForReady(ctx context.Context, perfClient performerV1.PerformerServiceClient) error {
+	for {
+		res, err := perfClient.HealthCheck(ctx, &performerV1.HealthCheckRequest{})
+		if err == nil && res.Status == performerV1.PerformerStatus_READY_FOR_TASK {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("%w: %v", ctx.Err(), err)
+			}
+			return ctx.Err()
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
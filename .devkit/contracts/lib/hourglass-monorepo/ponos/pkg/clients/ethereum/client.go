@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	goethereum "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -21,8 +23,9 @@ import (
 type BlockType string
 
 const (
-	BlockType_Safe   BlockType = "safe"
-	BlockType_Latest BlockType = "latest"
+	BlockType_Safe      BlockType = "safe"
+	BlockType_Latest    BlockType = "latest"
+	BlockType_Finalized BlockType = "finalized"
 )
 
 type RequestMethod struct {
@@ -60,15 +63,56 @@ type ClientParams struct {
 
 var jsonRPCVersion = "2.0"
 
+// IEthereumClient is the part of the Client that chain pollers use to follow a chain
+type IEthereumClient interface {
+	GetLatestBlock(ctx context.Context) (uint64, error)
+	GetBlockByNumber(ctx context.Context, blockNumber uint64) (*EthereumBlock, error)
+	GetLogsForAddresses(ctx context.Context, addresses []string, topics []string, fromBlock uint64, toBlock uint64) ([]*EthereumEventLog, error)
+}
+
 type Client struct {
 	Logger       *zap.Logger
 	httpClient   *http.Client
 	clientConfig *EthereumClientConfig
+	endpoints    []*endpoint
+
+	healthMu        sync.Mutex
+	checkingHealth  bool
+	lastHealthCheck time.Time
 }
 
 type EthereumClientConfig struct {
 	BaseUrl   string
 	BlockType BlockType
+
+	// FallbackUrls are more endpoints for the same chain. Calls go to the healthiest endpoint, and
+	// reads are retried on the next one when an endpoint fails.
+	FallbackUrls []string
+
+	// RequestTimeout bounds every request to an endpoint. Defaults to DefaultRequestTimeout.
+	RequestTimeout time.Duration
+
+	// HealthCheckInterval is how often the head of every endpoint is compared when there are
+	// several. Defaults to DefaultHealthCheckInterval.
+	HealthCheckInterval time.Duration
+}
+
+// DefaultRequestTimeout bounds every request to an endpoint when EthereumClientConfig.RequestTimeout
+// is not set
+const DefaultRequestTimeout = 10 * time.Second
+
+// nonIdempotentMethods are not retried, since a failed call may still have been applied
+var nonIdempotentMethods = []string{"eth_sendRawTransaction", "eth_sendTransaction"}
+
+// callBackoffs are the seconds waited between rounds over all endpoints
+var callBackoffs = []int{1, 3, 5, 10, 20, 30, 60}
+
+// GetRequestTimeout returns the configured request timeout, defaulting to DefaultRequestTimeout
+func (cfg *EthereumClientConfig) GetRequestTimeout() time.Duration {
+	if cfg.RequestTimeout <= 0 {
+		return DefaultRequestTimeout
+	}
+	return cfg.RequestTimeout
 }
 
 //nolint:all
@@ -87,7 +131,7 @@ func DefaultChunkedCallEthereumClientConfig() *EthereumClientConfig {
 
 func NewEthereumClient(cfg *EthereumClientConfig, l *zap.Logger) *Client {
 	client := &http.Client{
-		Timeout: time.Second * 10,
+		Timeout: cfg.GetRequestTimeout(),
 	}
 
 	l.Sugar().Debugw("Creating new Ethereum client", zap.Any("config", cfg))
@@ -96,6 +140,9 @@ func NewEthereumClient(cfg *EthereumClientConfig, l *zap.Logger) *Client {
 		httpClient:   client,
 		Logger:       l,
 		clientConfig: cfg,
+		endpoints:    newEndpoints(cfg),
+		// the first health check runs one interval after the client starts serving calls
+		lastHealthCheck: time.Now(),
 	}
 }
 
@@ -103,8 +150,9 @@ func (c *Client) SetHttpClient(client *http.Client) {
 	c.httpClient = client
 }
 
+// GetEthereumContractCaller dials the currently healthiest endpoint
 func (c *Client) GetEthereumContractCaller() (*ethclient.Client, error) {
-	d, err := ethclient.Dial(c.clientConfig.BaseUrl)
+	d, err := ethclient.Dial(c.rankedEndpoints()[0].url)
 	if err != nil {
 		c.Logger.Sugar().Error("Failed to create new eth client", zap.Error(err))
 		return nil, err
@@ -112,9 +160,17 @@ func (c *Client) GetEthereumContractCaller() (*ethclient.Client, error) {
 	return d, nil
 }
 
+// HeadSubscriber subscribes to new chain heads, e.g. an ethclient.Client connected over WebSocket
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (goethereum.Subscription, error)
+}
+
+// ListenForNewBlocks calls recvBlockHandler for every new head until quitChan is closed, ctx is
+// done or the subscription fails. It returns the subscription error, so that the caller can
+// resubscribe.
 func (c *Client) ListenForNewBlocks(
 	ctx context.Context,
-	wsc *ethclient.Client,
+	wsc HeadSubscriber,
 	quitChan chan struct{},
 	recvBlockHandler func(block *types.Header) error,
 ) error {
@@ -123,12 +179,19 @@ func (c *Client) ListenForNewBlocks(
 	if err != nil {
 		return err
 	}
+	defer sub.Unsubscribe()
 
-	defer close(ch)
 	for {
 		select {
-		case err := <-sub.Err():
+		case err, ok := <-sub.Err():
+			if !ok || err == nil {
+				err = fmt.Errorf("head subscription closed")
+			}
 			c.Logger.Sugar().Errorw("Received error", zap.Error(err))
+			return err
+		case <-ctx.Done():
+			c.Logger.Sugar().Infow("Context done, stopping head subscription")
+			return nil
 		case header := <-ch:
 			err = recvBlockHandler(header)
 			if err != nil {
@@ -167,9 +230,12 @@ func (c *Client) GetBlockNumberUint64(ctx context.Context) (uint64, error) {
 
 func (c *Client) GetLatestBlock(ctx context.Context) (uint64, error) {
 	var rpcRequest *RPCRequest
-	if c.clientConfig.BlockType == BlockType_Latest {
+	switch c.clientConfig.BlockType {
+	case BlockType_Latest:
 		rpcRequest = GetLatestBlockRequest(1)
-	} else {
+	case BlockType_Finalized:
+		rpcRequest = GetFinalizedBlockRequest(1)
+	default:
 		rpcRequest = GetSafeBlockRequest(1)
 	}
 
@@ -279,6 +345,26 @@ func (c *Client) GetLogs(ctx context.Context, address string, fromBlock uint64,
 	return logs, nil
 }
 
+// GetLogsForAddresses returns the logs emitted by any of the addresses in the block range with one
+// eth_getLogs call. When topics is not empty, only logs whose first topic is one of them are returned.
+func (c *Client) GetLogsForAddresses(ctx context.Context, addresses []string, topics []string, fromBlock uint64, toBlock uint64) ([]*EthereumEventLog, error) {
+	rpcRequest := GetLogsForAddressesRequest(addresses, topics, fromBlock, toBlock, 1)
+
+	res, err := c.Call(ctx, rpcRequest)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := RPCMethod_getLogs.ResponseParser(res.Result)
+	if err != nil {
+		c.Logger.Sugar().Errorw("failed to parse logs",
+			zap.Error(err),
+			zap.Any("raw response", res.Result),
+		)
+		return nil, err
+	}
+	return logs, nil
+}
+
 type BatchRPCRequest[T any] struct {
 	Request *RPCRequest
 	Handler ResponseParserFunc[T]
@@ -297,7 +383,7 @@ func (c *Client) batchCall(ctx context.Context, requests []*RPCRequest) ([]*RPCR
 	ctx, cancel := context.WithTimeout(ctx, time.Second*20)
 	defer cancel()
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.clientConfig.BaseUrl, bytes.NewReader(requestBody))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.rankedEndpoints()[0].url, bytes.NewReader(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to make request: %s", err)
 	}
@@ -522,7 +608,22 @@ func (c *Client) BatchCall(ctx context.Context, requests []*RPCRequest) ([]*RPCR
 	return c.chunkedBatchCall(ctx, requests)
 }
 
-func (c *Client) call(ctx context.Context, rpcRequest *RPCRequest) (*RPCResponse, error) {
+// callEndpoint sends the request to one endpoint and records how the endpoint did
+func (c *Client) callEndpoint(ctx context.Context, e *endpoint, rpcRequest *RPCRequest) (*RPCResponse, error) {
+	started := time.Now()
+	res, err := c.call(ctx, e.url, rpcRequest)
+	if err == nil {
+		e.recordSuccess(time.Since(started))
+		return res, nil
+	}
+	var rpcErr *RPCError
+	if ctx.Err() == nil && (!errors.As(err, &rpcErr) || rpcErr.isRetryable()) {
+		e.recordFailure()
+	}
+	return nil, err
+}
+
+func (c *Client) call(ctx context.Context, url string, rpcRequest *RPCRequest) (*RPCResponse, error) {
 	requestBody, err := json.Marshal(rpcRequest)
 
 	c.Logger.Sugar().Debug("Request body", zap.String("requestBody", string(requestBody)))
@@ -530,10 +631,11 @@ func (c *Client) call(ctx context.Context, rpcRequest *RPCRequest) (*RPCResponse
 		return nil, err
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, RPCMethod_GetBlock.RequestMethod.Timeout)
+	// bounds the request even when SetHttpClient replaced the client without a timeout
+	callCtx, cancel := context.WithTimeout(ctx, c.clientConfig.GetRequestTimeout())
 	defer cancel()
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.clientConfig.BaseUrl, bytes.NewReader(requestBody))
+	request, err := http.NewRequestWithContext(callCtx, http.MethodPost, url, bytes.NewReader(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to make request %s", err)
 	}
@@ -543,52 +645,77 @@ func (c *Client) call(ctx context.Context, rpcRequest *RPCRequest) (*RPCResponse
 
 	response, err := c.httpClient.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("Request failed %s", err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &TransportError{Url: url, Err: err}
 	}
+	defer response.Body.Close()
 
 	responseBody, err := io.ReadAll(response.Body)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to read body %s", err)
+		return nil, &TransportError{Url: url, Err: fmt.Errorf("failed to read body: %w", err)}
 	}
 	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received http error code %+v", response.StatusCode)
+		return nil, &TransportError{Url: url, StatusCode: response.StatusCode}
 	}
 
 	destination := &RPCResponse{}
 	if err := json.Unmarshal(responseBody, destination); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %s", err)
+		return nil, &TransportError{Url: url, Err: fmt.Errorf("failed to unmarshal response: %w", err)}
 	}
 
 	if destination.Error != nil {
-		return nil, fmt.Errorf("received error response: %+v", destination.Error)
+		if destination.Error.isTooManyResults() {
+			return nil, fmt.Errorf("%w: %w", ErrTooManyResults, destination.Error)
+		}
+		return nil, destination.Error
 	}
 
-	response.Body.Close()
-
 	return destination, nil
 }
 
+// Call sends the request to the healthiest endpoint. Reads that fail with a retryable error are
+// tried on the next endpoint, and every endpoint is retried with a backoff. Errors are a
+// *TransportError, an *RPCError, ErrTooManyResults or ErrEndpointsExhausted.
 func (c *Client) Call(ctx context.Context, rpcRequest *RPCRequest) (*RPCResponse, error) {
-	backoffs := []int{1, 3, 5, 10, 20, 30, 60}
-
-	for i, backoff := range backoffs {
-		res, err := c.call(ctx, rpcRequest)
-		if err == nil {
-			if i > 0 {
-				c.Logger.Sugar().Infow("Successfully called after backoff",
-					zap.Int("backoffSecs", backoff),
-					zap.Any("rpcRequest", rpcRequest),
-				)
+	retry := !slices.Contains(nonIdempotentMethods, rpcRequest.Method)
+
+	var lastErr error
+	for i, backoff := range callBackoffs {
+		for _, e := range c.rankedEndpoints() {
+			res, err := c.callEndpoint(ctx, e, rpcRequest)
+			if err == nil {
+				if i > 0 {
+					c.Logger.Sugar().Infow("Successfully called after backoff",
+						zap.Int("backoffSecs", backoff),
+						zap.String("url", e.url),
+						zap.Any("rpcRequest", rpcRequest),
+					)
+				}
+				return res, nil
 			}
-			return res, nil
+			if !retry || !IsRetryable(err) {
+				return nil, err
+			}
+			lastErr = err
+			c.Logger.Sugar().Warnw("RPC call failed on endpoint",
+				zap.String("url", e.url),
+				zap.String("method", rpcRequest.Method),
+				zap.Error(err),
+			)
 		}
 		c.Logger.Sugar().Errorw("Failed to call",
-			zap.Error(err),
+			zap.Error(lastErr),
 			zap.Int("backoffSecs", backoff),
 			zap.Any("rpcRequest", rpcRequest),
 		)
-		time.Sleep(time.Second * time.Duration(backoff))
+		select {
+		case <-time.After(time.Second * time.Duration(backoff)):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
 	c.Logger.Sugar().Errorw("Exceeded retries for Call", zap.Any("rpcRequest", rpcRequest))
-	return nil, fmt.Errorf("Exceeded retries for Call")
+	return nil, fmt.Errorf("%w: %s: %w", ErrEndpointsExhausted, rpcRequest.Method, lastErr)
 }
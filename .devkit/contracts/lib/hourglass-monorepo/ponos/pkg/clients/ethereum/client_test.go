@@ -2,7 +2,11 @@ package ethereum
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -48,3 +52,48 @@ func Test_EthereumClient(t *testing.T) {
 		}
 	})
 }
+
+func Test_GetLogsForAddresses(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{
+		Debug: false,
+	})
+	assert.Nil(t, err)
+
+	newServer := func(t *testing.T, respond func(req *RPCRequest) string) (*Client, *atomic.Int32) {
+		calls := &atomic.Int32{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			var req *RPCRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+			_, _ = w.Write([]byte(respond(req)))
+		}))
+		t.Cleanup(server.Close)
+		return NewEthereumClient(&EthereumClientConfig{BaseUrl: server.URL, BlockType: BlockType_Latest}, l), calls
+	}
+
+	t.Run("sends one filter for all addresses and topics", func(t *testing.T) {
+		var filter map[string]interface{}
+		client, _ := newServer(t, func(req *RPCRequest) string {
+			filter = req.Params.([]interface{})[0].(map[string]interface{})
+			return `{"jsonrpc":"2.0","id":1,"result":[{"address":"0xaa","blockNumber":"0x5","logIndex":"0x0"}]}`
+		})
+
+		logs, err := client.GetLogsForAddresses(context.Background(), []string{"0xaa", "0xbb"}, []string{"0x01"}, 5, 10)
+		assert.Nil(t, err)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, []interface{}{"0xaa", "0xbb"}, filter["address"])
+		assert.Equal(t, []interface{}{[]interface{}{"0x01"}}, filter["topics"])
+		assert.Equal(t, "0x5", filter["fromBlock"])
+		assert.Equal(t, "0xa", filter["toBlock"])
+	})
+
+	t.Run("returns ErrTooManyResults without retrying", func(t *testing.T) {
+		client, calls := newServer(t, func(req *RPCRequest) string {
+			return `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`
+		})
+
+		_, err := client.GetLogsForAddresses(context.Background(), []string{"0xaa"}, nil, 0, 100000)
+		assert.ErrorIs(t, err, ErrTooManyResults)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
@@ -0,0 +1,194 @@
+package ethereum
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+)
+
+const (
+	// errorPenaltyMs is how much a recent error weighs against an endpoint, in milliseconds of latency
+	errorPenaltyMs = 1000.0
+
+	// headLagPenaltyMs is how much every block an endpoint is behind weighs, in milliseconds of latency
+	headLagPenaltyMs = 500.0
+
+	DefaultHealthCheckInterval = 30 * time.Second
+)
+
+// endpoint is an RPC url and what the client observed about it
+type endpoint struct {
+	url string
+
+	mu sync.Mutex
+	// latency is a moving average of successful calls
+	latency time.Duration
+	// errorScore grows with every failure and halves with every success
+	errorScore float64
+	// head is the latest block the endpoint reported during a health check
+	head uint64
+}
+
+// EndpointHealth is a snapshot of how the client scores an endpoint. Lower scores are preferred.
+type EndpointHealth struct {
+	Url        string
+	Latency    time.Duration
+	ErrorScore float64
+	HeadLag    uint64
+	Score      float64
+}
+
+func newEndpoints(cfg *EthereumClientConfig) []*endpoint {
+	urls := make([]string, 0, 1+len(cfg.FallbackUrls))
+	for _, url := range append([]string{cfg.BaseUrl}, cfg.FallbackUrls...) {
+		if url != "" && !slices.Contains(urls, url) {
+			urls = append(urls, url)
+		}
+	}
+	endpoints := make([]*endpoint, 0, len(urls))
+	for _, url := range urls {
+		endpoints = append(endpoints, &endpoint{url: url})
+	}
+	return endpoints
+}
+
+func (e *endpoint) recordSuccess(latency time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.latency == 0 {
+		e.latency = latency
+	} else {
+		e.latency = (e.latency*4 + latency) / 5
+	}
+	e.errorScore /= 2
+}
+
+func (e *endpoint) recordFailure() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.errorScore++
+}
+
+func (e *endpoint) recordHead(head uint64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.head = head
+}
+
+func (e *endpoint) health(maxHead uint64) EndpointHealth {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var lag uint64
+	if e.head > 0 && maxHead > e.head {
+		lag = maxHead - e.head
+	}
+	return EndpointHealth{
+		Url:        e.url,
+		Latency:    e.latency,
+		ErrorScore: e.errorScore,
+		HeadLag:    lag,
+		Score:      float64(e.latency)/float64(time.Millisecond) + e.errorScore*errorPenaltyMs + float64(lag)*headLagPenaltyMs,
+	}
+}
+
+// EndpointHealth returns the health of every endpoint, healthiest first
+func (c *Client) EndpointHealth() []EndpointHealth {
+	var maxHead uint64
+	for _, e := range c.endpoints {
+		e.mu.Lock()
+		maxHead = max(maxHead, e.head)
+		e.mu.Unlock()
+	}
+	health := make([]EndpointHealth, 0, len(c.endpoints))
+	for _, e := range c.endpoints {
+		health = append(health, e.health(maxHead))
+	}
+	// ties keep the configured order, so the primary endpoint is preferred
+	slices.SortStableFunc(health, func(a, b EndpointHealth) int {
+		switch {
+		case a.Score < b.Score:
+			return -1
+		case a.Score > b.Score:
+			return 1
+		}
+		return 0
+	})
+	return health
+}
+
+// rankedEndpoints returns the endpoints healthiest first and starts a background health check when
+// the last one is older than the health check interval
+func (c *Client) rankedEndpoints() []*endpoint {
+	if len(c.endpoints) == 1 {
+		return c.endpoints
+	}
+	c.maybeCheckEndpoints()
+
+	ranked := make([]*endpoint, 0, len(c.endpoints))
+	for _, h := range c.EndpointHealth() {
+		idx := slices.IndexFunc(c.endpoints, func(e *endpoint) bool {
+			return e.url == h.Url
+		})
+		ranked = append(ranked, c.endpoints[idx])
+	}
+	return ranked
+}
+
+func (c *Client) maybeCheckEndpoints() {
+	interval := c.clientConfig.HealthCheckInterval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+	c.healthMu.Lock()
+	due := !c.checkingHealth && time.Since(c.lastHealthCheck) >= interval
+	if due {
+		c.checkingHealth = true
+	}
+	c.healthMu.Unlock()
+	if !due {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		c.CheckEndpoints(ctx)
+	}()
+}
+
+// CheckEndpoints asks every endpoint for its latest block, recording its latency, failures and how
+// far it is behind the other endpoints
+func (c *Client) CheckEndpoints(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, e := range c.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			res, err := c.callEndpoint(ctx, e, GetBlockRequest(1))
+			if err != nil {
+				c.Logger.Sugar().Warnw("RPC endpoint health check failed",
+					zap.String("url", e.url),
+					zap.Error(err),
+				)
+				return
+			}
+			var head hexutil.Uint64
+			if err := head.UnmarshalJSON(res.Result); err != nil {
+				e.recordFailure()
+				return
+			}
+			e.recordHead(uint64(head))
+		}(e)
+	}
+	wg.Wait()
+
+	c.healthMu.Lock()
+	c.lastHealthCheck = time.Now()
+	c.checkingHealth = false
+	c.healthMu.Unlock()
+	c.Logger.Sugar().Debugw("Checked RPC endpoints", zap.Any("endpoints", c.EndpointHealth()))
+}
@@ -0,0 +1,142 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testEndpoint is an RPC server that answers every request with the same response
+type testEndpoint struct {
+	url   string
+	calls *atomic.Int32
+}
+
+func newTestEndpoint(t *testing.T, status int, body string) *testEndpoint {
+	calls := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return &testEndpoint{url: server.URL, calls: calls}
+}
+
+func Test_ClientEndpoints(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	assert.Nil(t, err)
+
+	newClient := func(primary *testEndpoint, fallbacks ...*testEndpoint) *Client {
+		urls := make([]string, 0, len(fallbacks))
+		for _, f := range fallbacks {
+			urls = append(urls, f.url)
+		}
+		return NewEthereumClient(&EthereumClientConfig{
+			BaseUrl:      primary.url,
+			FallbackUrls: urls,
+			BlockType:    BlockType_Latest,
+		}, l)
+	}
+
+	t.Run("fails over to the next endpoint and prefers it afterwards", func(t *testing.T) {
+		down := newTestEndpoint(t, http.StatusBadGateway, "")
+		up := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+		client := newClient(down, up)
+
+		block, err := client.GetBlockNumberUint64(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(16), block)
+		assert.Equal(t, int32(1), down.calls.Load())
+
+		_, err = client.GetBlockNumberUint64(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, up.url, client.EndpointHealth()[0].Url)
+	})
+
+	t.Run("returns rpc errors without trying other endpoints", func(t *testing.T) {
+		reverting := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`)
+		other := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+		client := newClient(reverting, other)
+
+		_, err := client.Call(context.Background(), GetBlockRequest(1))
+		var rpcErr *RPCError
+		assert.True(t, errors.As(err, &rpcErr))
+		assert.Equal(t, int64(3), rpcErr.Code)
+		assert.False(t, IsRetryable(err))
+		assert.Equal(t, int32(0), other.calls.Load())
+	})
+
+	t.Run("fails over when the endpoint is behind the requested block", func(t *testing.T) {
+		behind := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"header not found"}}`)
+		synced := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":{"number":"0x10","hash":"0x01"}}`)
+		client := newClient(behind, synced)
+
+		block, err := client.GetBlockByNumber(context.Background(), 16)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(16), block.Number.Value())
+		assert.Equal(t, int32(1), behind.calls.Load())
+		assert.Equal(t, synced.url, client.EndpointHealth()[0].Url)
+	})
+
+	t.Run("fails over when a request exceeds the request timeout", func(t *testing.T) {
+		slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+		}))
+		t.Cleanup(slowServer.Close)
+		fast := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+		client := NewEthereumClient(&EthereumClientConfig{
+			BaseUrl:        slowServer.URL,
+			FallbackUrls:   []string{fast.url},
+			BlockType:      BlockType_Latest,
+			RequestTimeout: 50 * time.Millisecond,
+		}, l)
+
+		block, err := client.GetBlockNumberUint64(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(16), block)
+	})
+
+	t.Run("does not retry transactions on another endpoint", func(t *testing.T) {
+		down := newTestEndpoint(t, http.StatusInternalServerError, "")
+		other := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+		client := newClient(down, other)
+
+		_, err := client.Call(context.Background(), &RPCRequest{JSONRPC: jsonRPCVersion, Method: "eth_sendRawTransaction", ID: 1})
+		var transportErr *TransportError
+		assert.True(t, errors.As(err, &transportErr))
+		assert.Equal(t, http.StatusInternalServerError, transportErr.StatusCode)
+		assert.Equal(t, int32(0), other.calls.Load())
+	})
+
+	t.Run("ranks endpoints that lag behind the head last", func(t *testing.T) {
+		lagging := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"0x64"}`)
+		synced := newTestEndpoint(t, http.StatusOK, `{"jsonrpc":"2.0","id":1,"result":"0x6e"}`)
+		client := newClient(lagging, synced)
+
+		client.CheckEndpoints(context.Background())
+		health := client.EndpointHealth()
+		assert.Equal(t, synced.url, health[0].Url)
+		assert.Equal(t, uint64(10), health[1].HeadLag)
+	})
+
+	t.Run("stops retrying when the context is cancelled", func(t *testing.T) {
+		down := newTestEndpoint(t, http.StatusServiceUnavailable, "")
+		client := newClient(down)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := client.Call(ctx, GetBlockRequest(1))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, IsRetryable(err))
+	})
+}
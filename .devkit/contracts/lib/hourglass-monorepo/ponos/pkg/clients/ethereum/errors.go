@@ -0,0 +1,118 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ErrTooManyResults is returned when the provider refuses an eth_getLogs query because its block
+// range or result set is too large. The query succeeds with a smaller block range.
+var ErrTooManyResults = errors.New("too many results for log query")
+
+// ErrEndpointsExhausted is returned when a call failed on every endpoint after all retries
+var ErrEndpointsExhausted = errors.New("rpc call failed on all endpoints")
+
+// tooManyResultsMessages are the error messages providers use to refuse oversized eth_getLogs queries
+var tooManyResultsMessages = []string{
+	"too many results",
+	"query returned more than",
+	"query exceeds max results",
+	"log response size exceeded",
+	"block range too large",
+	"range is too large",
+	"exceed maximum block range",
+	"eth_getlogs is limited to",
+}
+
+// endpointStateMessages are the error messages of nodes that cannot serve a request because of their
+// own state, e.g. when they are behind the other endpoints or pruned the state. Nodes answer them
+// with the generic -32000 code, which is also used for errors that do not depend on the endpoint,
+// such as reverts, so they are matched by message.
+var endpointStateMessages = []string{
+	"header not found",
+	"unknown block",
+	"block not found",
+	"missing trie node",
+	"state not available",
+	"state is not available",
+	"historical state",
+	"node is syncing",
+}
+
+// retryableRPCErrorCodes are JSON-RPC error codes providers use for transient failures
+var retryableRPCErrorCodes = []int64{
+	-32005, // limit exceeded
+	-32603, // internal error
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc error %d: %s", e.Code, e.Message)
+}
+
+func (e *RPCError) isTooManyResults() bool {
+	msg := strings.ToLower(e.Message)
+	return slices.ContainsFunc(tooManyResultsMessages, func(m string) bool {
+		return strings.Contains(msg, m)
+	})
+}
+
+// isEndpointState reports whether the error is caused by the state of the endpoint rather than by
+// the request, so that another endpoint may answer it
+func (e *RPCError) isEndpointState() bool {
+	msg := strings.ToLower(e.Message)
+	return slices.ContainsFunc(endpointStateMessages, func(m string) bool {
+		return strings.Contains(msg, m)
+	})
+}
+
+// isRetryable reports whether the endpoint, or another one, is likely to answer differently later,
+// e.g. when it is rate limiting or behind the chain head
+func (e *RPCError) isRetryable() bool {
+	return slices.Contains(retryableRPCErrorCodes, e.Code) ||
+		strings.Contains(strings.ToLower(e.Message), "rate limit") ||
+		e.isEndpointState()
+}
+
+// TransportError is returned when an endpoint could not be reached or did not answer with a
+// JSON-RPC response
+type TransportError struct {
+	Url string
+
+	// StatusCode is the HTTP status the endpoint answered with, 0 if there was no response
+	StatusCode int
+	Err        error
+}
+
+func (e *TransportError) Error() string {
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("rpc endpoint %s returned http status %d", e.Url, e.StatusCode)
+	}
+	return fmt.Sprintf("rpc endpoint %s failed: %v", e.Url, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether a failed call may succeed when it is retried, on another endpoint or
+// later. Errors the node answered deliberately, such as reverts or invalid params, are not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrTooManyResults) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrEndpointsExhausted) {
+		return true
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.isRetryable()
+	}
+	return false
+}
@@ -124,6 +124,15 @@ func GetSafeBlockRequest(id uint) *RPCRequest {
 	}
 }
 
+func GetFinalizedBlockRequest(id uint) *RPCRequest {
+	return &RPCRequest{
+		JSONRPC: jsonRPCVersion,
+		Method:  RPCMethod_getBlockByNumber.RequestMethod.Name,
+		Params:  []interface{}{"finalized", true},
+		ID:      id,
+	}
+}
+
 func GetLatestBlockRequest(id uint) *RPCRequest {
 	return &RPCRequest{
 		JSONRPC: jsonRPCVersion,
@@ -161,6 +170,25 @@ func GetBlockReceiptsRequest(blockNumber uint64, id uint) *RPCRequest {
 	}
 }
 
+func GetLogsForAddressesRequest(addresses []string, topics []string, fromBlock uint64, toBlock uint64, id uint) *RPCRequest {
+	filter := map[string]interface{}{
+		"address":   addresses,
+		"fromBlock": hexutil.EncodeUint64(fromBlock),
+		"toBlock":   hexutil.EncodeUint64(toBlock),
+	}
+	if len(topics) > 0 {
+		// topics in the same position are OR-ed
+		filter["topics"] = [][]string{topics}
+	}
+
+	return &RPCRequest{
+		JSONRPC: jsonRPCVersion,
+		Method:  RPCMethod_getLogs.RequestMethod.Name,
+		Params:  []interface{}{filter},
+		ID:      id,
+	}
+}
+
 func GetLogsRequest(address string, fromBlock uint64, toBlock uint64, id uint) *RPCRequest {
 	hexFromBlock := hexutil.EncodeUint64(fromBlock)
 	hexToBlock := hexutil.EncodeUint64(toBlock)
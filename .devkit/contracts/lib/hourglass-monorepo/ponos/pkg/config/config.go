@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"slices"
+	"strings"
 )
 
 type ChainId uint
@@ -18,6 +19,7 @@ const (
 const (
 	ContractName_AllocationManager = "AllocationManager"
 	ContractName_TaskMailbox       = "TaskMailbox"
+	ContractName_TaskAVSRegistrar  = "TaskAVSRegistrar"
 )
 
 const (
@@ -154,3 +156,35 @@ type SimulatedPeeringConfig struct {
 	AggregatorPeers []SimulatedPeer `json:"aggregatorPeers" yaml:"aggregatorPeers"`
 	OperatorPeers   []SimulatedPeer `json:"operatorPeers" yaml:"operatorPeers"`
 }
+
+// AvsRegistrarConfig overrides the TaskAVSRegistrar an AVS reads operator peering data from on a chain
+type AvsRegistrarConfig struct {
+	ChainId    ChainId `json:"chainId" yaml:"chainId"`
+	AvsAddress string  `json:"avsAddress" yaml:"avsAddress"`
+	Address    string  `json:"address" yaml:"address"`
+}
+
+func (arc *AvsRegistrarConfig) Validate(path *field.Path) field.ErrorList {
+	var allErrors field.ErrorList
+	if arc.ChainId == 0 {
+		allErrors = append(allErrors, field.Required(path.Child("chainId"), "chainId is required"))
+	}
+	if arc.AvsAddress == "" {
+		allErrors = append(allErrors, field.Required(path.Child("avsAddress"), "avsAddress is required"))
+	}
+	if arc.Address == "" {
+		allErrors = append(allErrors, field.Required(path.Child("address"), "address is required"))
+	}
+	return allErrors
+}
+
+// AvsRegistrarsForChain returns the registrar overrides for the chain, keyed by lower-cased AVS address
+func AvsRegistrarsForChain(registrars []*AvsRegistrarConfig, chainId ChainId) map[string]string {
+	overrides := make(map[string]string)
+	for _, r := range registrars {
+		if r.ChainId == chainId {
+			overrides[strings.ToLower(r.AvsAddress)] = r.Address
+		}
+	}
+	return overrides
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"testing"
+)
+
+func Test_AvsRegistrarConfig(t *testing.T) {
+	t.Run("requires every field", func(t *testing.T) {
+		errs := (&AvsRegistrarConfig{}).Validate(field.NewPath("avsRegistrars").Index(0))
+		assert.Len(t, errs, 3)
+	})
+
+	t.Run("keys overrides for the chain by lower-cased AVS address", func(t *testing.T) {
+		registrars := []*AvsRegistrarConfig{
+			{ChainId: ChainId_EthereumHolesky, AvsAddress: "0xAvs1", Address: "0xregistrar1"},
+			{ChainId: ChainId_EthereumMainnet, AvsAddress: "0xAvs1", Address: "0xregistrar2"},
+			{ChainId: ChainId_EthereumHolesky, AvsAddress: "0xavs2", Address: "0xregistrar3"},
+		}
+		assert.Equal(t, map[string]string{
+			"0xavs1": "0xregistrar1",
+			"0xavs2": "0xregistrar3",
+		}, AvsRegistrarsForChain(registrars, ChainId_EthereumHolesky))
+		assert.Empty(t, AvsRegistrarsForChain(registrars, ChainId_EthereumAnvil))
+	})
+}
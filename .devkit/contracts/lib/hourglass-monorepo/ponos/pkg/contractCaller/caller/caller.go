@@ -2,6 +2,7 @@ package caller
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"crypto/ecdsa"
 	"encoding/binary"
@@ -17,6 +18,7 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionManager"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
@@ -26,16 +28,25 @@ import (
 	"go.uber.org/zap"
 	"math/big"
 	"slices"
+	"strings"
 )
 
 type ContractCallerConfig struct {
 	PrivateKey          string
 	AVSRegistrarAddress string
 	TaskMailboxAddress  string
+
+	// AVSRegistrarAddresses overrides AVSRegistrarAddress for single AVSs, keyed by lower-cased AVS address
+	AVSRegistrarAddresses map[string]string
+
+	// TransactionManager sends transactions when set, so that nonces are tracked and stuck
+	// transactions are replaced. Otherwise each transaction is sent once with estimated fees.
+	TransactionManager transactionManager.ITransactionManager
 }
 
 type ContractCaller struct {
 	avsRegistrarCaller          *ITaskAVSRegistrar.ITaskAVSRegistrarCaller
+	avsRegistrarCallers         map[string]*ITaskAVSRegistrar.ITaskAVSRegistrarCaller
 	taskMailboxCaller           *ITaskMailbox.ITaskMailboxCaller
 	taskMailboxTransactor       *ITaskMailbox.ITaskMailboxTransactor
 	allocationManagerCaller     *IAllocationManager.IAllocationManagerCaller
@@ -65,9 +76,21 @@ func NewContractCaller(
 	ethclient *ethclient.Client,
 	logger *zap.Logger,
 ) (*ContractCaller, error) {
-	avsRegistrarCaller, err := ITaskAVSRegistrar.NewITaskAVSRegistrarCaller(common.HexToAddress(cfg.AVSRegistrarAddress), ethclient)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create AVSRegistrar caller: %w", err)
+	var avsRegistrarCaller *ITaskAVSRegistrar.ITaskAVSRegistrarCaller
+	if cfg.AVSRegistrarAddress != "" {
+		c, err := ITaskAVSRegistrar.NewITaskAVSRegistrarCaller(common.HexToAddress(cfg.AVSRegistrarAddress), ethclient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AVSRegistrar caller: %w", err)
+		}
+		avsRegistrarCaller = c
+	}
+	avsRegistrarCallers := make(map[string]*ITaskAVSRegistrar.ITaskAVSRegistrarCaller, len(cfg.AVSRegistrarAddresses))
+	for avsAddress, registrarAddress := range cfg.AVSRegistrarAddresses {
+		c, err := ITaskAVSRegistrar.NewITaskAVSRegistrarCaller(common.HexToAddress(registrarAddress), ethclient)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create AVSRegistrar caller for AVS %s: %w", avsAddress, err)
+		}
+		avsRegistrarCallers[strings.ToLower(avsAddress)] = c
 	}
 
 	taskMailboxCaller, err := ITaskMailbox.NewITaskMailboxCaller(common.HexToAddress(cfg.TaskMailboxAddress), ethclient)
@@ -106,6 +129,7 @@ func NewContractCaller(
 
 	return &ContractCaller{
 		avsRegistrarCaller:          avsRegistrarCaller,
+		avsRegistrarCallers:         avsRegistrarCallers,
 		taskMailboxCaller:           taskMailboxCaller,
 		taskMailboxTransactor:       taskMailboxTransactor,
 		allocationManagerCaller:     allocationManagerCaller,
@@ -118,6 +142,17 @@ func NewContractCaller(
 	}, nil
 }
 
+// registrarCallerForAvs returns the TaskAVSRegistrar configured for the AVS, or the default one
+func (cc *ContractCaller) registrarCallerForAvs(avsAddress string) (*ITaskAVSRegistrar.ITaskAVSRegistrarCaller, error) {
+	if c, ok := cc.avsRegistrarCallers[strings.ToLower(avsAddress)]; ok {
+		return c, nil
+	}
+	if cc.avsRegistrarCaller == nil {
+		return nil, fmt.Errorf("no AVS registrar configured for AVS %s", avsAddress)
+	}
+	return cc.avsRegistrarCaller, nil
+}
+
 func (cc *ContractCaller) buildNoSendOptsWithPrivateKey(ctx context.Context) (*bind.TransactOpts, *ecdsa.PrivateKey, error) {
 	privateKey, err := cryptoUtils.StringToECDSAPrivateKey(cc.config.PrivateKey)
 	if err != nil {
@@ -158,6 +193,11 @@ func (cc *ContractCaller) SubmitTaskResult(ctx context.Context, aggCert *aggrega
 	copy(taskId[:], aggCert.TaskId)
 	cc.logger.Sugar().Infow("submitting task result", "taskId", taskId)
 
+	// the TaskMailbox only verifies BN254 certificates
+	if aggCert.SigningCurve != "" && aggCert.SigningCurve != aggregation.SigningCurve_BN254 {
+		return nil, fmt.Errorf("the TaskMailbox does not accept %s certificates", aggCert.SigningCurve)
+	}
+
 	// Convert signature to G1 point in precompile format
 	g1Point := &bn254.G1Point{
 		G1Affine: aggCert.SignersSignature.GetG1Point(),
@@ -176,8 +216,18 @@ func (cc *ContractCaller) SubmitTaskResult(ctx context.Context, aggCert *aggrega
 	var digest [32]byte
 	copy(digest[:], aggCert.TaskResponseDigest)
 
+	nonSignerIndices, nonSignerWitnesses, err := buildNonSignerWitnesses(aggCert)
+	if err != nil {
+		return nil, err
+	}
+
+	if aggCert.SignedAt == nil || aggCert.SignedAt.IsZero() {
+		return nil, fmt.Errorf("certificate has no signing time")
+	}
+	referenceTimestamp := uint32(aggCert.SignedAt.Unix())
+
 	cert := ITaskMailbox.IBN254CertificateVerifierBN254Certificate{
-		ReferenceTimestamp: uint32(aggCert.SignedAt.Unix()),
+		ReferenceTimestamp: referenceTimestamp,
 		MessageHash:        digest,
 		Sig: ITaskMailbox.BN254G1Point{
 			X: new(big.Int).SetBytes(g1Bytes[0:32]),
@@ -193,12 +243,13 @@ func (cc *ContractCaller) SubmitTaskResult(ctx context.Context, aggCert *aggrega
 				new(big.Int).SetBytes(g2Bytes[96:128]),
 			},
 		},
-		// TODO: technically these are all empty since we default to needing all operators to sign.
-		NonsignerIndices:   []uint32{},
-		NonSignerWitnesses: []ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfoWitness{},
+		NonsignerIndices:   nonSignerIndices,
+		NonSignerWitnesses: nonSignerWitnesses,
 	}
-	fmt.Printf("taskId: %v\n", taskId)
-	fmt.Printf("Submitting task: %+v\n", cert)
+	cc.logger.Sugar().Debugw("Submitting task result certificate",
+		"taskId", taskId,
+		"certificate", cert,
+	)
 
 	tx, err := cc.taskMailboxTransactor.SubmitResult(noSendTxOpts, taskId, cert, aggCert.TaskResponse)
 	if err != nil {
@@ -208,6 +259,57 @@ func (cc *ContractCaller) SubmitTaskResult(ctx context.Context, aggCert *aggrega
 	return cc.EstimateGasPriceAndLimitAndSendTx(ctx, noSendTxOpts.From, tx, privateKey, "SubmitTaskSession")
 }
 
+// buildNonSignerWitnesses converts the certificate's non-signers into the indices and witnesses the
+// certificate verifier expects. The verifier requires the indices in strictly ascending order of the
+// operators' positions in the operator table, which are also the leaf indices of the operator info
+// tree. Each witness carries the operator's registered G1 key and weights along with the proof of its
+// leaf in that tree.
+func buildNonSignerWitnesses(aggCert *aggregation.AggregatedCertificate) ([]uint32, []ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfoWitness, error) {
+	indices := make([]uint32, 0, len(aggCert.NonSigners))
+	witnesses := make([]ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfoWitness, 0, len(aggCert.NonSigners))
+	if len(aggCert.NonSigners) == 0 {
+		return indices, witnesses, nil
+	}
+	if len(aggCert.OperatorTable) == 0 {
+		return nil, nil, fmt.Errorf("certificate has %d non-signers but no operator table", len(aggCert.NonSigners))
+	}
+
+	operatorInfos := util.Map(aggCert.OperatorTable, func(e *aggregation.OperatorTableEntry, i uint64) ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo {
+		g1 := e.PublicKey.GetG1Point()
+		return ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo{
+			Pubkey: ITaskMailbox.BN254G1Point{
+				X: g1.X.BigInt(new(big.Int)),
+				Y: g1.Y.BigInt(new(big.Int)),
+			},
+			Weights: e.Weights,
+		}
+	})
+	tree, err := newOperatorInfoTree(operatorInfos)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonSigners := slices.Clone(aggCert.NonSigners)
+	slices.SortFunc(nonSigners, func(a, b *aggregation.NonSigner) int {
+		return cmp.Compare(a.OperatorIndex, b.OperatorIndex)
+	})
+	for i, ns := range nonSigners {
+		if int(ns.OperatorIndex) >= len(operatorInfos) {
+			return nil, nil, fmt.Errorf("non-signer %s has index %d outside the operator table", ns.Operator.Address, ns.OperatorIndex)
+		}
+		if i > 0 && nonSigners[i-1].OperatorIndex == ns.OperatorIndex {
+			return nil, nil, fmt.Errorf("operator index %d is listed as a non-signer twice", ns.OperatorIndex)
+		}
+		indices = append(indices, ns.OperatorIndex)
+		witnesses = append(witnesses, ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfoWitness{
+			OperatorIndex:      ns.OperatorIndex,
+			OperatorInfoProofs: tree.proof(int(ns.OperatorIndex)),
+			OperatorInfo:       operatorInfos[ns.OperatorIndex],
+		})
+	}
+	return indices, witnesses, nil
+}
+
 //nolint:unused
 func encodeOperatorOutputMap(m map[string][]byte) ([]byte, error) {
 	var buf bytes.Buffer
@@ -242,6 +344,14 @@ func encodeOperatorOutputMap(m map[string][]byte) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+func (cc *ContractCaller) GetBlockNumber(ctx context.Context) (uint64, error) {
+	blockNumber, err := cc.ethclient.BlockNumber(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	return blockNumber, nil
+}
+
 func (cc *ContractCaller) GetOperatorSets(avsAddress string) ([]uint32, error) {
 	avsAddr := common.HexToAddress(avsAddress)
 	opSets, err := cc.allocationManagerCaller.GetRegisteredSets(&bind.CallOpts{}, avsAddr)
@@ -280,7 +390,11 @@ func (cc *ContractCaller) GetOperatorSetMembersWithPeering(
 		return nil, err
 	}
 
-	peerMembers, err := cc.avsRegistrarCaller.GetBatchOperatorPubkeyInfoAndSocket(&bind.CallOpts{}, util.Map(members, func(mem string, i uint64) common.Address {
+	registrarCaller, err := cc.registrarCallerForAvs(avsAddress)
+	if err != nil {
+		return nil, err
+	}
+	peerMembers, err := registrarCaller.GetBatchOperatorPubkeyInfoAndSocket(&bind.CallOpts{}, util.Map(members, func(mem string, i uint64) common.Address {
 		return common.HexToAddress(mem)
 	}))
 	if err != nil {
@@ -299,6 +413,7 @@ func (cc *ContractCaller) GetOperatorSetMembersWithPeering(
 		allMembers = append(allMembers, &peering.OperatorPeerInfo{
 			NetworkAddress:  pm.Socket,
 			PublicKey:       pubKey,
+			CurveType:       "bn254",
 			OperatorAddress: members[i],
 			OperatorSetIds:  []uint32{operatorSetId},
 		})
@@ -306,6 +421,112 @@ func (cc *ContractCaller) GetOperatorSetMembersWithPeering(
 	return allMembers, nil
 }
 
+func (cc *ContractCaller) GetOperatorStakeWeights(
+	ctx context.Context,
+	avsAddress string,
+	operatorSetId uint32,
+	operators []string,
+	blockNumber uint64,
+) ([]*big.Int, error) {
+	stakes, err := cc.getAllocatedStakes(ctx, avsAddress, operatorSetId, operators, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return util.Map(stakes, func(strategyStakes []*big.Int, i uint64) *big.Int {
+		weight := new(big.Int)
+		for _, stake := range strategyStakes {
+			weight.Add(weight, stake)
+		}
+		return weight
+	}), nil
+}
+
+// getAllocatedStakes returns the stake each operator allocated to the operator set at blockNumber,
+// with one entry per strategy of the operator set, in the same order as operators
+func (cc *ContractCaller) getAllocatedStakes(
+	ctx context.Context,
+	avsAddress string,
+	operatorSetId uint32,
+	operators []string,
+	blockNumber uint64,
+) ([][]*big.Int, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)}
+	operatorSet := IAllocationManager.OperatorSet{
+		Avs: common.HexToAddress(avsAddress),
+		Id:  operatorSetId,
+	}
+	strategies, err := cc.allocationManagerCaller.GetStrategiesInOperatorSet(opts, operatorSet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategies in operator set: %w", err)
+	}
+	operatorAddresses := util.Map(operators, func(op string, i uint64) common.Address {
+		return common.HexToAddress(op)
+	})
+	allocated, err := cc.allocationManagerCaller.GetAllocatedStake(opts, operatorSet, operatorAddresses, strategies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocated stake: %w", err)
+	}
+	if len(allocated) != len(operators) {
+		return nil, fmt.Errorf("expected stake for %d operators, got %d", len(operators), len(allocated))
+	}
+	return allocated, nil
+}
+
+func (cc *ContractCaller) GetOperatorTable(
+	ctx context.Context,
+	avsAddress string,
+	operatorSetId uint32,
+	blockNumber uint64,
+) ([]*aggregation.OperatorTableEntry, error) {
+	opts := &bind.CallOpts{Context: ctx, BlockNumber: new(big.Int).SetUint64(blockNumber)}
+	members, err := cc.allocationManagerCaller.GetMembers(opts, IAllocationManager.OperatorSet{
+		Avs: common.HexToAddress(avsAddress),
+		Id:  operatorSetId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator set members: %w", err)
+	}
+	if len(members) == 0 {
+		return []*aggregation.OperatorTableEntry{}, nil
+	}
+
+	registrarCaller, err := cc.registrarCallerForAvs(avsAddress)
+	if err != nil {
+		return nil, err
+	}
+	pubkeyInfos, err := registrarCaller.GetBatchOperatorPubkeyInfoAndSocket(opts, members)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator public keys: %w", err)
+	}
+	if len(pubkeyInfos) != len(members) {
+		return nil, fmt.Errorf("expected public keys for %d operators, got %d", len(members), len(pubkeyInfos))
+	}
+
+	operators := util.Map(members, func(m common.Address, i uint64) string {
+		return m.String()
+	})
+	// the operator table keeps one weight per strategy, as the leaves of the verifier's operator info tree do
+	stakes, err := cc.getAllocatedStakes(ctx, avsAddress, operatorSetId, operators, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	table := make([]*aggregation.OperatorTableEntry, 0, len(members))
+	for i, info := range pubkeyInfos {
+		pubKey, err := bn254.NewPublicKeyFromSolidity(info.PubkeyInfo.PubkeyG1, info.PubkeyInfo.PubkeyG2)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert public key of operator %s: %w", operators[i], err)
+		}
+		table = append(table, &aggregation.OperatorTableEntry{
+			Address:    operators[i],
+			OperatorId: info.PubkeyInfo.PubkeyHash,
+			PublicKey:  pubKey,
+			Weights:    stakes[i],
+		})
+	}
+	return table, nil
+}
+
 func (cc *ContractCaller) GetMembersForAllOperatorSets(avsAddress string) (map[uint32][]string, error) {
 	operatorSets, err := cc.GetOperatorSets(avsAddress)
 	if err != nil {
@@ -331,7 +552,6 @@ func (cc *ContractCaller) GetAVSConfig(avsAddress string) (*contractCaller.AVSCo
 	}
 
 	return &contractCaller.AVSConfig{
-		ResultSubmitter:         avsConfig.ResultSubmitter.String(),
 		AggregatorOperatorSetId: avsConfig.AggregatorOperatorSetId,
 		ExecutorOperatorSetIds:  avsConfig.ExecutorOperatorSetIds,
 	}, nil
@@ -401,6 +621,9 @@ func (cc *ContractCaller) PublishMessageToInbox(ctx context.Context, avsAddress
 }
 
 func (cc *ContractCaller) GetOperatorRegistrationMessageHash(ctx context.Context, operatorAddress common.Address) (ITaskAVSRegistrar.BN254G1Point, error) {
+	if cc.avsRegistrarCaller == nil {
+		return ITaskAVSRegistrar.BN254G1Point{}, fmt.Errorf("no AVS registrar configured")
+	}
 	return cc.avsRegistrarCaller.PubkeyRegistrationMessageHash(&bind.CallOpts{
 		Context: ctx,
 	}, operatorAddress)
@@ -469,6 +692,9 @@ func (cc *ContractCaller) CreateOperatorRegistrationPayload(
 		},
 	}
 
+	if cc.avsRegistrarCaller == nil {
+		return nil, fmt.Errorf("no AVS registrar configured")
+	}
 	return cc.avsRegistrarCaller.PackRegisterPayload(&bind.CallOpts{}, socket, registrationPayload)
 }
 
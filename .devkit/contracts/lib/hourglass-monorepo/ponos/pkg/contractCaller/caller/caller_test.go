@@ -0,0 +1,75 @@
+package caller
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/contracts/pkg/bindings/ITaskMailbox"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func Test_BuildNonSignerWitnesses(t *testing.T) {
+	table := make([]*aggregation.OperatorTableEntry, 4)
+	for i := range table {
+		_, pubKey, err := bn254.GenerateKeyPair()
+		require.NoError(t, err)
+		table[i] = &aggregation.OperatorTableEntry{
+			Address:   fmt.Sprintf("0x%d", i+1),
+			PublicKey: pubKey,
+			Weights:   []*big.Int{big.NewInt(int64(10 * (i + 1))), big.NewInt(5)},
+		}
+	}
+	nonSigner := func(index uint32) *aggregation.NonSigner {
+		return &aggregation.NonSigner{
+			OperatorIndex: index,
+			Operator:      &aggregation.Operator{Address: table[index].Address, PublicKey: table[index].PublicKey},
+		}
+	}
+
+	t.Run("lists non-signers in ascending operator info tree order", func(t *testing.T) {
+		indices, witnesses, err := buildNonSignerWitnesses(&aggregation.AggregatedCertificate{
+			OperatorTable: table,
+			NonSigners:    []*aggregation.NonSigner{nonSigner(3), nonSigner(1)},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{1, 3}, indices)
+
+		infos := make([]ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo, 0, len(table))
+		for i := range table {
+			g1 := table[i].PublicKey.GetG1Point()
+			infos = append(infos, ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo{
+				Pubkey:  ITaskMailbox.BN254G1Point{X: g1.X.BigInt(new(big.Int)), Y: g1.Y.BigInt(new(big.Int))},
+				Weights: table[i].Weights,
+			})
+		}
+		tree, err := newOperatorInfoTree(infos)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 2)
+		for i, witness := range witnesses {
+			assert.Equal(t, indices[i], witness.OperatorIndex)
+			assert.Equal(t, infos[witness.OperatorIndex], witness.OperatorInfo)
+			leaf, err := operatorInfoLeaf(witness.OperatorInfo)
+			require.NoError(t, err)
+			assert.True(t, verifyInclusion(witness.OperatorInfoProofs, tree.root(), leaf, int(witness.OperatorIndex)))
+		}
+	})
+
+	t.Run("rejects non-signers listed twice or outside the table", func(t *testing.T) {
+		_, _, err := buildNonSignerWitnesses(&aggregation.AggregatedCertificate{
+			OperatorTable: table,
+			NonSigners:    []*aggregation.NonSigner{nonSigner(2), nonSigner(2)},
+		})
+		assert.NotNil(t, err)
+
+		outside := nonSigner(0)
+		outside.OperatorIndex = 4
+		_, _, err = buildNonSignerWitnesses(&aggregation.AggregatedCertificate{
+			OperatorTable: table,
+			NonSigners:    []*aggregation.NonSigner{outside},
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+package caller
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/contracts/pkg/bindings/ITaskMailbox"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// operatorInfoArguments ABI encodes a BN254OperatorInfo the way the certificate verifier hashes its leaves
+var operatorInfoArguments = func() abi.Arguments {
+	typ, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "pubkey", Type: "tuple", Components: []abi.ArgumentMarshaling{
+			{Name: "X", Type: "uint256"},
+			{Name: "Y", Type: "uint256"},
+		}},
+		{Name: "weights", Type: "uint96[]"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return abi.Arguments{{Name: "operatorInfo", Type: typ}}
+}()
+
+// operatorInfoLeaf returns keccak256(abi.encode(operatorInfo))
+func operatorInfoLeaf(info ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo) (common.Hash, error) {
+	encoded, err := operatorInfoArguments.Pack(info)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode operator info: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// operatorInfoTree is the keccak merkle tree of an operator table. Leaves are padded with zero hashes
+// to a power of two and each parent is keccak256(left ++ right), like Merkle.merkleizeKeccak on chain.
+type operatorInfoTree struct {
+	// layers[0] holds the leaves and the last layer the root
+	layers [][]common.Hash
+}
+
+func newOperatorInfoTree(infos []ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo) (*operatorInfoTree, error) {
+	width := 1
+	for width < len(infos) {
+		width *= 2
+	}
+	leaves := make([]common.Hash, width)
+	for i, info := range infos {
+		leaf, err := operatorInfoLeaf(info)
+		if err != nil {
+			return nil, err
+		}
+		leaves[i] = leaf
+	}
+
+	layers := [][]common.Hash{leaves}
+	for layer := leaves; len(layer) > 1; {
+		parents := make([]common.Hash, len(layer)/2)
+		for i := range parents {
+			parents[i] = crypto.Keccak256Hash(layer[2*i][:], layer[2*i+1][:])
+		}
+		layers = append(layers, parents)
+		layer = parents
+	}
+	return &operatorInfoTree{layers: layers}, nil
+}
+
+func (t *operatorInfoTree) root() common.Hash {
+	return t.layers[len(t.layers)-1][0]
+}
+
+// proof returns the concatenated sibling hashes from the leaf at index up to the root
+func (t *operatorInfoTree) proof(index int) []byte {
+	proof := make([]byte, 0, 32*(len(t.layers)-1))
+	for _, layer := range t.layers[:len(t.layers)-1] {
+		sibling := layer[index^1]
+		proof = append(proof, sibling[:]...)
+		index /= 2
+	}
+	return proof
+}
@@ -0,0 +1,73 @@
+package caller
+
+import (
+	"github.com/Layr-Labs/hourglass-monorepo/contracts/pkg/bindings/ITaskMailbox"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+// verifyInclusion mirrors Merkle.verifyInclusionKeccak
+func verifyInclusion(proof []byte, root common.Hash, leaf common.Hash, index int) bool {
+	computed := leaf
+	for i := 0; i < len(proof); i += 32 {
+		sibling := proof[i : i+32]
+		if index%2 == 0 {
+			computed = crypto.Keccak256Hash(computed[:], sibling)
+		} else {
+			computed = crypto.Keccak256Hash(sibling, computed[:])
+		}
+		index /= 2
+	}
+	return computed == root
+}
+
+func Test_OperatorInfoTree(t *testing.T) {
+	infos := make([]ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo, 5)
+	for i := range infos {
+		infos[i] = ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo{
+			Pubkey:  ITaskMailbox.BN254G1Point{X: big.NewInt(int64(i + 1)), Y: big.NewInt(int64(i + 2))},
+			Weights: []*big.Int{big.NewInt(int64(100 * (i + 1)))},
+		}
+	}
+
+	t.Run("proves every leaf against the root", func(t *testing.T) {
+		tree, err := newOperatorInfoTree(infos)
+		require.NoError(t, err)
+		for i, info := range infos {
+			leaf, err := operatorInfoLeaf(info)
+			require.NoError(t, err)
+			proof := tree.proof(i)
+			// five leaves are padded to eight, so there are three levels of siblings
+			assert.Len(t, proof, 3*32)
+			assert.True(t, verifyInclusion(proof, tree.root(), leaf, i), "leaf %d", i)
+			assert.False(t, verifyInclusion(proof, tree.root(), leaf, (i+1)%len(infos)), "leaf %d at the wrong index", i)
+		}
+	})
+
+	t.Run("a single operator is the root", func(t *testing.T) {
+		tree, err := newOperatorInfoTree(infos[:1])
+		require.NoError(t, err)
+		leaf, err := operatorInfoLeaf(infos[0])
+		require.NoError(t, err)
+		assert.Equal(t, leaf, tree.root())
+		assert.Empty(t, tree.proof(0))
+	})
+	t.Run("hashes one weight per stake type into the leaf", func(t *testing.T) {
+		pubkey := ITaskMailbox.BN254G1Point{X: big.NewInt(1), Y: big.NewInt(2)}
+		perStrategy, err := operatorInfoLeaf(ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo{
+			Pubkey:  pubkey,
+			Weights: []*big.Int{big.NewInt(60), big.NewInt(40)},
+		})
+		require.NoError(t, err)
+		summed, err := operatorInfoLeaf(ITaskMailbox.IBN254CertificateVerifierBN254OperatorInfo{
+			Pubkey:  pubkey,
+			Weights: []*big.Int{big.NewInt(100)},
+		})
+		require.NoError(t, err)
+		assert.NotEqual(t, perStrategy, summed)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionManager"
 	ethereum2 "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -15,7 +16,7 @@ import (
 )
 
 var (
-	FallbackGasTipCap = big.NewInt(15000000000)
+	FallbackGasTipCap = transactionManager.FallbackGasTipCap
 )
 
 func (cc *ContractCaller) EstimateGasPriceAndLimitAndSendTx(
@@ -25,6 +26,9 @@ func (cc *ContractCaller) EstimateGasPriceAndLimitAndSendTx(
 	privateKey *ecdsa.PrivateKey,
 	tag string,
 ) (*ethereumTypes.Receipt, error) {
+	if cc.config.TransactionManager != nil {
+		return cc.config.TransactionManager.Send(ctx, tx, tag)
+	}
 
 	gasTipCap, err := cc.ethclient.SuggestGasTipCap(ctx)
 	if err != nil {
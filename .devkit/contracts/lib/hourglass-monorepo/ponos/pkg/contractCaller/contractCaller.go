@@ -11,7 +11,6 @@ import (
 )
 
 type AVSConfig struct {
-	ResultSubmitter         string
 	AggregatorOperatorSetId uint32
 	ExecutorOperatorSetIds  []uint32
 }
@@ -42,7 +41,18 @@ type IContractCaller interface {
 
 	GetOperatorSetMembersWithPeering(avsAddress string, operatorSetId uint32) ([]*peering.OperatorPeerInfo, error)
 
+	// GetOperatorStakeWeights returns each operator's stake allocated to the operator set at blockNumber,
+	// in the same order as operators
+	GetOperatorStakeWeights(ctx context.Context, avsAddress string, operatorSetId uint32, operators []string, blockNumber uint64) ([]*big.Int, error)
+
+	// GetOperatorTable returns the operator set's members in operator table order at blockNumber, with
+	// their registered public keys and their stake weights, one per strategy of the operator set
+	GetOperatorTable(ctx context.Context, avsAddress string, operatorSetId uint32, blockNumber uint64) ([]*aggregation.OperatorTableEntry, error)
+
 	PublishMessageToInbox(ctx context.Context, avsAddress string, operatorSetId uint32, payload []byte) (*ethereumTypes.Receipt, error)
 
 	GetOperatorRegistrationMessageHash(ctx context.Context, address common.Address) (ITaskAVSRegistrar.BN254G1Point, error)
+
+	// GetBlockNumber returns the number of the latest block
+	GetBlockNumber(ctx context.Context) (uint64, error)
 }
@@ -1,9 +1,32 @@
 package contractStore
 
-import "github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contracts"
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contracts"
+)
 
 type IContractStore interface {
 	GetContractByAddress(address string) (*contracts.Contract, error)
+	GetContractByNameForChainId(name string, chainId config.ChainId) (*contracts.Contract, error)
 	ListContractAddresses() []string
 	ListContracts() []*contracts.Contract
 }
+
+// ResolveContractAddress returns the configured address if set, otherwise the address of the named
+// contract for the chain from the store
+func ResolveContractAddress(store IContractStore, configured string, name string, chainId config.ChainId) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	if store != nil {
+		contract, err := store.GetContractByNameForChainId(name, chainId)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up %s contract: %w", name, err)
+		}
+		if contract != nil {
+			return contract.Address, nil
+		}
+	}
+	return "", fmt.Errorf("no %s address configured and none found in the contract store for chain %d", name, chainId)
+}
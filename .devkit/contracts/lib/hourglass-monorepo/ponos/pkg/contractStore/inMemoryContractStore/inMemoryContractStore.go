@@ -2,6 +2,7 @@ package inMemoryContractStore
 
 import (
 	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contracts"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 	"go.uber.org/zap"
@@ -36,6 +37,21 @@ func (ics *InMemoryContractStore) GetContractByAddress(address string) (*contrac
 	return contract, nil
 }
 
+// GetContractByNameForChainId returns the contract with the given name deployed on the chain, or nil if there is none
+func (ics *InMemoryContractStore) GetContractByNameForChainId(name string, chainId config.ChainId) (*contracts.Contract, error) {
+	contract := util.Find(ics.contracts, func(c *contracts.Contract) bool {
+		return c.Name == name && c.ChainId == chainId
+	})
+	if contract == nil {
+		ics.logger.Sugar().Debugw("Contract not found",
+			zap.String("name", name),
+			zap.Uint("chainId", uint(chainId)),
+		)
+		return nil, nil
+	}
+	return contract, nil
+}
+
 func (ics *InMemoryContractStore) ListContractAddresses() []string {
 	return util.Map(ics.contracts, func(c *contracts.Contract, i uint64) string {
 		return strings.ToLower(c.Address)
@@ -79,4 +79,25 @@ func Test_CoreContracts(t *testing.T) {
 			assert.NotNil(t, a)
 		})
 	})
+	t.Run("anvil", func(t *testing.T) {
+		t.Run("Should load the task AVS registrar for anvil", func(t *testing.T) {
+			loadedContracts, err := LoadContracts()
+			if err != nil {
+				t.Fatalf("Failed to load core loadedContracts for anvil: %v", err)
+			}
+
+			filteredContracts := filterContractsForChainId(loadedContracts, config.ChainId_EthereumAnvil)
+			registrar := getContractByNameAndChainId(filteredContracts, config.ContractName_TaskAVSRegistrar, config.ChainId_EthereumAnvil)
+			if registrar == nil {
+				t.Fatalf("TaskAVSRegistrar not found for anvil")
+			}
+			assert.Equal(t, config.AVSRegistrarSimulationAddress, registrar.Address)
+
+			a, err := registrar.GetAbi()
+			if err != nil {
+				t.Fatalf("Failed to get ABI for task AVS registrar contract: %v", err)
+			}
+			assert.NotNil(t, a.Methods["getBatchOperatorPubkeyInfoAndSocket"])
+		})
+	})
 }
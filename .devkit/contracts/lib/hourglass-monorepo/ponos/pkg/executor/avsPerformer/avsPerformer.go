@@ -3,6 +3,7 @@ package avsPerformer
 import (
 	"context"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	"time"
 )
 
 type AvsProcessType string
@@ -10,28 +11,110 @@ type AvsProcessType string
 const (
 	AvsProcessTypeServer AvsProcessType = "server"
 	AvsProcessTypeOneOff AvsProcessType = "one-off"
+
+	// AvsProcessTypeProcess runs the performer binary as a child process of the executor
+	AvsProcessTypeProcess AvsProcessType = "process"
+
+	// AvsProcessTypeInProcess calls a worker registered with the executor directly
+	AvsProcessTypeInProcess AvsProcessType = "inprocess"
 )
 
+// PerformerState is the lifecycle state of the performer backing an AVS
+type PerformerState string
+
+const (
+	PerformerStateStarting   PerformerState = "starting"
+	PerformerStateReady      PerformerState = "ready"
+	PerformerStateRestarting PerformerState = "restarting"
+	PerformerStateStopped    PerformerState = "stopped"
+)
+
+// PerformerStatus is a snapshot of a supervised performer
+type PerformerStatus struct {
+	State        PerformerState
+	RestartCount int
+
+	// Since is when the performer entered State
+	Since time.Time
+
+	// LastError is why the last restart attempt failed; it is cleared once a restart succeeds
+	LastError string
+
+	// NextRestartAt is when the next restart attempt is made while the performer is backing off
+	NextRestartAt time.Time
+}
+
+// IPerformerStatusReporter is implemented by performers that are supervised and restarted
+type IPerformerStatusReporter interface {
+	Status() PerformerStatus
+}
+
 type PerformerImage struct {
 	Repository string
 	Tag        string
 }
 
+// PerformerProcess describes the local performer binary used by the process type
+type PerformerProcess struct {
+	Path string
+	Args []string
+
+	// Env holds additional KEY=VALUE environment variables for the process
+	Env []string
+}
+
+// PerformerMount is a host path mounted read-only into the performer container
+type PerformerMount struct {
+	HostPath      string
+	ContainerPath string
+}
+
+// PerformerContainerOptions holds the container settings used by the server and one-off process types
+type PerformerContainerOptions struct {
+	// NanoCPUs limits the CPU of the container in units of 1e-9 CPUs, 0 means unlimited
+	NanoCPUs int64
+
+	// MemoryBytes limits the memory of the container, 0 means unlimited
+	MemoryBytes int64
+
+	Mounts []PerformerMount
+
+	// User is the user (and optionally group) the container runs as, e.g. 1000:1000
+	User string
+
+	// Networks are existing Docker networks the container is connected to in addition to
+	// the performer network
+	Networks []string
+}
+
 type AvsPerformerConfig struct {
 	AvsAddress           string
 	ProcessType          AvsProcessType
 	Image                PerformerImage
+	Process              PerformerProcess
 	WorkerCount          int
+	TaskBacklogSize      int
 	PerformerNetworkName string
 	SigningCurve         string // bn254, bls381, etc
+
+	// Env holds KEY=VALUE environment variables passed to the performer container or process
+	Env       []string
+	Container PerformerContainerOptions
+
+	// TaskTimeout bounds a single task once it is picked up. The one-off process type counts
+	// starting the task's container and waiting for it to be ready, and defaults to 60s; the
+	// inprocess type bounds the worker call and is unbounded when zero. Other types ignore it.
+	TaskTimeout time.Duration
 }
 
 type IAvsPerformer interface {
 	Initialize(ctx context.Context) error
 	ProcessTasks(ctx context.Context) error
 	RunTask(ctx context.Context, task *performerTask.PerformerTask) error
-	ValidateTaskSignature(task *performerTask.PerformerTask) error
+	ValidateTaskSignature(ctx context.Context, task *performerTask.PerformerTask) error
 	Shutdown() error
 }
 
+// ReceiveTaskResponse is called once for every task a performer ran, with the task's error if it
+// failed or timed out
 type ReceiveTaskResponse func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error)
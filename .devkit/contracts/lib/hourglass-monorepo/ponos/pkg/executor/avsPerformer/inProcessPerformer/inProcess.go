@@ -0,0 +1,117 @@
+package inProcessPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/worker"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+)
+
+// AvsPerformerInProcess calls a worker linked into the executor binary directly, without a
+// container, child process or gRPC hop in between.
+type AvsPerformerInProcess struct {
+	config *avsPerformer.AvsPerformerConfig
+	logger *zap.Logger
+	worker worker.IContextWorker
+
+	signatureValidator *avsPerformer.TaskSignatureValidator
+	taskPool           *avsPerformer.TaskPool
+}
+
+func NewAvsPerformerInProcess(
+	config *avsPerformer.AvsPerformerConfig,
+	w worker.IContextWorker,
+	peeringFetcher peering.IPeeringDataFetcher,
+	reportTaskResponse avsPerformer.ReceiveTaskResponse,
+	logger *zap.Logger,
+) (*AvsPerformerInProcess, error) {
+	if w == nil {
+		return nil, fmt.Errorf("no in-process worker registered for AVS %s", config.AvsAddress)
+	}
+	signatureValidator, err := avsPerformer.NewTaskSignatureValidator(config.AvsAddress, config.SigningCurve, peeringFetcher, logger)
+	if err != nil {
+		return nil, err
+	}
+	aip := &AvsPerformerInProcess{
+		config:             config,
+		logger:             logger,
+		worker:             w,
+		signatureValidator: signatureValidator,
+	}
+	aip.taskPool = avsPerformer.NewTaskPool(
+		config.AvsAddress,
+		config.WorkerCount,
+		config.TaskBacklogSize,
+		aip.processTask,
+		reportTaskResponse,
+		logger,
+	)
+	return aip, nil
+}
+
+func (aip *AvsPerformerInProcess) Initialize(ctx context.Context) error {
+	return aip.signatureValidator.FetchAggregatorPeers(ctx)
+}
+
+func (aip *AvsPerformerInProcess) ProcessTasks(ctx context.Context) error {
+	aip.taskPool.Start(ctx)
+	return nil
+}
+
+func (aip *AvsPerformerInProcess) ValidateTaskSignature(ctx context.Context, t *performerTask.PerformerTask) error {
+	return aip.signatureValidator.ValidateTaskSignature(ctx, t)
+}
+
+func (aip *AvsPerformerInProcess) RunTask(ctx context.Context, task *performerTask.PerformerTask) error {
+	return aip.taskPool.Submit(task)
+}
+
+// InflightTasks returns the number of tasks currently being executed by the worker
+func (aip *AvsPerformerInProcess) InflightTasks() int64 {
+	return aip.taskPool.InflightTasks()
+}
+
+func (aip *AvsPerformerInProcess) processTask(ctx context.Context, task *performerTask.PerformerTask) (*performerTask.PerformerTaskResult, error) {
+	if aip.config.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, aip.config.TaskTimeout)
+		defer cancel()
+	}
+
+	req := &performerV1.TaskRequest{
+		TaskId:   []byte(task.TaskID),
+		Metadata: task.Metadata,
+		Payload:  task.Payload,
+	}
+	if err := aip.worker.ValidateTask(ctx, req); err != nil {
+		aip.logger.Sugar().Errorw("task is invalid",
+			zap.String("avsAddress", aip.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("task is invalid: %w", err)
+	}
+
+	res, err := aip.worker.HandleTask(ctx, req)
+	if err != nil {
+		aip.logger.Sugar().Errorw("Failed to handle task",
+			zap.String("avsAddress", aip.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return &performerTask.PerformerTaskResult{
+		TaskID: task.TaskID,
+		Result: res.Result,
+	}, nil
+}
+
+func (aip *AvsPerformerInProcess) Shutdown() error {
+	aip.taskPool.Drain()
+	return nil
+}
@@ -0,0 +1,66 @@
+package inProcessPerformer
+
+import (
+	"context"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type slowContextWorker struct {
+	delay time.Duration
+}
+
+func (w *slowContextWorker) ValidateTask(ctx context.Context, task *performerV1.TaskRequest) error {
+	return nil
+}
+
+func (w *slowContextWorker) HandleTask(ctx context.Context, task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	select {
+	case <-time.After(w.delay):
+		return &performerV1.TaskResponse{TaskId: task.TaskId, Result: task.Payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func Test_AvsPerformerInProcess(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	cfg := &avsPerformer.AvsPerformerConfig{
+		AvsAddress:   "0xavs1",
+		ProcessType:  avsPerformer.AvsProcessTypeInProcess,
+		WorkerCount:  1,
+		SigningCurve: "bn254",
+		TaskTimeout:  100 * time.Millisecond,
+	}
+
+	t.Run("fails without a registered worker", func(t *testing.T) {
+		_, err := NewAvsPerformerInProcess(cfg, nil, nil, nil, l)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("calls the worker directly and bounds it by the task timeout", func(t *testing.T) {
+		aip, err := NewAvsPerformerInProcess(cfg, &slowContextWorker{delay: 10 * time.Millisecond}, nil, nil, l)
+		if err != nil {
+			t.Fatalf("Failed to create in-process performer: %v", err)
+		}
+
+		res, err := aip.processTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x1", Payload: []byte("payload")})
+		assert.Nil(t, err)
+		assert.Equal(t, "0x1", res.TaskID)
+		assert.Equal(t, []byte("payload"), res.Result)
+
+		aip.worker = &slowContextWorker{delay: 5 * time.Second}
+		start := time.Now()
+		_, err = aip.processTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x2"})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
@@ -0,0 +1,293 @@
+package oneOffPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/avsPerformerClient"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer/performerContainer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/docker/docker/client"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultTaskTimeout bounds starting the container, running the task and collecting the result
+	defaultTaskTimeout = 60 * time.Second
+
+	// drainTimeout is how long Shutdown waits for queued and in-flight tasks to finish
+	drainTimeout = 30 * time.Second
+)
+
+// taskPerformer is a performer that exists for the lifetime of a single task
+type taskPerformer struct {
+	client   performerV1.PerformerServiceClient
+	teardown func() error
+}
+
+// startTaskPerformerFunc starts a fresh performer for the given task
+type startTaskPerformerFunc func(ctx context.Context, task *performerTask.PerformerTask) (*taskPerformer, error)
+
+// AvsPerformerOneOff runs every task in its own performer container which is torn down once the
+// result has been collected, so that no memory or state is shared between tasks.
+//
+// At most WorkerCount containers run at the same time; up to TaskBacklogSize additional tasks
+// wait for a free slot. Each task, including starting its container, is bounded by TaskTimeout.
+type AvsPerformerOneOff struct {
+	config       *avsPerformer.AvsPerformerConfig
+	logger       *zap.Logger
+	dockerClient *client.Client
+
+	signatureValidator *avsPerformer.TaskSignatureValidator
+	reportTaskResponse avsPerformer.ReceiveTaskResponse
+
+	startTaskPerformer startTaskPerformerFunc
+
+	// slots limits the number of task containers running concurrently
+	slots chan struct{}
+
+	mu           sync.Mutex
+	shuttingDown bool
+	tasksWg      sync.WaitGroup
+
+	// pendingTasks counts tasks that are queued or running
+	pendingTasks  atomic.Int64
+	inflightTasks atomic.Int64
+}
+
+func NewAvsPerformerOneOff(
+	config *avsPerformer.AvsPerformerConfig,
+	peeringFetcher peering.IPeeringDataFetcher,
+	reportTaskResponse avsPerformer.ReceiveTaskResponse,
+	logger *zap.Logger,
+) (*AvsPerformerOneOff, error) {
+	signatureValidator, err := avsPerformer.NewTaskSignatureValidator(config.AvsAddress, config.SigningCurve, peeringFetcher, logger)
+	if err != nil {
+		return nil, err
+	}
+	workerCount := config.WorkerCount
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	aoo := &AvsPerformerOneOff{
+		config:             config,
+		logger:             logger,
+		signatureValidator: signatureValidator,
+		reportTaskResponse: reportTaskResponse,
+		slots:              make(chan struct{}, workerCount),
+	}
+	aoo.startTaskPerformer = aoo.startTaskContainer
+	return aoo, nil
+}
+
+func (aoo *AvsPerformerOneOff) Initialize(ctx context.Context) error {
+	if err := aoo.signatureValidator.FetchAggregatorPeers(ctx); err != nil {
+		return err
+	}
+
+	dockerClient, err := performerContainer.NewDockerClient(ctx)
+	if err != nil {
+		aoo.logger.Sugar().Errorw("Failed to create Docker client for performer",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+			zap.Error(err),
+		)
+		return err
+	}
+	aoo.dockerClient = dockerClient
+
+	if aoo.config.PerformerNetworkName != "" {
+		if err := performerContainer.CreateNetworkIfNotExists(ctx, dockerClient, aoo.config.PerformerNetworkName, aoo.logger); err != nil {
+			aoo.logger.Sugar().Errorw("Failed to create Docker network for performer",
+				zap.String("avsAddress", aoo.config.AvsAddress),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessTasks is a no-op; a container is started for each task as it is submitted with RunTask
+func (aoo *AvsPerformerOneOff) ProcessTasks(ctx context.Context) error {
+	aoo.logger.Sugar().Infow("One-off performer ready for tasks",
+		zap.String("avsAddress", aoo.config.AvsAddress),
+		zap.Int("maxConcurrentTasks", cap(aoo.slots)),
+	)
+	return nil
+}
+
+func (aoo *AvsPerformerOneOff) ValidateTaskSignature(ctx context.Context, t *performerTask.PerformerTask) error {
+	return aoo.signatureValidator.ValidateTaskSignature(ctx, t)
+}
+
+func (aoo *AvsPerformerOneOff) RunTask(ctx context.Context, task *performerTask.PerformerTask) error {
+	aoo.mu.Lock()
+	defer aoo.mu.Unlock()
+	if aoo.shuttingDown {
+		return fmt.Errorf("performer for avs %s is shutting down", aoo.config.AvsAddress)
+	}
+
+	backlogSize := aoo.config.TaskBacklogSize
+	if backlogSize <= 0 {
+		backlogSize = avsPerformer.DefaultTaskBacklogSize
+	}
+	if aoo.pendingTasks.Load() >= int64(cap(aoo.slots)+backlogSize) {
+		aoo.logger.Sugar().Infow("PerformerTask backlog is full, dropping task",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+		)
+		return fmt.Errorf("task backlog is full for avs %s", aoo.config.AvsAddress)
+	}
+	aoo.pendingTasks.Add(1)
+	aoo.tasksWg.Add(1)
+
+	// the task must be allowed to finish even if the submitting request is done
+	go aoo.runTask(context.WithoutCancel(ctx), task)
+	return nil
+}
+
+func (aoo *AvsPerformerOneOff) runTask(ctx context.Context, task *performerTask.PerformerTask) {
+	defer aoo.tasksWg.Done()
+	defer aoo.pendingTasks.Add(-1)
+
+	aoo.slots <- struct{}{}
+	defer func() { <-aoo.slots }()
+
+	inflight := aoo.inflightTasks.Add(1)
+	defer aoo.inflightTasks.Add(-1)
+	aoo.logger.Sugar().Debugw("Starting one-off performer for task",
+		zap.String("avsAddress", aoo.config.AvsAddress),
+		zap.String("taskId", task.TaskID),
+		zap.Int64("inflightTasks", inflight),
+	)
+
+	res, err := aoo.processTask(ctx, task)
+	if err != nil {
+		aoo.logger.Sugar().Errorw("Failed to process task",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+	}
+	// failures are reported too, so that the executor stops treating the task as running
+	aoo.reportTaskResponse(task, res, err)
+}
+
+func (aoo *AvsPerformerOneOff) taskTimeout() time.Duration {
+	if aoo.config.TaskTimeout > 0 {
+		return aoo.config.TaskTimeout
+	}
+	return defaultTaskTimeout
+}
+
+func (aoo *AvsPerformerOneOff) processTask(ctx context.Context, task *performerTask.PerformerTask) (*performerTask.PerformerTaskResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, aoo.taskTimeout())
+	defer cancel()
+
+	tp, err := aoo.startTaskPerformer(ctx, task)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start performer for task %s: %w", task.TaskID, err)
+	}
+	defer func() {
+		if err := tp.teardown(); err != nil {
+			aoo.logger.Sugar().Errorw("Failed to tear down performer for task",
+				zap.String("avsAddress", aoo.config.AvsAddress),
+				zap.String("taskId", task.TaskID),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	if err := avsPerformerClient.WaitForReady(ctx, tp.client); err != nil {
+		return nil, fmt.Errorf("performer for task %s never became ready: %w", task.TaskID, err)
+	}
+
+	res, err := tp.client.ExecuteTask(ctx, &performerV1.TaskRequest{
+		TaskId:   []byte(task.TaskID),
+		Metadata: task.Metadata,
+		Payload:  task.Payload,
+	})
+	if err != nil {
+		aoo.logger.Sugar().Errorw("Performer failed to handle task",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return performerTask.NewTaskResultFromResultProto(res), nil
+}
+
+func (aoo *AvsPerformerOneOff) startTaskContainer(ctx context.Context, task *performerTask.PerformerTask) (*taskPerformer, error) {
+	hostname := taskContainerHostname(aoo.config.AvsAddress, task.TaskID)
+	pc, err := performerContainer.StartContainer(ctx, aoo.dockerClient, performerContainer.NewContainerConfig(aoo.config, hostname), aoo.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	perfClient, err := avsPerformerClient.NewAvsPerformerClient(pc.Endpoint, true)
+	if err != nil {
+		aoo.logger.Sugar().Errorw("Failed to create performer client",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+		if removeErr := pc.Remove(); removeErr != nil {
+			return nil, fmt.Errorf("%w (failed to remove Docker container: %v)", err, removeErr)
+		}
+		return nil, err
+	}
+	return &taskPerformer{
+		client:   perfClient,
+		teardown: pc.Remove,
+	}, nil
+}
+
+// taskContainerHostname names the container for a single task run. The random suffix keeps
+// concurrent runs, including re-sent copies of the same task, from colliding on the performer network.
+func taskContainerHostname(avsAddress string, taskId string) string {
+	return fmt.Sprintf("avs-performer-%s-%s-%s",
+		performerContainer.HashAvsAddress(avsAddress),
+		performerContainer.HashAvsAddress(taskId),
+		strings.ReplaceAll(uuid.New().String(), "-", ""),
+	)
+}
+
+// InflightTasks returns the number of task containers currently running
+func (aoo *AvsPerformerOneOff) InflightTasks() int64 {
+	return aoo.inflightTasks.Load()
+}
+
+// Shutdown stops accepting tasks and waits for queued and running tasks to finish, giving up
+// after drainTimeout. Each task tears down its own container.
+func (aoo *AvsPerformerOneOff) Shutdown() error {
+	aoo.mu.Lock()
+	aoo.shuttingDown = true
+	aoo.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		aoo.tasksWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		aoo.logger.Sugar().Infow("One-off performer tasks drained",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+		)
+	case <-time.After(drainTimeout):
+		aoo.logger.Sugar().Warnw("Timed out waiting for one-off performer tasks to drain",
+			zap.String("avsAddress", aoo.config.AvsAddress),
+			zap.Int64("pendingTasks", aoo.pendingTasks.Load()),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package oneOffPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeTaskPerformerClient struct {
+	delay time.Duration
+}
+
+func (c *fakeTaskPerformerClient) HealthCheck(ctx context.Context, in *performerV1.HealthCheckRequest, opts ...grpc.CallOption) (*performerV1.HealthCheckResponse, error) {
+	return &performerV1.HealthCheckResponse{Status: performerV1.PerformerStatus_READY_FOR_TASK}, nil
+}
+
+func (c *fakeTaskPerformerClient) StartSync(ctx context.Context, in *performerV1.StartSyncRequest, opts ...grpc.CallOption) (*performerV1.StartSyncResponse, error) {
+	return &performerV1.StartSyncResponse{}, nil
+}
+
+func (c *fakeTaskPerformerClient) ExecuteTask(ctx context.Context, in *performerV1.TaskRequest, opts ...grpc.CallOption) (*performerV1.TaskResponse, error) {
+	select {
+	case <-time.After(c.delay):
+		return &performerV1.TaskResponse{TaskId: in.TaskId, Result: in.Payload}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func Test_AvsPerformerOneOff(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	// newPerformer wires a fake container lifecycle into the performer that tracks how many
+	// task performers are alive at once and how many were torn down.
+	newPerformer := func(
+		cfg *avsPerformer.AvsPerformerConfig,
+		delay time.Duration,
+		onResult avsPerformer.ReceiveTaskResponse,
+	) (*AvsPerformerOneOff, *atomic.Int64, *atomic.Int64, *atomic.Int64) {
+		cfg.AvsAddress = "0xavs1"
+		cfg.ProcessType = avsPerformer.AvsProcessTypeOneOff
+		cfg.SigningCurve = "bn254"
+		aoo, err := NewAvsPerformerOneOff(cfg, nil, onResult, l)
+		if err != nil {
+			t.Fatalf("Failed to create one-off performer: %v", err)
+		}
+
+		var alive, maxAlive, tornDown atomic.Int64
+		aoo.startTaskPerformer = func(ctx context.Context, task *performerTask.PerformerTask) (*taskPerformer, error) {
+			current := alive.Add(1)
+			for {
+				observed := maxAlive.Load()
+				if current <= observed || maxAlive.CompareAndSwap(observed, current) {
+					break
+				}
+			}
+			return &taskPerformer{
+				client: &fakeTaskPerformerClient{delay: delay},
+				teardown: func() error {
+					alive.Add(-1)
+					tornDown.Add(1)
+					return nil
+				},
+			}, nil
+		}
+		return aoo, &alive, &maxAlive, &tornDown
+	}
+
+	t.Run("runs each task in its own performer up to the concurrency cap", func(t *testing.T) {
+		var wg sync.WaitGroup
+		var completed atomic.Int64
+		aoo, alive, maxAlive, tornDown := newPerformer(&avsPerformer.AvsPerformerConfig{WorkerCount: 2}, 50*time.Millisecond,
+			func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+				assert.Nil(t, err)
+				assert.Equal(t, originalTask.Payload, response.Result)
+				completed.Add(1)
+				wg.Done()
+			},
+		)
+
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			assert.Nil(t, aoo.RunTask(context.Background(), &performerTask.PerformerTask{
+				TaskID:  fmt.Sprintf("0x%d", i),
+				Payload: []byte(fmt.Sprintf("payload-%d", i)),
+			}))
+		}
+		wg.Wait()
+		assert.Nil(t, aoo.Shutdown())
+
+		assert.Equal(t, int64(6), completed.Load())
+		assert.Equal(t, int64(6), tornDown.Load())
+		assert.Equal(t, int64(2), maxAlive.Load())
+		assert.Equal(t, int64(0), alive.Load())
+	})
+
+	t.Run("tears down the performer when a task exceeds its timeout", func(t *testing.T) {
+		var completed, failed atomic.Int64
+		aoo, alive, _, tornDown := newPerformer(&avsPerformer.AvsPerformerConfig{
+			WorkerCount: 1,
+			TaskTimeout: 50 * time.Millisecond,
+		}, 5*time.Second, func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+			if err != nil {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				failed.Add(1)
+				return
+			}
+			completed.Add(1)
+		})
+
+		start := time.Now()
+		assert.Nil(t, aoo.RunTask(context.Background(), &performerTask.PerformerTask{TaskID: "0xslow"}))
+		assert.Nil(t, aoo.Shutdown())
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, int64(0), completed.Load())
+		assert.Equal(t, int64(1), failed.Load())
+		assert.Equal(t, int64(1), tornDown.Load())
+		assert.Equal(t, int64(0), alive.Load())
+	})
+
+	t.Run("rejects tasks once the backlog is full or after shutdown", func(t *testing.T) {
+		aoo, _, _, _ := newPerformer(&avsPerformer.AvsPerformerConfig{
+			WorkerCount:     1,
+			TaskBacklogSize: 1,
+			TaskTimeout:     time.Second,
+		}, 100*time.Millisecond, func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+		})
+
+		assert.Nil(t, aoo.RunTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x1"}))
+		assert.Nil(t, aoo.RunTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x2"}))
+		assert.NotNil(t, aoo.RunTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x3"}))
+
+		assert.Nil(t, aoo.Shutdown())
+		assert.NotNil(t, aoo.RunTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x4"}))
+	})
+	t.Run("gives every run of a task its own container hostname", func(t *testing.T) {
+		first := taskContainerHostname("0xavs", "0x1")
+		second := taskContainerHostname("0xavs", "0x1")
+
+		assert.NotEqual(t, first, second)
+		assert.LessOrEqual(t, len(first), 63)
+	})
+}
@@ -0,0 +1,362 @@
+package performerContainer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ContainerPort is the port the performer gRPC server listens on inside the container
+const ContainerPort = 8080
+
+type ContainerConfig struct {
+	AvsAddress  string
+	Hostname    string
+	Image       string
+	NetworkName string
+
+	// Env holds KEY=VALUE environment variables for the container
+	Env     []string
+	Options avsPerformer.PerformerContainerOptions
+}
+
+// NewContainerConfig builds the container config for the performer of an AVS
+func NewContainerConfig(config *avsPerformer.AvsPerformerConfig, hostname string) *ContainerConfig {
+	return &ContainerConfig{
+		AvsAddress:  config.AvsAddress,
+		Hostname:    hostname,
+		Image:       fmt.Sprintf("%s:%s", config.Image.Repository, config.Image.Tag),
+		NetworkName: config.PerformerNetworkName,
+		Env:         config.Env,
+		Options:     config.Container,
+	}
+}
+
+// PerformerContainer is a running performer container and the endpoint its gRPC server is reachable at
+type PerformerContainer struct {
+	ID       string
+	Hostname string
+	Endpoint string
+
+	dockerClient *client.Client
+	logger       *zap.Logger
+}
+
+// HashAvsAddress takes a sha256 hash of the avs address and returns the first 6 chars
+func HashAvsAddress(avsAddress string) string {
+	hasher := sha256.New()
+
+	hasher.Write([]byte(avsAddress))
+	hashBytes := hasher.Sum(nil)
+
+	return hex.EncodeToString(hashBytes)[0:6]
+}
+
+func NewDockerClient(ctx context.Context) (*client.Client, error) {
+	dockerClient, err := client.NewClientWithOpts(client.FromEnv)
+	if err != nil {
+		return nil, err
+	}
+	dockerClient.NegotiateAPIVersion(ctx)
+	return dockerClient, nil
+}
+
+// StartContainer creates and starts a performer container, waits for it to be running and resolves
+// the endpoint of its gRPC server. If anything fails after the container was created, it is removed.
+func StartContainer(
+	ctx context.Context,
+	dockerClient *client.Client,
+	cfg *ContainerConfig,
+	logger *zap.Logger,
+) (*PerformerContainer, error) {
+	containerPortProto := nat.Port(fmt.Sprintf("%d/tcp", ContainerPort))
+
+	if cfg.NetworkName != "" {
+		if err := CreateNetworkIfNotExists(ctx, dockerClient, cfg.NetworkName, logger); err != nil {
+			logger.Sugar().Errorw("Failed to create Docker network for performer",
+				zap.String("avsAddress", cfg.AvsAddress),
+				zap.Error(err),
+			)
+			return nil, err
+		}
+	}
+
+	containerConfg, hostConfig, netConfig := buildContainerConfigs(cfg, containerPortProto)
+
+	res, err := dockerClient.ContainerCreate(
+		ctx,
+		containerConfg,
+		hostConfig,
+		netConfig,
+		nil,
+		cfg.Hostname,
+	)
+	if err != nil {
+		logger.Sugar().Errorw("Failed to create Docker container for performer",
+			zap.String("avsAddress", cfg.AvsAddress),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	pc := &PerformerContainer{
+		ID:           res.ID,
+		Hostname:     cfg.Hostname,
+		dockerClient: dockerClient,
+		logger:       logger,
+	}
+
+	for _, networkName := range cfg.Options.Networks {
+		if err := dockerClient.NetworkConnect(ctx, networkName, res.ID, nil); err != nil {
+			logger.Sugar().Errorw("Failed to connect Docker container for performer to network",
+				zap.String("avsAddress", cfg.AvsAddress),
+				zap.String("network", networkName),
+				zap.Error(err),
+			)
+			return nil, pc.removeAfterError(fmt.Errorf("failed to connect container to network %s: %w", networkName, err))
+		}
+	}
+
+	if err := dockerClient.ContainerStart(ctx, res.ID, container.StartOptions{}); err != nil {
+		logger.Sugar().Errorw("Failed to start Docker container for performer",
+			zap.String("avsAddress", cfg.AvsAddress),
+			zap.Error(err),
+		)
+		return nil, pc.removeAfterError(err)
+	}
+	logger.Sugar().Infow("Started Docker container for performer",
+		zap.String("avsAddress", cfg.AvsAddress),
+		zap.String("containerID", res.ID),
+	)
+
+	running, err := waitForRunning(ctx, dockerClient, res.ID, containerPortProto, logger)
+	if err != nil || !running {
+		logger.Sugar().Errorw("Failed to wait for Docker container to be running",
+			zap.String("avsAddress", cfg.AvsAddress),
+			zap.Error(err),
+		)
+		if err == nil {
+			err = fmt.Errorf("container %s is not running", res.ID)
+		}
+		return nil, pc.removeAfterError(err)
+	}
+
+	containerInfo, err := dockerClient.ContainerInspect(ctx, res.ID)
+	if err != nil {
+		logger.Sugar().Errorw("Failed to inspect Docker container for performer",
+			zap.String("avsAddress", cfg.AvsAddress),
+			zap.Error(err),
+		)
+		return nil, pc.removeAfterError(err)
+	}
+	var exposedPort string
+	if portMap, ok := containerInfo.NetworkSettings.Ports[containerPortProto]; !ok {
+		logger.Sugar().Errorw("Failed to get exposed port from Docker container",
+			zap.String("avsAddress", cfg.AvsAddress),
+		)
+		return nil, pc.removeAfterError(fmt.Errorf("container %s does not expose port %s", res.ID, containerPortProto))
+	} else if len(portMap) == 0 {
+		logger.Sugar().Errorw("No exposed ports found in Docker container",
+			zap.String("avsAddress", cfg.AvsAddress),
+		)
+	} else {
+		exposedPort = portMap[0].HostPort
+	}
+
+	containerHost := "localhost"
+	if cfg.NetworkName != "" {
+		containerHost = cfg.Hostname
+		exposedPort = fmt.Sprintf("%d", ContainerPort)
+		logger.Sugar().Infow("Custom network provided, using container hostname and container port",
+			zap.String("avsAddress", cfg.AvsAddress),
+			zap.String("containerHost", containerHost),
+			zap.String("exposedPort", exposedPort),
+			zap.String("containerID", res.ID),
+		)
+	}
+	pc.Endpoint = fmt.Sprintf("%s:%s", containerHost, exposedPort)
+
+	return pc, nil
+}
+
+// buildContainerConfigs translates the performer container config into the Docker create options
+func buildContainerConfigs(cfg *ContainerConfig, containerPortProto nat.Port) (*container.Config, *container.HostConfig, *network.NetworkingConfig) {
+	containerConfg := &container.Config{
+		Hostname: cfg.Hostname,
+		Image:    cfg.Image,
+		Env:      cfg.Env,
+		User:     cfg.Options.User,
+		ExposedPorts: nat.PortSet{
+			containerPortProto: struct{}{},
+		},
+	}
+
+	hostConfig := &container.HostConfig{
+		AutoRemove: true,
+		PortBindings: nat.PortMap{
+			containerPortProto: []nat.PortBinding{
+				{
+					HostIP: "0.0.0.0",
+
+					// leave this blank to let Docker handle creating a random port
+					HostPort: "",
+				},
+			},
+		},
+		Resources: container.Resources{
+			NanoCPUs: cfg.Options.NanoCPUs,
+			Memory:   cfg.Options.MemoryBytes,
+		},
+	}
+	for _, m := range cfg.Options.Mounts {
+		hostConfig.Mounts = append(hostConfig.Mounts, mount.Mount{
+			Type:     mount.TypeBind,
+			Source:   m.HostPath,
+			Target:   m.ContainerPath,
+			ReadOnly: true,
+		})
+	}
+
+	var netConfig *network.NetworkingConfig
+	if cfg.NetworkName != "" {
+		netConfig = &network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				cfg.NetworkName: {},
+			},
+		}
+	}
+	return containerConfg, hostConfig, netConfig
+}
+
+func (pc *PerformerContainer) removeAfterError(err error) error {
+	if removeErr := pc.Remove(); removeErr != nil {
+		return fmt.Errorf("%w (failed to remove Docker container: %v)", err, removeErr)
+	}
+	return err
+}
+
+// IsRunning reports whether the container still exists and is running. Containers are created with
+// AutoRemove, so a container that died is usually gone entirely.
+func (pc *PerformerContainer) IsRunning(ctx context.Context) (bool, error) {
+	info, err := pc.dockerClient.ContainerInspect(ctx, pc.ID)
+	if err != nil {
+		if client.IsErrNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return info.State != nil && info.State.Running, nil
+}
+
+// Remove stops and removes the container
+func (pc *PerformerContainer) Remove() error {
+	pc.logger.Sugar().Infow("Stopping Docker container for performer",
+		zap.String("containerID", pc.ID),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := pc.dockerClient.ContainerStop(ctx, pc.ID, container.StopOptions{}); err != nil {
+		pc.logger.Sugar().Errorw("Failed to stop Docker container for performer",
+			zap.String("containerID", pc.ID),
+			zap.Error(err),
+		)
+	} else {
+		pc.logger.Sugar().Infow("Stopped Docker container for performer",
+			zap.String("containerID", pc.ID),
+		)
+	}
+	pc.logger.Sugar().Infow("Removing Docker container for performer",
+		zap.String("containerID", pc.ID),
+	)
+	if err := pc.dockerClient.ContainerRemove(context.Background(), pc.ID, container.RemoveOptions{
+		Force: true,
+	}); err != nil {
+		pc.logger.Sugar().Errorw("Failed to remove Docker container for performer",
+			zap.String("containerID", pc.ID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+func waitForRunning(
+	ctx context.Context,
+	dockerClient *client.Client,
+	containerId string,
+	containerPort nat.Port,
+	logger *zap.Logger,
+) (bool, error) {
+	for attempts := 0; attempts < 10; attempts++ {
+		info, err := dockerClient.ContainerInspect(ctx, containerId)
+		if err != nil {
+			return false, err
+		}
+
+		if info.State.Running {
+			portMap, ok := info.NetworkSettings.Ports[containerPort]
+			if ok && len(portMap) > 0 {
+				logger.Sugar().Infow("Container is running with port exposed",
+					zap.String("containerId", containerId),
+					zap.String("exposedPort", portMap[0].HostPort),
+				)
+				return true, nil
+			}
+			logger.Sugar().Infow("Port map not yet available", zap.String("containerId", containerId))
+		}
+
+		// Not ready yet, sleep and retry
+		select {
+		case <-time.After(1 * time.Second * time.Duration(attempts+1)):
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+	return false, fmt.Errorf("container %s is not running after 10 attempts", containerId)
+}
+
+func CreateNetworkIfNotExists(ctx context.Context, dockerClient *client.Client, networkName string, logger *zap.Logger) error {
+	networks, err := dockerClient.NetworkList(ctx, network.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	var n *network.Summary
+	for _, net := range networks {
+		if net.Name == networkName {
+			n = &net
+			break
+		}
+	}
+
+	// net already exists
+	if n != nil {
+		return nil
+	}
+
+	_, err = dockerClient.NetworkCreate(
+		ctx,
+		networkName,
+		network.CreateOptions{
+			Driver: "bridge",
+			Options: map[string]string{
+				"com.docker.net.bridge.enable_icc": "true",
+			},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create net: %w", err)
+	}
+	logger.Sugar().Infow("Created net",
+		zap.String("networkName", networkName),
+	)
+	return nil
+}
@@ -0,0 +1,65 @@
+package performerContainer
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_BuildContainerConfigs(t *testing.T) {
+	containerPortProto := nat.Port(fmt.Sprintf("%d/tcp", ContainerPort))
+
+	t.Run("applies env, user, resource limits and read-only mounts", func(t *testing.T) {
+		cfg := NewContainerConfig(&avsPerformer.AvsPerformerConfig{
+			AvsAddress:           "0xavs1",
+			Image:                avsPerformer.PerformerImage{Repository: "eigenlabs/avs", Tag: "v1.0.0"},
+			PerformerNetworkName: "performers",
+			Env:                  []string{"API_KEY=s3cr3t"},
+			Container: avsPerformer.PerformerContainerOptions{
+				NanoCPUs:    1_500_000_000,
+				MemoryBytes: 512 * 1024 * 1024,
+				Mounts:      []avsPerformer.PerformerMount{{HostPath: "/var/lib/avs", ContainerPath: "/data"}},
+				User:        "1000:1000",
+				Networks:    []string{"avs-db"},
+			},
+		}, "avs-performer-abc123")
+
+		containerConfig, hostConfig, netConfig := buildContainerConfigs(cfg, containerPortProto)
+		assert.Equal(t, "eigenlabs/avs:v1.0.0", containerConfig.Image)
+		assert.Equal(t, "avs-performer-abc123", containerConfig.Hostname)
+		assert.Equal(t, []string{"API_KEY=s3cr3t"}, containerConfig.Env)
+		assert.Equal(t, "1000:1000", containerConfig.User)
+
+		assert.True(t, hostConfig.AutoRemove)
+		assert.Equal(t, int64(1_500_000_000), hostConfig.Resources.NanoCPUs)
+		assert.Equal(t, int64(512*1024*1024), hostConfig.Resources.Memory)
+		assert.Equal(t, []mount.Mount{{
+			Type:     mount.TypeBind,
+			Source:   "/var/lib/avs",
+			Target:   "/data",
+			ReadOnly: true,
+		}}, hostConfig.Mounts)
+
+		// extra networks are connected after the container is created
+		assert.Len(t, netConfig.EndpointsConfig, 1)
+		assert.Contains(t, netConfig.EndpointsConfig, "performers")
+	})
+
+	t.Run("leaves the container unrestricted by default", func(t *testing.T) {
+		cfg := NewContainerConfig(&avsPerformer.AvsPerformerConfig{
+			AvsAddress: "0xavs1",
+			Image:      avsPerformer.PerformerImage{Repository: "eigenlabs/avs", Tag: "v1.0.0"},
+		}, "avs-performer-abc123")
+
+		containerConfig, hostConfig, netConfig := buildContainerConfigs(cfg, containerPortProto)
+		assert.Empty(t, containerConfig.Env)
+		assert.Empty(t, containerConfig.User)
+		assert.Equal(t, int64(0), hostConfig.Resources.NanoCPUs)
+		assert.Equal(t, int64(0), hostConfig.Resources.Memory)
+		assert.Empty(t, hostConfig.Mounts)
+		assert.Nil(t, netConfig)
+	})
+}
@@ -0,0 +1,336 @@
+package processPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/avsPerformerClient"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/server"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"net"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// startupTimeout is how long a freshly started performer has to report that it is ready
+	startupTimeout = 30 * time.Second
+
+	// stopTimeout is how long the performer has to exit after SIGTERM before it is killed
+	stopTimeout = 5 * time.Second
+
+	initialRestartBackoff = 1 * time.Second
+	maxRestartBackoff     = 30 * time.Second
+)
+
+// performerProcess is a single run of the performer binary
+type performerProcess struct {
+	cmd      *exec.Cmd
+	port     int
+	conn     *grpc.ClientConn
+	client   performerV1.PerformerServiceClient
+	exited   chan struct{}
+	exitErr  error
+	stopping bool
+}
+
+// AvsPerformerProcess runs the AVS performer as a supervised child process of the executor
+// instead of a Docker container. The process is given a free port through the PERFORMER_PORT
+// environment variable and is restarted with an exponential backoff whenever it exits unexpectedly.
+type AvsPerformerProcess struct {
+	config *avsPerformer.AvsPerformerConfig
+	logger *zap.Logger
+
+	signatureValidator *avsPerformer.TaskSignatureValidator
+	reportTaskResponse avsPerformer.ReceiveTaskResponse
+	taskPool           *avsPerformer.TaskPool
+
+	// mu guards process, which is replaced every time the performer is restarted
+	mu       sync.RWMutex
+	process  *performerProcess
+	shutdown bool
+}
+
+func NewAvsPerformerProcess(
+	config *avsPerformer.AvsPerformerConfig,
+	peeringFetcher peering.IPeeringDataFetcher,
+	reportTaskResponse avsPerformer.ReceiveTaskResponse,
+	logger *zap.Logger,
+) (*AvsPerformerProcess, error) {
+	if config.Process.Path == "" {
+		return nil, fmt.Errorf("no performer binary configured for AVS %s", config.AvsAddress)
+	}
+	signatureValidator, err := avsPerformer.NewTaskSignatureValidator(config.AvsAddress, config.SigningCurve, peeringFetcher, logger)
+	if err != nil {
+		return nil, err
+	}
+	app := &AvsPerformerProcess{
+		config:             config,
+		logger:             logger,
+		signatureValidator: signatureValidator,
+		reportTaskResponse: reportTaskResponse,
+	}
+	app.taskPool = avsPerformer.NewTaskPool(
+		config.AvsAddress,
+		config.WorkerCount,
+		config.TaskBacklogSize,
+		app.processTask,
+		reportTaskResponse,
+		logger,
+	)
+	return app, nil
+}
+
+func (app *AvsPerformerProcess) Initialize(ctx context.Context) error {
+	if err := app.signatureValidator.FetchAggregatorPeers(ctx); err != nil {
+		return err
+	}
+
+	if err := app.startProcess(ctx); err != nil {
+		return err
+	}
+
+	go app.supervise(ctx)
+	return nil
+}
+
+func (app *AvsPerformerProcess) ProcessTasks(ctx context.Context) error {
+	app.taskPool.Start(ctx)
+	return nil
+}
+
+func (app *AvsPerformerProcess) ValidateTaskSignature(ctx context.Context, t *performerTask.PerformerTask) error {
+	return app.signatureValidator.ValidateTaskSignature(ctx, t)
+}
+
+func (app *AvsPerformerProcess) RunTask(ctx context.Context, task *performerTask.PerformerTask) error {
+	return app.taskPool.Submit(task)
+}
+
+// InflightTasks returns the number of tasks currently being executed by the performer
+func (app *AvsPerformerProcess) InflightTasks() int64 {
+	return app.taskPool.InflightTasks()
+}
+
+func (app *AvsPerformerProcess) processTask(ctx context.Context, task *performerTask.PerformerTask) (*performerTask.PerformerTaskResult, error) {
+	app.mu.RLock()
+	proc := app.process
+	app.mu.RUnlock()
+	if proc == nil {
+		return nil, fmt.Errorf("performer process for avs %s is not running", app.config.AvsAddress)
+	}
+
+	res, err := proc.client.ExecuteTask(ctx, &performerV1.TaskRequest{
+		TaskId:   []byte(task.TaskID),
+		Metadata: task.Metadata,
+		Payload:  task.Payload,
+	})
+	if err != nil {
+		app.logger.Sugar().Errorw("Performer failed to handle task",
+			zap.String("avsAddress", app.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return performerTask.NewTaskResultFromResultProto(res), nil
+}
+
+// startProcess launches the performer binary on a free port and waits for it to become ready
+func (app *AvsPerformerProcess) startProcess(ctx context.Context) error {
+	port, err := getFreePort()
+	if err != nil {
+		return fmt.Errorf("failed to find a free port for the performer: %w", err)
+	}
+
+	cmd := exec.Command(app.config.Process.Path, app.config.Process.Args...)
+	cmd.Env = append(os.Environ(), app.config.Process.Env...)
+	cmd.Env = append(cmd.Env, app.config.Env...)
+	cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%d", server.PortEnvVar, port))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Start(); err != nil {
+		app.logger.Sugar().Errorw("Failed to start performer process",
+			zap.String("avsAddress", app.config.AvsAddress),
+			zap.String("path", app.config.Process.Path),
+			zap.Error(err),
+		)
+		return err
+	}
+	proc := &performerProcess{
+		cmd:    cmd,
+		port:   port,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		proc.exitErr = cmd.Wait()
+		close(proc.exited)
+	}()
+	app.logger.Sugar().Infow("Started performer process",
+		zap.String("avsAddress", app.config.AvsAddress),
+		zap.String("path", app.config.Process.Path),
+		zap.Int("pid", cmd.Process.Pid),
+		zap.Int("port", port),
+	)
+
+	conn, err := clients.NewGrpcClient(fmt.Sprintf("localhost:%d", port), true)
+	if err != nil {
+		app.stopProcess(proc)
+		return fmt.Errorf("failed to create performer client: %w", err)
+	}
+	proc.conn = conn
+	proc.client = performerV1.NewPerformerServiceClient(conn)
+
+	readyCtx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+	readyErr := make(chan error, 1)
+	go func() {
+		readyErr <- avsPerformerClient.WaitForReady(readyCtx, proc.client)
+	}()
+	select {
+	case err := <-readyErr:
+		if err != nil {
+			app.logger.Sugar().Errorw("Performer process never became ready",
+				zap.String("avsAddress", app.config.AvsAddress),
+				zap.Error(err),
+			)
+			app.stopProcess(proc)
+			return fmt.Errorf("performer process never became ready: %w", err)
+		}
+	case <-proc.exited:
+		cancel()
+		app.stopProcess(proc)
+		return fmt.Errorf("performer process exited during startup: %v", proc.exitErr)
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.shutdown {
+		app.stopProcessLocked(proc)
+		return fmt.Errorf("performer for avs %s is shutting down", app.config.AvsAddress)
+	}
+	app.process = proc
+	return nil
+}
+
+// supervise restarts the performer process whenever it exits, until the context is done or
+// the performer is shut down.
+func (app *AvsPerformerProcess) supervise(ctx context.Context) {
+	backoff := initialRestartBackoff
+	for {
+		app.mu.RLock()
+		proc := app.process
+		app.mu.RUnlock()
+		if proc == nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-proc.exited:
+		}
+
+		app.mu.Lock()
+		if app.shutdown || proc.stopping {
+			app.mu.Unlock()
+			return
+		}
+		app.process = nil
+		app.mu.Unlock()
+		_ = proc.conn.Close()
+
+		app.logger.Sugar().Errorw("Performer process exited unexpectedly, restarting",
+			zap.String("avsAddress", app.config.AvsAddress),
+			zap.Int("pid", proc.cmd.Process.Pid),
+			zap.NamedError("exitError", proc.exitErr),
+		)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			err := app.startProcess(ctx)
+			if err == nil {
+				backoff = initialRestartBackoff
+				break
+			}
+			app.mu.RLock()
+			shutdown := app.shutdown
+			app.mu.RUnlock()
+			if shutdown {
+				return
+			}
+			backoff = min(backoff*2, maxRestartBackoff)
+			app.logger.Sugar().Errorw("Failed to restart performer process",
+				zap.String("avsAddress", app.config.AvsAddress),
+				zap.Duration("nextAttemptIn", backoff),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (app *AvsPerformerProcess) stopProcess(proc *performerProcess) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.stopProcessLocked(proc)
+}
+
+// stopProcessLocked asks the process to terminate and kills it if it does not exit in time.
+// The caller must hold app.mu.
+func (app *AvsPerformerProcess) stopProcessLocked(proc *performerProcess) {
+	proc.stopping = true
+	if proc.conn != nil {
+		_ = proc.conn.Close()
+	}
+	_ = proc.cmd.Process.Signal(syscall.SIGTERM)
+	select {
+	case <-proc.exited:
+	case <-time.After(stopTimeout):
+		app.logger.Sugar().Warnw("Performer process did not exit after SIGTERM, killing it",
+			zap.String("avsAddress", app.config.AvsAddress),
+			zap.Int("pid", proc.cmd.Process.Pid),
+		)
+		_ = proc.cmd.Process.Kill()
+		<-proc.exited
+	}
+}
+
+func (app *AvsPerformerProcess) Shutdown() error {
+	app.taskPool.Drain()
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.shutdown = true
+	if app.process == nil {
+		return nil
+	}
+	app.logger.Sugar().Infow("Stopping performer process",
+		zap.String("avsAddress", app.config.AvsAddress),
+		zap.Int("pid", app.process.cmd.Process.Pid),
+	)
+	app.stopProcessLocked(app.process)
+	app.process = nil
+	return nil
+}
+
+func getFreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,141 @@
+package processPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/localPeeringDataFetcher"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/server"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"os"
+	"testing"
+	"time"
+)
+
+// helperPerformerEnvVar makes the test binary act as a performer binary, so the tests can run
+// a real child process without building anything.
+const helperPerformerEnvVar = "PROCESS_PERFORMER_TEST_HELPER"
+
+type echoWorker struct{}
+
+func (w *echoWorker) ValidateTask(task *performerV1.TaskRequest) error {
+	return nil
+}
+
+func (w *echoWorker) HandleTask(task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	return &performerV1.TaskResponse{TaskId: task.TaskId, Result: task.Payload}, nil
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperPerformerEnvVar) != "1" {
+		os.Exit(m.Run())
+	}
+
+	l, _ := zap.NewProduction()
+	pp, err := server.NewPonosPerformerWithRpcServer(&server.PonosPerformerConfig{Port: 8080}, &echoWorker{}, l)
+	if err != nil {
+		panic(err)
+	}
+	if err := pp.Start(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+func Test_AvsPerformerProcess(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	results := make(chan *performerTask.PerformerTaskResult, 10)
+	app, err := NewAvsPerformerProcess(&avsPerformer.AvsPerformerConfig{
+		AvsAddress:   "0xavs1",
+		ProcessType:  avsPerformer.AvsProcessTypeProcess,
+		WorkerCount:  1,
+		SigningCurve: "bn254",
+		Process: avsPerformer.PerformerProcess{
+			Path: os.Args[0],
+			Env:  []string{fmt.Sprintf("%s=1", helperPerformerEnvVar)},
+		},
+	},
+		localPeeringDataFetcher.NewLocalPeeringDataFetcher(&localPeeringDataFetcher.LocalPeeringDataFetcherConfig{}, l),
+		func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+			results <- response
+		},
+		l,
+	)
+	if err != nil {
+		t.Fatalf("Failed to create process performer: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := app.Initialize(ctx); err != nil {
+		t.Fatalf("Failed to initialize process performer: %v", err)
+	}
+	if err := app.ProcessTasks(ctx); err != nil {
+		t.Fatalf("Failed to start processing tasks: %v", err)
+	}
+
+	runTask := func(taskId string) *performerTask.PerformerTaskResult {
+		assert.Nil(t, app.RunTask(ctx, &performerTask.PerformerTask{TaskID: taskId, Payload: []byte(taskId)}))
+		select {
+		case res := <-results:
+			return res
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Timed out waiting for result of task %s", taskId)
+		}
+		return nil
+	}
+
+	t.Run("executes tasks on the child process", func(t *testing.T) {
+		res := runTask("0x1")
+		assert.Equal(t, "0x1", res.TaskID)
+		assert.Equal(t, []byte("0x1"), res.Result)
+	})
+
+	t.Run("restarts the child process when it crashes", func(t *testing.T) {
+		app.mu.RLock()
+		crashed := app.process
+		app.mu.RUnlock()
+		assert.Nil(t, crashed.cmd.Process.Kill())
+		<-crashed.exited
+
+		deadline := time.Now().Add(15 * time.Second)
+		for {
+			app.mu.RLock()
+			restarted := app.process
+			app.mu.RUnlock()
+			if restarted != nil && restarted != crashed {
+				assert.NotEqual(t, crashed.cmd.Process.Pid, restarted.cmd.Process.Pid)
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Performer process was not restarted")
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		res := runTask("0x2")
+		assert.Equal(t, []byte("0x2"), res.Result)
+	})
+
+	t.Run("stops the child process on shutdown", func(t *testing.T) {
+		app.mu.RLock()
+		proc := app.process
+		app.mu.RUnlock()
+
+		assert.Nil(t, app.Shutdown())
+		select {
+		case <-proc.exited:
+		case <-time.After(10 * time.Second):
+			t.Fatalf("Performer process did not exit on shutdown")
+		}
+		assert.NotNil(t, app.RunTask(ctx, &performerTask.PerformerTask{TaskID: "0x3"}))
+	})
+}
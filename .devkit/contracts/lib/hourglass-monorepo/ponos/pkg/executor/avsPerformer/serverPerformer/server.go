@@ -2,41 +2,73 @@ package serverPerformer
 
 import (
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"fmt"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/avsPerformerClient"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer/performerContainer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
-	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
-	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
-	"github.com/docker/docker/api/types/container"
-	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/client"
-	"github.com/docker/go-connections/nat"
-	"github.com/pkg/errors"
 	"go.uber.org/zap"
-	"strings"
 	"sync"
 	"time"
 )
 
-type AvsPerformerServer struct {
-	config          *avsPerformer.AvsPerformerConfig
-	logger          *zap.Logger
-	containerId     string
-	dockerClient    *client.Client
-	performerClient performerV1.PerformerServiceClient
-	// TODO(seanmcgary) make this an actual chan with a type
-	taskBacklog chan *performerTask.PerformerTask
+const (
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+
+	// maxConsecutiveHealthFailures is the number of failed health checks in a row after which
+	// the performer container is recreated
+	maxConsecutiveHealthFailures = 3
+
+	initialRestartBackoff = 1 * time.Second
+	maxRestartBackoff     = 60 * time.Second
+
+	// taskHoldTimeout is how long a task waits for a performer that is down to come back
+	taskHoldTimeout = 60 * time.Second
+)
 
-	peeringFetcher peering.IPeeringDataFetcher
+// performerInstance is a running performer and a client connected to it
+type performerInstance struct {
+	client    performerV1.PerformerServiceClient
+	isRunning func(ctx context.Context) (bool, error)
+	remove    func() error
+}
+
+type startPerformerFunc func(ctx context.Context) (*performerInstance, error)
+
+type AvsPerformerServer struct {
+	config       *avsPerformer.AvsPerformerConfig
+	logger       *zap.Logger
+	dockerClient *client.Client
+	taskPool     *avsPerformer.TaskPool
 
 	reportTaskResponse avsPerformer.ReceiveTaskResponse
 
-	aggregatorPeers []*peering.OperatorPeerInfo
+	signatureValidator *avsPerformer.TaskSignatureValidator
+
+	startPerformer startPerformerFunc
+
+	// stateMu guards the current performer instance and its state. readyChan is closed while the
+	// performer is ready (or stopped) and replaced by an open channel when it goes down, so that
+	// tasks can wait for it to come back.
+	stateMu        sync.RWMutex
+	state          avsPerformer.PerformerState
+	instance       *performerInstance
+	readyChan      chan struct{}
+	readyChanOpen  bool
+	restartCount   int
+	lastTransition time.Time
+
+	// lastRestartError and nextRestartAt describe the restart backoff
+	lastRestartError string
+	nextRestartAt    time.Time
+
+	healthCheckInterval time.Duration
+	restartBackoff      time.Duration
+	taskHoldTimeout     time.Duration
 }
 
 func NewAvsPerformerServer(
@@ -45,65 +77,41 @@ func NewAvsPerformerServer(
 	reportTaskResponse avsPerformer.ReceiveTaskResponse,
 	logger *zap.Logger,
 ) (*AvsPerformerServer, error) {
-	return &AvsPerformerServer{
-		config:             config,
-		logger:             logger,
-		taskBacklog:        make(chan *performerTask.PerformerTask, 50),
-		reportTaskResponse: reportTaskResponse,
-		peeringFetcher:     peeringFetcher,
-	}, nil
-}
-
-const containerPort = 8080
-
-// take a sha shash of the avs address and return the first 6 chars
-func hashAvsAddress(avsAddress string) string {
-	hasher := sha256.New()
-
-	hasher.Write([]byte(avsAddress))
-	hashBytes := hasher.Sum(nil)
-
-	return hex.EncodeToString(hashBytes)[0:6]
-}
-
-func (aps *AvsPerformerServer) fetchAggregatorPeerInfo(ctx context.Context) ([]*peering.OperatorPeerInfo, error) {
-	retries := []uint64{1, 3, 5, 10, 20}
-	for i, retry := range retries {
-		aggPeers, err := aps.peeringFetcher.ListAggregatorOperators(ctx, aps.config.AvsAddress)
-		if err != nil {
-			aps.logger.Sugar().Errorw("Failed to fetch aggregator peers",
-				zap.String("avsAddress", aps.config.AvsAddress),
-				zap.Error(err),
-			)
-			if i == len(retries)-1 {
-				aps.logger.Sugar().Infow("Giving up on fetching aggregator peers",
-					zap.String("avsAddress", aps.config.AvsAddress),
-					zap.Error(err),
-				)
-				return nil, err
-			}
-			time.Sleep(time.Duration(retry) * time.Second)
-			continue
-		}
-		return aggPeers, nil
+	signatureValidator, err := avsPerformer.NewTaskSignatureValidator(config.AvsAddress, config.SigningCurve, peeringFetcher, logger)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("failed to fetch aggregator peers after retries")
+	aps := &AvsPerformerServer{
+		config:              config,
+		logger:              logger,
+		reportTaskResponse:  reportTaskResponse,
+		signatureValidator:  signatureValidator,
+		state:               avsPerformer.PerformerStateStarting,
+		readyChan:           make(chan struct{}),
+		readyChanOpen:       true,
+		lastTransition:      time.Now(),
+		healthCheckInterval: healthCheckInterval,
+		restartBackoff:      initialRestartBackoff,
+		taskHoldTimeout:     taskHoldTimeout,
+	}
+	aps.startPerformer = aps.startPerformerContainer
+	aps.taskPool = avsPerformer.NewTaskPool(
+		config.AvsAddress,
+		config.WorkerCount,
+		config.TaskBacklogSize,
+		aps.processTask,
+		reportTaskResponse,
+		logger,
+	)
+	return aps, nil
 }
 
 func (aps *AvsPerformerServer) Initialize(ctx context.Context) error {
-	containerPortProto := nat.Port(fmt.Sprintf("%d/tcp", containerPort))
-
-	aggregatorPeers, err := aps.fetchAggregatorPeerInfo(ctx)
-	if err != nil {
+	if err := aps.signatureValidator.FetchAggregatorPeers(ctx); err != nil {
 		return err
 	}
-	aps.aggregatorPeers = aggregatorPeers
-	aps.logger.Sugar().Infow("Fetched aggregator peers",
-		zap.String("avsAddress", aps.config.AvsAddress),
-		zap.Any("aggregatorPeers", aps.aggregatorPeers),
-	)
 
-	dockerClient, err := client.NewClientWithOpts(client.FromEnv)
+	dockerClient, err := performerContainer.NewDockerClient(ctx)
 	if err != nil {
 		aps.logger.Sugar().Errorw("Failed to create Docker perfClient for performer",
 			zap.String("avsAddress", aps.config.AvsAddress),
@@ -111,193 +119,80 @@ func (aps *AvsPerformerServer) Initialize(ctx context.Context) error {
 		)
 		return err
 	}
-	dockerClient.NegotiateAPIVersion(ctx)
 	aps.dockerClient = dockerClient
 
-	hostname := fmt.Sprintf("avs-performer-%s", hashAvsAddress(aps.config.AvsAddress))
-
-	aps.logger.Sugar().Infow("Using hostname",
-		zap.String("hostname", hostname),
-	)
-
-	if aps.config.PerformerNetworkName != "" {
-		if err := aps.createNetworkIfNotExists(ctx, dockerClient, aps.config.PerformerNetworkName); err != nil {
-			aps.logger.Sugar().Errorw("Failed to create Docker network for performer",
-				zap.String("avsAddress", aps.config.AvsAddress),
-				zap.Error(err),
-			)
-			return err
-		}
-	}
+	return aps.startAndSupervise(ctx)
+}
 
-	containerConfg := &container.Config{
-		Hostname: hostname,
-		Image:    fmt.Sprintf("%s:%s", aps.config.Image.Repository, aps.config.Image.Tag),
-		ExposedPorts: nat.PortSet{
-			containerPortProto: struct{}{},
-		},
+// startAndSupervise starts the first performer instance and the supervisor that keeps it running
+func (aps *AvsPerformerServer) startAndSupervise(ctx context.Context) error {
+	instance, err := aps.startPerformer(ctx)
+	if err != nil {
+		return err
 	}
+	aps.setInstance(instance)
 
-	hostConfig := &container.HostConfig{
-		AutoRemove: true,
-		PortBindings: nat.PortMap{
-			containerPortProto: []nat.PortBinding{
-				{
-					HostIP: "0.0.0.0",
-
-					// leave this blank to let Docker handle creating a random port
-					HostPort: "",
-				},
-			},
-		},
-	}
+	go aps.supervise(ctx)
 
-	var netConfig *network.NetworkingConfig
-	if aps.config.PerformerNetworkName != "" {
-		netConfig = &network.NetworkingConfig{
-			EndpointsConfig: map[string]*network.EndpointSettings{
-				aps.config.PerformerNetworkName: {},
-			},
-		}
-	}
+	return nil
+}
 
-	res, err := dockerClient.ContainerCreate(
-		ctx,
-		containerConfg,
-		hostConfig,
-		netConfig,
-		nil,
-		hostname,
-	)
-	if err != nil {
-		aps.logger.Sugar().Errorw("Failed to create Docker container for performer",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		return err
-	}
-	aps.containerId = res.ID
+func (aps *AvsPerformerServer) startPerformerContainer(ctx context.Context) (*performerInstance, error) {
+	hostname := fmt.Sprintf("avs-performer-%s", performerContainer.HashAvsAddress(aps.config.AvsAddress))
 
-	if err := dockerClient.ContainerStart(ctx, res.ID, container.StartOptions{}); err != nil {
-		aps.logger.Sugar().Errorw("Failed to start Docker container for performer",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		shutdownErr := aps.Shutdown()
-		if shutdownErr != nil {
-			err = errors.Wrap(err, "failed to shutdown Docker container")
-		}
-		return err
-	}
-	aps.logger.Sugar().Infow("Started Docker container for performer",
-		zap.String("avsAddress", aps.config.AvsAddress),
-		zap.String("containerID", res.ID),
+	aps.logger.Sugar().Infow("Using hostname",
+		zap.String("hostname", hostname),
 	)
 
-	running, err := aps.waitForRunning(ctx, dockerClient, res.ID, containerPortProto)
-	if err != nil || !running {
-		aps.logger.Sugar().Errorw("Failed to wait for Docker container to be running",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		shutdownErr := aps.Shutdown()
-		if shutdownErr != nil {
-			err = errors.Wrap(err, "failed to shutdown Docker container")
-		}
-		return err
-	}
-
-	containerInfo, err := dockerClient.ContainerInspect(ctx, res.ID)
+	pc, err := performerContainer.StartContainer(ctx, aps.dockerClient, performerContainer.NewContainerConfig(aps.config, hostname), aps.logger)
 	if err != nil {
-		aps.logger.Sugar().Errorw("Failed to inspect Docker container for performer",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		shutdownErr := aps.Shutdown()
-		if shutdownErr != nil {
-			err = errors.Wrap(err, "failed to shutdown Docker container")
-		}
-		return err
-	}
-	var exposedPort string
-	if portMap, ok := containerInfo.NetworkSettings.Ports[containerPortProto]; !ok {
-		aps.logger.Sugar().Errorw("Failed to get exposed port from Docker container",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		shutdownErr := aps.Shutdown()
-		if shutdownErr != nil {
-			err = errors.Wrap(err, "failed to shutdown Docker container")
-		}
-		return err
-	} else if len(portMap) == 0 {
-		aps.logger.Sugar().Errorw("No exposed ports found in Docker container",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-	} else {
-		exposedPort = portMap[0].HostPort
-	}
-
-	containerHost := "localhost"
-	if aps.config.PerformerNetworkName != "" {
-		containerHost = hostname
-		exposedPort = fmt.Sprintf("%d", containerPort)
-		aps.logger.Sugar().Infow("Custom network provided, using container hostname and container port",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.String("containerHost", containerHost),
-			zap.String("exposedPort", exposedPort),
-			zap.String("containerID", res.ID),
-		)
+		return nil, err
 	}
 
-	perfClient, err := avsPerformerClient.NewAvsPerformerClient(fmt.Sprintf("%s:%s", containerHost, exposedPort), true)
+	perfClient, err := avsPerformerClient.NewAvsPerformerClient(pc.Endpoint, true)
 	if err != nil {
 		aps.logger.Sugar().Errorw("Failed to create performer perfClient",
 			zap.String("avsAddress", aps.config.AvsAddress),
 			zap.Error(err),
 		)
-		shutdownErr := aps.Shutdown()
-		if shutdownErr != nil {
-			err = errors.Wrap(err, "failed to shutdown Docker container")
+		if removeErr := pc.Remove(); removeErr != nil {
+			return nil, fmt.Errorf("%w (failed to remove Docker container: %v)", err, removeErr)
 		}
-		return err
+		return nil, err
 	}
-	aps.performerClient = perfClient
-
-	go aps.startHealthCheck(ctx)
-
-	return nil
+	return &performerInstance{
+		client:    perfClient,
+		isRunning: pc.IsRunning,
+		remove:    pc.Remove,
+	}, nil
 }
 
+// ProcessTasks starts WorkerCount workers that consume the task backlog concurrently.
+// Workers run until the backlog is closed and drained by Shutdown.
 func (aps *AvsPerformerServer) ProcessTasks(ctx context.Context) error {
-	var wg sync.WaitGroup
-	for i := 0; i < aps.config.WorkerCount; i++ {
-		wg.Add(1)
-	}
-	go func(wg *sync.WaitGroup) {
-		defer wg.Done()
-		aps.logger.Sugar().Infow("Waiting for tasks", zap.String("avs", aps.config.AvsAddress))
-		for task := range aps.taskBacklog {
-			res, err := aps.processTask(ctx, task)
-			if err != nil {
-				aps.logger.Sugar().Errorw("Failed to process task",
-					zap.String("avsAddress", aps.config.AvsAddress),
-					zap.Error(err),
-				)
-				continue
-			}
-			aps.reportTaskResponse(task, res, err)
-		}
-
-	}(&wg)
+	aps.taskPool.Start(ctx)
 	return nil
 }
 
+// InflightTasks returns the number of tasks currently being executed by the performer
+func (aps *AvsPerformerServer) InflightTasks() int64 {
+	return aps.taskPool.InflightTasks()
+}
+
 func (aps *AvsPerformerServer) processTask(ctx context.Context, task *performerTask.PerformerTask) (*performerTask.PerformerTaskResult, error) {
 	aps.logger.Sugar().Infow("Processing task", zap.Any("task", task))
 
-	res, err := aps.performerClient.ExecuteTask(ctx, &performerV1.TaskRequest{
+	instance, err := aps.waitForPerformer(ctx)
+	if err != nil {
+		aps.logger.Sugar().Errorw("Performer is not available for task",
+			zap.String("avsAddress", aps.config.AvsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+
+	res, err := instance.client.ExecuteTask(ctx, &performerV1.TaskRequest{
 		TaskId:   []byte(task.TaskID),
 		Metadata: task.Metadata,
 		Payload:  task.Payload,
@@ -313,94 +208,30 @@ func (aps *AvsPerformerServer) processTask(ctx context.Context, task *performerT
 	return performerTask.NewTaskResultFromResultProto(res), nil
 }
 
-func (aps *AvsPerformerServer) ValidateTaskSignature(t *performerTask.PerformerTask) error {
-	sig, err := bn254.NewSignatureFromBytes(t.Signature)
-	if err != nil {
-		aps.logger.Sugar().Errorw("Failed to create signature from bytes",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		return err
-	}
-	peer := util.Find(aps.aggregatorPeers, func(p *peering.OperatorPeerInfo) bool {
-		return strings.EqualFold(p.OperatorAddress, t.AggregatorAddress)
-	})
-	if peer == nil {
-		aps.logger.Sugar().Errorw("Failed to find peer for task",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.String("aggregatorAddress", t.AggregatorAddress),
-		)
-		return fmt.Errorf("failed to find peer for task")
-	}
-
-	verfied, err := sig.Verify(peer.PublicKey, t.Payload)
-	if err != nil {
-		aps.logger.Sugar().Errorw("Failed to verify signature",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.String("aggregatorAddress", t.AggregatorAddress),
-			zap.Error(err),
-		)
-		return err
-	}
-	if !verfied {
-		aps.logger.Sugar().Errorw("Failed to verify signature",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		return fmt.Errorf("failed to verify signature")
-	}
-
-	return nil
+func (aps *AvsPerformerServer) ValidateTaskSignature(ctx context.Context, t *performerTask.PerformerTask) error {
+	return aps.signatureValidator.ValidateTaskSignature(ctx, t)
 }
 
+// RunTask queues the task for the performer. Tasks are rejected once the performer is stopped;
+// while it is restarting they are held in the backlog until it is ready again.
 func (aps *AvsPerformerServer) RunTask(ctx context.Context, task *performerTask.PerformerTask) error {
-	select {
-	case aps.taskBacklog <- task:
-		aps.logger.Sugar().Infow("PerformerTask added to backlog")
-	default:
-		aps.logger.Sugar().Infow("PerformerTask backlog is full, dropping task")
-		return fmt.Errorf("task backlog is full for avs %s", aps.config.AvsAddress)
+	if state := aps.State(); state == avsPerformer.PerformerStateStopped {
+		return fmt.Errorf("performer for avs %s is %s", aps.config.AvsAddress, state)
 	}
-	return nil
+	return aps.taskPool.Submit(task)
 }
 
 func (aps *AvsPerformerServer) Shutdown() error {
-	if len(aps.containerId) == 0 {
-		return nil
-	}
-	if aps.dockerClient == nil {
-		return nil
-	}
+	aps.taskPool.Drain()
 
-	aps.logger.Sugar().Infow("Stopping Docker container for performer",
-		zap.String("avsAddress", aps.config.AvsAddress),
-		zap.String("containerID", aps.containerId),
-	)
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-	if err := aps.dockerClient.ContainerStop(ctx, aps.containerId, container.StopOptions{}); err != nil {
-		aps.logger.Sugar().Errorw("Failed to stop Docker container for performer",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-	} else {
-		aps.logger.Sugar().Infow("Stopped Docker container for performer",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.String("containerID", aps.containerId),
-		)
-	}
-	aps.logger.Sugar().Infow("Removing Docker container for performer",
-		zap.String("avsAddress", aps.config.AvsAddress),
-		zap.String("containerID", aps.containerId),
-	)
-	if err := aps.dockerClient.ContainerRemove(context.Background(), aps.containerId, container.RemoveOptions{
-		Force: true,
-	}); err != nil {
-		aps.logger.Sugar().Errorw("Failed to remove Docker container for performer",
-			zap.String("avsAddress", aps.config.AvsAddress),
-			zap.Error(err),
-		)
-		return err
+	aps.stateMu.Lock()
+	instance := aps.instance
+	aps.instance = nil
+	aps.setStateLocked(avsPerformer.PerformerStateStopped)
+	aps.stateMu.Unlock()
+
+	if instance == nil {
+		return nil
 	}
-	return nil
+	return instance.remove()
 }
@@ -0,0 +1,321 @@
+package serverPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type blockingPerformerClient struct {
+	delay       time.Duration
+	current     atomic.Int64
+	maxObserved atomic.Int64
+}
+
+func (c *blockingPerformerClient) HealthCheck(ctx context.Context, in *performerV1.HealthCheckRequest, opts ...grpc.CallOption) (*performerV1.HealthCheckResponse, error) {
+	return &performerV1.HealthCheckResponse{Status: performerV1.PerformerStatus_READY_FOR_TASK}, nil
+}
+
+func (c *blockingPerformerClient) StartSync(ctx context.Context, in *performerV1.StartSyncRequest, opts ...grpc.CallOption) (*performerV1.StartSyncResponse, error) {
+	return &performerV1.StartSyncResponse{}, nil
+}
+
+func (c *blockingPerformerClient) ExecuteTask(ctx context.Context, in *performerV1.TaskRequest, opts ...grpc.CallOption) (*performerV1.TaskResponse, error) {
+	current := c.current.Add(1)
+	defer c.current.Add(-1)
+	for {
+		observed := c.maxObserved.Load()
+		if current <= observed || c.maxObserved.CompareAndSwap(observed, current) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	return &performerV1.TaskResponse{TaskId: in.TaskId, Result: in.Payload}, nil
+}
+
+// unhealthyPerformerClient fails health checks and tasks until it is replaced
+type unhealthyPerformerClient struct {
+	blockingPerformerClient
+}
+
+func (c *unhealthyPerformerClient) HealthCheck(ctx context.Context, in *performerV1.HealthCheckRequest, opts ...grpc.CallOption) (*performerV1.HealthCheckResponse, error) {
+	return nil, status.Error(codes.Unavailable, "performer is down")
+}
+
+func (c *unhealthyPerformerClient) ExecuteTask(ctx context.Context, in *performerV1.TaskRequest, opts ...grpc.CallOption) (*performerV1.TaskResponse, error) {
+	return nil, status.Error(codes.Unavailable, "performer is down")
+}
+
+func Test_AvsPerformerServerWorkerPool(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	newServer := func(workerCount int, client performerV1.PerformerServiceClient, onResult avsPerformer.ReceiveTaskResponse) *AvsPerformerServer {
+		aps, err := NewAvsPerformerServer(&avsPerformer.AvsPerformerConfig{
+			AvsAddress:   "0xavs1",
+			ProcessType:  avsPerformer.AvsProcessTypeServer,
+			WorkerCount:  workerCount,
+			SigningCurve: "bn254",
+		}, nil, onResult, l)
+		if err != nil {
+			t.Fatalf("Failed to create performer server: %v", err)
+		}
+		aps.setInstance(&performerInstance{
+			client: client,
+			remove: func() error { return nil },
+		})
+		return aps
+	}
+
+	t.Run("processes tasks concurrently up to the worker count", func(t *testing.T) {
+		client := &blockingPerformerClient{delay: 100 * time.Millisecond}
+
+		var wg sync.WaitGroup
+		var completed atomic.Int64
+		aps := newServer(4, client, func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+			completed.Add(1)
+			wg.Done()
+		})
+		if err := aps.ProcessTasks(context.Background()); err != nil {
+			t.Fatalf("Failed to start processing tasks: %v", err)
+		}
+
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			err := aps.RunTask(context.Background(), &performerTask.PerformerTask{
+				TaskID:  fmt.Sprintf("0x%d", i),
+				Payload: []byte("payload"),
+			})
+			assert.Nil(t, err)
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(8), completed.Load())
+		assert.Equal(t, int64(4), client.maxObserved.Load())
+		assert.Equal(t, int64(0), aps.InflightTasks())
+	})
+
+	t.Run("drains queued tasks on shutdown and rejects new ones", func(t *testing.T) {
+		client := &blockingPerformerClient{delay: 50 * time.Millisecond}
+
+		var completed atomic.Int64
+		aps := newServer(1, client, func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+			completed.Add(1)
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		if err := aps.ProcessTasks(ctx); err != nil {
+			t.Fatalf("Failed to start processing tasks: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			assert.Nil(t, aps.RunTask(ctx, &performerTask.PerformerTask{TaskID: fmt.Sprintf("0x%d", i)}))
+		}
+		cancel()
+
+		assert.Nil(t, aps.Shutdown())
+		assert.Equal(t, int64(3), completed.Load())
+		assert.NotNil(t, aps.RunTask(context.Background(), &performerTask.PerformerTask{TaskID: "0xlate"}))
+	})
+}
+
+func Test_AvsPerformerServerSupervision(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	t.Run("recreates an unhealthy performer and holds tasks until it is ready", func(t *testing.T) {
+		results := make(chan *performerTask.PerformerTaskResult, 10)
+		aps, err := NewAvsPerformerServer(&avsPerformer.AvsPerformerConfig{
+			AvsAddress:   "0xavs1",
+			ProcessType:  avsPerformer.AvsProcessTypeServer,
+			WorkerCount:  1,
+			SigningCurve: "bn254",
+		}, nil, func(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+			assert.Nil(t, err)
+			results <- response
+		}, l)
+		if err != nil {
+			t.Fatalf("Failed to create performer server: %v", err)
+		}
+		aps.healthCheckInterval = 10 * time.Millisecond
+		aps.restartBackoff = 10 * time.Millisecond
+
+		var starts atomic.Int64
+		var removed atomic.Int64
+		aps.startPerformer = func(ctx context.Context) (*performerInstance, error) {
+			var client performerV1.PerformerServiceClient
+			switch starts.Add(1) {
+			case 1:
+				client = &unhealthyPerformerClient{}
+			case 2:
+				return nil, fmt.Errorf("failed to start container")
+			default:
+				// give tasks a chance to queue up while the performer is restarting
+				time.Sleep(50 * time.Millisecond)
+				client = &blockingPerformerClient{}
+			}
+			return &performerInstance{
+				client: client,
+				remove: func() error {
+					removed.Add(1)
+					return nil
+				},
+			}, nil
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if err := aps.startAndSupervise(ctx); err != nil {
+			t.Fatalf("Failed to start performer: %v", err)
+		}
+		assert.Equal(t, avsPerformer.PerformerStateReady, aps.State())
+
+		deadline := time.Now().Add(5 * time.Second)
+		for aps.State() != avsPerformer.PerformerStateRestarting {
+			if time.Now().After(deadline) {
+				t.Fatalf("Performer was never restarted")
+			}
+			time.Sleep(time.Millisecond)
+		}
+
+		if err := aps.ProcessTasks(ctx); err != nil {
+			t.Fatalf("Failed to start processing tasks: %v", err)
+		}
+		assert.Nil(t, aps.RunTask(ctx, &performerTask.PerformerTask{TaskID: "0x1", Payload: []byte("0x1")}))
+
+		select {
+		case res := <-results:
+			assert.Equal(t, []byte("0x1"), res.Result)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("Timed out waiting for task result")
+		}
+
+		status := aps.Status()
+		assert.Equal(t, avsPerformer.PerformerStateReady, status.State)
+		assert.Equal(t, 1, status.RestartCount)
+		assert.Empty(t, status.LastError)
+		assert.True(t, status.NextRestartAt.IsZero())
+		assert.Equal(t, int64(3), starts.Load())
+		assert.Equal(t, int64(1), removed.Load())
+
+		assert.Nil(t, aps.Shutdown())
+		assert.Equal(t, avsPerformer.PerformerStateStopped, aps.State())
+		assert.Equal(t, int64(2), removed.Load())
+		assert.NotNil(t, aps.RunTask(ctx, &performerTask.PerformerTask{TaskID: "0x2"}))
+	})
+
+	t.Run("reports the last restart error only until the performer is ready again", func(t *testing.T) {
+		aps, err := NewAvsPerformerServer(&avsPerformer.AvsPerformerConfig{
+			AvsAddress:   "0xavs1",
+			ProcessType:  avsPerformer.AvsProcessTypeServer,
+			WorkerCount:  1,
+			SigningCurve: "bn254",
+		}, nil, nil, l)
+		if err != nil {
+			t.Fatalf("Failed to create performer server: %v", err)
+		}
+		aps.restartBackoff = 10 * time.Millisecond
+
+		release := make(chan struct{})
+		var starts atomic.Int64
+		aps.startPerformer = func(ctx context.Context) (*performerInstance, error) {
+			if starts.Add(1) == 1 {
+				return nil, fmt.Errorf("failed to start container")
+			}
+			<-release
+			return &performerInstance{
+				client: &blockingPerformerClient{},
+				remove: func() error { return nil },
+			}, nil
+		}
+
+		done := make(chan struct{})
+		go func() {
+			aps.restartPerformer(context.Background(), &performerInstance{remove: func() error { return nil }})
+			close(done)
+		}()
+
+		deadline := time.Now().Add(5 * time.Second)
+		for starts.Load() < 2 {
+			if time.Now().After(deadline) {
+				t.Fatalf("Performer restart was never retried")
+			}
+			time.Sleep(time.Millisecond)
+		}
+		status := aps.Status()
+		assert.Equal(t, avsPerformer.PerformerStateRestarting, status.State)
+		assert.Equal(t, "failed to start container", status.LastError)
+
+		close(release)
+		<-done
+
+		status = aps.Status()
+		assert.Equal(t, avsPerformer.PerformerStateReady, status.State)
+		assert.Empty(t, status.LastError)
+		assert.True(t, status.NextRestartAt.IsZero())
+	})
+
+	t.Run("fails held tasks when the performer does not recover in time", func(t *testing.T) {
+		aps, err := NewAvsPerformerServer(&avsPerformer.AvsPerformerConfig{
+			AvsAddress:   "0xavs1",
+			ProcessType:  avsPerformer.AvsProcessTypeServer,
+			WorkerCount:  1,
+			SigningCurve: "bn254",
+		}, nil, nil, l)
+		if err != nil {
+			t.Fatalf("Failed to create performer server: %v", err)
+		}
+		aps.taskHoldTimeout = 50 * time.Millisecond
+		aps.stateMu.Lock()
+		aps.setStateLocked(avsPerformer.PerformerStateRestarting)
+		aps.stateMu.Unlock()
+
+		_, err = aps.processTask(context.Background(), &performerTask.PerformerTask{TaskID: "0x1"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("stops supervising when the context is cancelled", func(t *testing.T) {
+		aps, err := NewAvsPerformerServer(&avsPerformer.AvsPerformerConfig{
+			AvsAddress:   "0xavs1",
+			ProcessType:  avsPerformer.AvsProcessTypeServer,
+			WorkerCount:  1,
+			SigningCurve: "bn254",
+		}, nil, nil, l)
+		if err != nil {
+			t.Fatalf("Failed to create performer server: %v", err)
+		}
+		aps.healthCheckInterval = 10 * time.Millisecond
+		aps.setInstance(&performerInstance{
+			client: &blockingPerformerClient{},
+			remove: func() error { return nil },
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			aps.supervise(ctx)
+			close(done)
+		}()
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("Supervisor did not stop")
+		}
+	})
+}
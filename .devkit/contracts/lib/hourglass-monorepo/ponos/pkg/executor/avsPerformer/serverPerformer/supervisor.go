@@ -0,0 +1,239 @@
+package serverPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"go.uber.org/zap"
+	"time"
+)
+
+// State returns the current state of the performer
+func (aps *AvsPerformerServer) State() avsPerformer.PerformerState {
+	aps.stateMu.RLock()
+	defer aps.stateMu.RUnlock()
+	return aps.state
+}
+
+// Status returns the current state of the performer, how many times it was restarted, when it
+// entered the current state and, while restarting, why the last attempt failed and when the next
+// one is made
+func (aps *AvsPerformerServer) Status() avsPerformer.PerformerStatus {
+	aps.stateMu.RLock()
+	defer aps.stateMu.RUnlock()
+	return avsPerformer.PerformerStatus{
+		State:         aps.state,
+		RestartCount:  aps.restartCount,
+		Since:         aps.lastTransition,
+		LastError:     aps.lastRestartError,
+		NextRestartAt: aps.nextRestartAt,
+	}
+}
+
+// setStateLocked records a state transition and opens or closes readyChan accordingly.
+// The caller must hold stateMu.
+func (aps *AvsPerformerServer) setStateLocked(state avsPerformer.PerformerState) {
+	if aps.state == state {
+		return
+	}
+	aps.logger.Sugar().Infow("Performer state changed",
+		zap.String("avsAddress", aps.config.AvsAddress),
+		zap.String("from", string(aps.state)),
+		zap.String("to", string(state)),
+		zap.Int("restartCount", aps.restartCount),
+	)
+	aps.state = state
+	aps.lastTransition = time.Now()
+
+	switch state {
+	case avsPerformer.PerformerStateReady, avsPerformer.PerformerStateStopped:
+		// wake up anything waiting on the performer
+		if aps.readyChanOpen {
+			close(aps.readyChan)
+			aps.readyChanOpen = false
+		}
+	default:
+		if !aps.readyChanOpen {
+			aps.readyChan = make(chan struct{})
+			aps.readyChanOpen = true
+		}
+	}
+}
+
+func (aps *AvsPerformerServer) setInstance(instance *performerInstance) {
+	aps.stateMu.Lock()
+	defer aps.stateMu.Unlock()
+	if aps.state == avsPerformer.PerformerStateStopped {
+		return
+	}
+	aps.instance = instance
+	aps.setStateLocked(avsPerformer.PerformerStateReady)
+}
+
+// waitForPerformer returns the ready performer instance, holding the caller for up to
+// taskHoldTimeout while the performer is being restarted.
+func (aps *AvsPerformerServer) waitForPerformer(ctx context.Context) (*performerInstance, error) {
+	timeout := time.After(aps.taskHoldTimeout)
+	for {
+		aps.stateMu.RLock()
+		state := aps.state
+		instance := aps.instance
+		readyChan := aps.readyChan
+		aps.stateMu.RUnlock()
+
+		switch state {
+		case avsPerformer.PerformerStateReady:
+			return instance, nil
+		case avsPerformer.PerformerStateStopped:
+			return nil, fmt.Errorf("performer for avs %s is stopped", aps.config.AvsAddress)
+		}
+
+		select {
+		case <-readyChan:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, fmt.Errorf("performer for avs %s is %s and did not recover within %s", aps.config.AvsAddress, state, aps.taskHoldTimeout)
+		}
+	}
+}
+
+// supervise health checks the performer and recreates it after maxConsecutiveHealthFailures
+// failed checks in a row, or immediately if its container is gone. It runs until ctx is done
+// or the performer is stopped.
+func (aps *AvsPerformerServer) supervise(ctx context.Context) {
+	ticker := time.NewTicker(aps.healthCheckInterval)
+	defer ticker.Stop()
+
+	consecutiveFailures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			aps.logger.Sugar().Infow("Stopping performer supervisor",
+				zap.String("avsAddress", aps.config.AvsAddress),
+			)
+			return
+		case <-ticker.C:
+		}
+
+		aps.stateMu.RLock()
+		state := aps.state
+		instance := aps.instance
+		aps.stateMu.RUnlock()
+		if state == avsPerformer.PerformerStateStopped {
+			return
+		}
+		if instance == nil {
+			continue
+		}
+
+		running, err := aps.checkHealth(ctx, instance)
+		if err == nil {
+			consecutiveFailures = 0
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		consecutiveFailures++
+		aps.logger.Sugar().Warnw("Performer health check failed",
+			zap.String("avsAddress", aps.config.AvsAddress),
+			zap.Int("consecutiveFailures", consecutiveFailures),
+			zap.Bool("containerRunning", running),
+			zap.Error(err),
+		)
+		if running && consecutiveFailures < maxConsecutiveHealthFailures {
+			continue
+		}
+
+		aps.restartPerformer(ctx, instance)
+		consecutiveFailures = 0
+	}
+}
+
+// checkHealth reports whether the performer process is still running and returns an error if it
+// is not healthy
+func (aps *AvsPerformerServer) checkHealth(ctx context.Context, instance *performerInstance) (bool, error) {
+	if instance.isRunning != nil {
+		running, err := instance.isRunning(ctx)
+		if err == nil && !running {
+			return false, fmt.Errorf("performer container is no longer running")
+		}
+	}
+
+	healthCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	res, err := instance.client.HealthCheck(healthCtx, &performerV1.HealthCheckRequest{})
+	if err != nil {
+		return true, err
+	}
+	aps.logger.Sugar().Debugw("Got health response",
+		zap.String("avsAddress", aps.config.AvsAddress),
+		zap.String("status", res.Status.String()),
+	)
+	return true, nil
+}
+
+// restartPerformer tears down the unhealthy instance and starts a new one, retrying with an
+// exponential backoff until it succeeds, ctx is done or the performer is stopped.
+func (aps *AvsPerformerServer) restartPerformer(ctx context.Context, unhealthy *performerInstance) {
+	aps.stateMu.Lock()
+	if aps.state == avsPerformer.PerformerStateStopped {
+		aps.stateMu.Unlock()
+		return
+	}
+	aps.instance = nil
+	aps.setStateLocked(avsPerformer.PerformerStateRestarting)
+	aps.stateMu.Unlock()
+
+	if err := unhealthy.remove(); err != nil {
+		aps.logger.Sugar().Warnw("Failed to remove unhealthy performer",
+			zap.String("avsAddress", aps.config.AvsAddress),
+			zap.Error(err),
+		)
+	}
+
+	backoff := aps.restartBackoff
+	for attempt := 1; ; attempt++ {
+		instance, err := aps.startPerformer(ctx)
+		if err == nil {
+			aps.stateMu.Lock()
+			aps.restartCount++
+			aps.lastRestartError = ""
+			aps.nextRestartAt = time.Time{}
+			aps.stateMu.Unlock()
+			aps.setInstance(instance)
+
+			aps.logger.Sugar().Infow("Restarted performer",
+				zap.String("avsAddress", aps.config.AvsAddress),
+				zap.Int("attempt", attempt),
+			)
+			// the instance may have been discarded by a concurrent shutdown
+			if aps.State() == avsPerformer.PerformerStateStopped {
+				_ = instance.remove()
+			}
+			return
+		}
+		aps.logger.Sugar().Errorw("Failed to restart performer",
+			zap.String("avsAddress", aps.config.AvsAddress),
+			zap.Int("attempt", attempt),
+			zap.Duration("nextAttemptIn", backoff),
+			zap.Error(err),
+		)
+		aps.stateMu.Lock()
+		aps.lastRestartError = err.Error()
+		aps.nextRestartAt = time.Now().Add(backoff)
+		aps.stateMu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if aps.State() == avsPerformer.PerformerStateStopped {
+			return
+		}
+		backoff = min(backoff*2, maxRestartBackoff)
+	}
+}
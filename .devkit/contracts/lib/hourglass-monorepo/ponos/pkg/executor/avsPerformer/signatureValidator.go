@@ -0,0 +1,166 @@
+package avsPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/keystore"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TaskSignatureValidator verifies that tasks were signed by one of the AVS's aggregators,
+// using the signing scheme of the AVS's configured curve.
+//
+// The aggregator peers are read through the peering fetcher for every task, so that rotated
+// aggregator keys and new aggregators are picked up without restarting the executor. A caching
+// fetcher decides how often they are actually read from the chain.
+type TaskSignatureValidator struct {
+	avsAddress     string
+	signingCurve   string
+	signingScheme  signing.SigningScheme
+	peeringFetcher peering.IPeeringDataFetcher
+	logger         *zap.Logger
+
+	mu              sync.RWMutex
+	aggregatorPeers []*peering.OperatorPeerInfo
+}
+
+func NewTaskSignatureValidator(
+	avsAddress string,
+	signingCurve string,
+	peeringFetcher peering.IPeeringDataFetcher,
+	logger *zap.Logger,
+) (*TaskSignatureValidator, error) {
+	signingScheme, err := keystore.GetSigningSchemeForCurveType(signingCurve)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signing scheme for AVS %s: %w", avsAddress, err)
+	}
+	return &TaskSignatureValidator{
+		avsAddress:     avsAddress,
+		signingCurve:   signingCurve,
+		signingScheme:  signingScheme,
+		peeringFetcher: peeringFetcher,
+		logger:         logger,
+	}, nil
+}
+
+// FetchAggregatorPeers loads the aggregator peers for the AVS, retrying with an increasing delay
+func (tsv *TaskSignatureValidator) FetchAggregatorPeers(ctx context.Context) error {
+	retries := []uint64{1, 3, 5, 10, 20}
+	for i, retry := range retries {
+		aggPeers, err := tsv.peeringFetcher.ListAggregatorOperators(ctx, tsv.avsAddress)
+		if err != nil {
+			tsv.logger.Sugar().Errorw("Failed to fetch aggregator peers",
+				zap.String("avsAddress", tsv.avsAddress),
+				zap.Error(err),
+			)
+			if i == len(retries)-1 {
+				tsv.logger.Sugar().Infow("Giving up on fetching aggregator peers",
+					zap.String("avsAddress", tsv.avsAddress),
+					zap.Error(err),
+				)
+				return err
+			}
+			time.Sleep(time.Duration(retry) * time.Second)
+			continue
+		}
+		tsv.SetAggregatorPeers(aggPeers)
+		tsv.logger.Sugar().Infow("Fetched aggregator peers",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.Any("aggregatorPeers", aggPeers),
+		)
+		return nil
+	}
+	return fmt.Errorf("failed to fetch aggregator peers after retries")
+}
+
+func (tsv *TaskSignatureValidator) SetAggregatorPeers(peers []*peering.OperatorPeerInfo) {
+	tsv.mu.Lock()
+	defer tsv.mu.Unlock()
+	tsv.aggregatorPeers = peers
+}
+
+// refreshAggregatorPeers returns the current aggregator peers, falling back to the last peers
+// that were fetched when the fetcher fails
+func (tsv *TaskSignatureValidator) refreshAggregatorPeers(ctx context.Context) []*peering.OperatorPeerInfo {
+	if tsv.peeringFetcher != nil {
+		peers, err := tsv.peeringFetcher.ListAggregatorOperators(ctx, tsv.avsAddress)
+		if err == nil {
+			tsv.SetAggregatorPeers(peers)
+			return peers
+		}
+		tsv.logger.Sugar().Warnw("Failed to refresh aggregator peers, using the last fetched peers",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.Error(err),
+		)
+	}
+	tsv.mu.RLock()
+	defer tsv.mu.RUnlock()
+	return tsv.aggregatorPeers
+}
+
+func (tsv *TaskSignatureValidator) ValidateTaskSignature(ctx context.Context, t *performerTask.PerformerTask) error {
+	sig, err := tsv.signingScheme.NewSignatureFromBytes(t.Signature)
+	if err != nil {
+		tsv.logger.Sugar().Errorw("Failed to create signature from bytes",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	peer := util.Find(tsv.refreshAggregatorPeers(ctx), func(p *peering.OperatorPeerInfo) bool {
+		return strings.EqualFold(p.OperatorAddress, t.AggregatorAddress)
+	})
+	if peer == nil {
+		tsv.logger.Sugar().Errorw("Failed to find peer for task",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.String("aggregatorAddress", t.AggregatorAddress),
+		)
+		return fmt.Errorf("failed to find peer for task")
+	}
+
+	if peer.CurveType != "" && !strings.EqualFold(peer.CurveType, tsv.signingCurve) {
+		tsv.logger.Sugar().Errorw("Aggregator peer uses a different signing curve",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.String("aggregatorAddress", t.AggregatorAddress),
+			zap.String("peerCurve", peer.CurveType),
+			zap.String("signingCurve", tsv.signingCurve),
+		)
+		return fmt.Errorf("aggregator peer curve '%s' does not match signing curve '%s'", peer.CurveType, tsv.signingCurve)
+	}
+	peerPubKey, err := peer.GetPublicKeyForScheme(tsv.signingScheme)
+	if err != nil {
+		tsv.logger.Sugar().Errorw("Failed to parse aggregator public key",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.String("aggregatorAddress", t.AggregatorAddress),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	verfied, err := sig.Verify(peerPubKey, t.Payload)
+	if err != nil {
+		tsv.logger.Sugar().Errorw("Failed to verify signature",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.String("aggregatorAddress", t.AggregatorAddress),
+			zap.Error(err),
+		)
+		return err
+	}
+	if !verfied {
+		tsv.logger.Sugar().Errorw("Failed to verify signature",
+			zap.String("avsAddress", tsv.avsAddress),
+			zap.String("aggregatorAddress", t.AggregatorAddress),
+		)
+		return fmt.Errorf("failed to verify signature")
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package avsPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering/cachedPeeringDataFetcher"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/keystore"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rotatingPeeringDataFetcher returns whatever aggregator peers were set last
+type rotatingPeeringDataFetcher struct {
+	mu    sync.Mutex
+	peers []*peering.OperatorPeerInfo
+}
+
+func (f *rotatingPeeringDataFetcher) setPeers(peers []*peering.OperatorPeerInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.peers = peers
+}
+
+func (f *rotatingPeeringDataFetcher) ListExecutorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	return nil, nil
+}
+
+func (f *rotatingPeeringDataFetcher) ListAggregatorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.peers, nil
+}
+
+func Test_TaskSignatureValidator(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	aggregatorAddress := "0xaggregator"
+	payload := []byte("payload")
+
+	for _, curve := range []string{"bn254", "bls381"} {
+		t.Run(fmt.Sprintf("validates aggregator signatures using %s", curve), func(t *testing.T) {
+			scheme, err := keystore.GetSigningSchemeForCurveType(curve)
+			if err != nil {
+				t.Fatalf("Failed to get signing scheme: %v", err)
+			}
+			privKey, pubKey, err := scheme.GenerateKeyPair()
+			if err != nil {
+				t.Fatalf("Failed to generate key pair: %v", err)
+			}
+			sig, err := privKey.Sign(payload)
+			if err != nil {
+				t.Fatalf("Failed to sign payload: %v", err)
+			}
+
+			tsv, err := NewTaskSignatureValidator("0xavs1", curve, nil, l)
+			if err != nil {
+				t.Fatalf("Failed to create signature validator: %v", err)
+			}
+			tsv.SetAggregatorPeers([]*peering.OperatorPeerInfo{
+				{OperatorAddress: aggregatorAddress, PublicKey: pubKey, CurveType: curve},
+			})
+
+			assert.Nil(t, tsv.ValidateTaskSignature(context.Background(), &performerTask.PerformerTask{
+				AggregatorAddress: aggregatorAddress,
+				Payload:           payload,
+				Signature:         sig.Bytes(),
+			}))
+			assert.NotNil(t, tsv.ValidateTaskSignature(context.Background(), &performerTask.PerformerTask{
+				AggregatorAddress: aggregatorAddress,
+				Payload:           []byte("tampered payload"),
+				Signature:         sig.Bytes(),
+			}))
+		})
+	}
+
+	t.Run("rejects peers registered with a different curve", func(t *testing.T) {
+		scheme, err := keystore.GetSigningSchemeForCurveType("bn254")
+		if err != nil {
+			t.Fatalf("Failed to get signing scheme: %v", err)
+		}
+		privKey, pubKey, err := scheme.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("Failed to generate key pair: %v", err)
+		}
+		sig, err := privKey.Sign(payload)
+		if err != nil {
+			t.Fatalf("Failed to sign payload: %v", err)
+		}
+
+		tsv, err := NewTaskSignatureValidator("0xavs1", "bls381", nil, l)
+		if err != nil {
+			t.Fatalf("Failed to create signature validator: %v", err)
+		}
+		tsv.SetAggregatorPeers([]*peering.OperatorPeerInfo{
+			{OperatorAddress: aggregatorAddress, PublicKey: pubKey, CurveType: "bn254"},
+		})
+
+		assert.NotNil(t, tsv.ValidateTaskSignature(context.Background(), &performerTask.PerformerTask{
+			AggregatorAddress: aggregatorAddress,
+			Payload:           payload,
+			Signature:         sig.Bytes(),
+		}))
+	})
+
+	t.Run("fails to create a validator for an unsupported curve", func(t *testing.T) {
+		_, err := NewTaskSignatureValidator("0xavs1", "secp256k1", nil, l)
+		assert.NotNil(t, err)
+	})
+	t.Run("accepts a rotated aggregator key once the cached peers are refreshed", func(t *testing.T) {
+		scheme, err := keystore.GetSigningSchemeForCurveType("bn254")
+		if err != nil {
+			t.Fatalf("Failed to get signing scheme: %v", err)
+		}
+		oldPrivKey, oldPubKey, err := scheme.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("Failed to generate key pair: %v", err)
+		}
+		newPrivKey, newPubKey, err := scheme.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("Failed to generate key pair: %v", err)
+		}
+		oldSig, err := oldPrivKey.Sign(payload)
+		if err != nil {
+			t.Fatalf("Failed to sign payload: %v", err)
+		}
+		newSig, err := newPrivKey.Sign(payload)
+		if err != nil {
+			t.Fatalf("Failed to sign payload: %v", err)
+		}
+
+		fetcher := &rotatingPeeringDataFetcher{}
+		fetcher.setPeers([]*peering.OperatorPeerInfo{
+			{OperatorAddress: aggregatorAddress, PublicKey: oldPubKey, CurveType: "bn254"},
+		})
+		cachedFetcher := cachedPeeringDataFetcher.NewCachedPeeringDataFetcher(fetcher, &cachedPeeringDataFetcher.CachedPeeringDataFetcherConfig{
+			RefreshInterval: 50 * time.Millisecond,
+		}, l)
+
+		tsv, err := NewTaskSignatureValidator("0xavs1", "bn254", cachedFetcher, l)
+		if err != nil {
+			t.Fatalf("Failed to create signature validator: %v", err)
+		}
+		if err := tsv.FetchAggregatorPeers(context.Background()); err != nil {
+			t.Fatalf("Failed to fetch aggregator peers: %v", err)
+		}
+		newTask := &performerTask.PerformerTask{
+			AggregatorAddress: aggregatorAddress,
+			Payload:           payload,
+			Signature:         newSig.Bytes(),
+		}
+		assert.Nil(t, tsv.ValidateTaskSignature(context.Background(), &performerTask.PerformerTask{
+			AggregatorAddress: aggregatorAddress,
+			Payload:           payload,
+			Signature:         oldSig.Bytes(),
+		}))
+		assert.NotNil(t, tsv.ValidateTaskSignature(context.Background(), newTask))
+
+		fetcher.setPeers([]*peering.OperatorPeerInfo{
+			{OperatorAddress: aggregatorAddress, PublicKey: newPubKey, CurveType: "bn254"},
+		})
+		time.Sleep(100 * time.Millisecond)
+
+		assert.Nil(t, tsv.ValidateTaskSignature(context.Background(), newTask))
+	})
+}
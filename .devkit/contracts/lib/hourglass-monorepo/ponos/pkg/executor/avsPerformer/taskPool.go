@@ -0,0 +1,182 @@
+package avsPerformer
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
+	"go.uber.org/zap"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultTaskBacklogSize = 50
+
+	// drainTimeout is how long Drain waits for queued and in-flight tasks to finish
+	drainTimeout = 30 * time.Second
+)
+
+// ProcessTaskFunc executes a single task against a performer
+type ProcessTaskFunc func(ctx context.Context, task *performerTask.PerformerTask) (*performerTask.PerformerTaskResult, error)
+
+// TaskPool is a bounded backlog of tasks consumed by a fixed number of workers.
+// Results and failures are handed to the ReceiveTaskResponse callback as each task completes.
+type TaskPool struct {
+	avsAddress         string
+	workerCount        int
+	logger             *zap.Logger
+	processTask        ProcessTaskFunc
+	reportTaskResponse ReceiveTaskResponse
+
+	taskBacklog chan *performerTask.PerformerTask
+
+	// backlogMu guards taskBacklog so that no task is enqueued after it has been closed for draining
+	backlogMu sync.RWMutex
+	draining  bool
+
+	// workersWg tracks the worker goroutines consuming the taskBacklog
+	workersWg sync.WaitGroup
+
+	// inflightTasks is a gauge of the tasks currently being executed by the performer
+	inflightTasks atomic.Int64
+}
+
+func NewTaskPool(
+	avsAddress string,
+	workerCount int,
+	backlogSize int,
+	processTask ProcessTaskFunc,
+	reportTaskResponse ReceiveTaskResponse,
+	logger *zap.Logger,
+) *TaskPool {
+	if workerCount < 1 {
+		workerCount = 1
+	}
+	if backlogSize <= 0 {
+		backlogSize = DefaultTaskBacklogSize
+	}
+	return &TaskPool{
+		avsAddress:         avsAddress,
+		workerCount:        workerCount,
+		logger:             logger,
+		processTask:        processTask,
+		reportTaskResponse: reportTaskResponse,
+		taskBacklog:        make(chan *performerTask.PerformerTask, backlogSize),
+	}
+}
+
+// Start starts the workers. They run until the backlog is closed and drained by Drain.
+func (tp *TaskPool) Start(ctx context.Context) {
+	tp.logger.Sugar().Infow("Starting performer workers",
+		zap.String("avsAddress", tp.avsAddress),
+		zap.Int("workerCount", tp.workerCount),
+	)
+
+	// tasks that were already accepted should be allowed to finish while draining,
+	// so the worker context is detached from the cancellation of the parent context.
+	workerCtx := context.WithoutCancel(ctx)
+	for i := 0; i < tp.workerCount; i++ {
+		tp.workersWg.Add(1)
+		go tp.runWorker(workerCtx, i)
+	}
+}
+
+func (tp *TaskPool) runWorker(ctx context.Context, workerId int) {
+	defer tp.workersWg.Done()
+	tp.logger.Sugar().Infow("Waiting for tasks",
+		zap.String("avs", tp.avsAddress),
+		zap.Int("workerId", workerId),
+	)
+	for task := range tp.taskBacklog {
+		inflight := tp.inflightTasks.Add(1)
+		tp.logger.Sugar().Debugw("Worker picked up task",
+			zap.String("avsAddress", tp.avsAddress),
+			zap.String("taskId", task.TaskID),
+			zap.Int("workerId", workerId),
+			zap.Int64("inflightTasks", inflight),
+		)
+
+		res, err := tp.processTask(ctx, task)
+		tp.inflightTasks.Add(-1)
+		if err != nil {
+			tp.logger.Sugar().Errorw("Failed to process task",
+				zap.String("avsAddress", tp.avsAddress),
+				zap.String("taskId", task.TaskID),
+				zap.Error(err),
+			)
+		}
+		// failures are reported too, so that the executor stops treating the task as running
+		tp.reportTaskResponse(task, res, err)
+	}
+	tp.logger.Sugar().Infow("Task backlog closed, worker exiting",
+		zap.String("avs", tp.avsAddress),
+		zap.Int("workerId", workerId),
+	)
+}
+
+// Submit adds a task to the backlog, failing if the backlog is full or the pool is draining
+func (tp *TaskPool) Submit(task *performerTask.PerformerTask) error {
+	tp.backlogMu.RLock()
+	defer tp.backlogMu.RUnlock()
+	if tp.draining {
+		return fmt.Errorf("performer for avs %s is shutting down", tp.avsAddress)
+	}
+
+	select {
+	case tp.taskBacklog <- task:
+		tp.logger.Sugar().Infow("PerformerTask added to backlog")
+	default:
+		tp.logger.Sugar().Infow("PerformerTask backlog is full, dropping task")
+		return fmt.Errorf("task backlog is full for avs %s", tp.avsAddress)
+	}
+	return nil
+}
+
+// InflightTasks returns the number of tasks currently being executed
+func (tp *TaskPool) InflightTasks() int64 {
+	return tp.inflightTasks.Load()
+}
+
+// QueuedTasks returns the number of tasks waiting in the backlog
+func (tp *TaskPool) QueuedTasks() int {
+	return len(tp.taskBacklog)
+}
+
+// Drain stops accepting new tasks and waits for the workers to finish everything
+// already in the backlog, giving up after drainTimeout.
+func (tp *TaskPool) Drain() {
+	tp.backlogMu.Lock()
+	if tp.draining {
+		tp.backlogMu.Unlock()
+		return
+	}
+	tp.draining = true
+	close(tp.taskBacklog)
+	tp.backlogMu.Unlock()
+
+	tp.logger.Sugar().Infow("Draining performer task backlog",
+		zap.String("avsAddress", tp.avsAddress),
+		zap.Int("queuedTasks", len(tp.taskBacklog)),
+		zap.Int64("inflightTasks", tp.inflightTasks.Load()),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		tp.workersWg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		tp.logger.Sugar().Infow("Performer task backlog drained",
+			zap.String("avsAddress", tp.avsAddress),
+		)
+	case <-time.After(drainTimeout):
+		tp.logger.Sugar().Warnw("Timed out waiting for performer task backlog to drain",
+			zap.String("avsAddress", tp.avsAddress),
+			zap.Int("queuedTasks", len(tp.taskBacklog)),
+			zap.Int64("inflightTasks", tp.inflightTasks.Load()),
+		)
+	}
+}
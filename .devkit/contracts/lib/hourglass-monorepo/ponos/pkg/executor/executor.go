@@ -3,19 +3,31 @@ package executor
 import (
 	"context"
 	"fmt"
+	aggregatorV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/hourglass/v1/aggregator"
 	executorV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/hourglass/v1/executor"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer/inProcessPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer/oneOffPerformer"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer/processPerformer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer/serverPerformer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/executorConfig"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/resultOutbox"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/signingHistory"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/worker"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/rpcServer"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signer"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 )
 
+// performerStatusLogInterval is how often the executor logs performers that are not ready
+const performerStatusLogInterval = 30 * time.Second
+
 type Executor struct {
 	logger        *zap.Logger
 	config        *executorConfig.ExecutorConfig
@@ -26,6 +38,18 @@ type Executor struct {
 	inflightTasks *sync.Map
 
 	peeringFetcher peering.IPeeringDataFetcher
+
+	// inProcessWorkers holds the workers registered for AVSs using the inprocess process type
+	inProcessWorkers map[string]worker.IContextWorker
+
+	// resultOutbox delivers signed task results to the aggregators, retrying until they are accepted
+	resultOutbox *resultOutbox.ResultOutbox
+
+	// signingHistory records every result digest we signed to prevent equivocation
+	signingHistory *signingHistory.SigningHistory
+
+	aggregatorClientsMu sync.Mutex
+	aggregatorClients   map[string]aggregatorV1.AggregatorServiceClient
 }
 
 func NewExecutor(
@@ -36,17 +60,74 @@ func NewExecutor(
 	peeringFetcher peering.IPeeringDataFetcher,
 ) *Executor {
 	return &Executor{
-		logger:         logger,
-		config:         config,
-		avsPerformers:  make(map[string]avsPerformer.IAvsPerformer),
-		rpcServer:      rpcServer,
-		signer:         signer,
-		inflightTasks:  &sync.Map{},
-		peeringFetcher: peeringFetcher,
+		logger:            logger,
+		config:            config,
+		avsPerformers:     make(map[string]avsPerformer.IAvsPerformer),
+		rpcServer:         rpcServer,
+		signer:            signer,
+		inflightTasks:     &sync.Map{},
+		peeringFetcher:    peeringFetcher,
+		inProcessWorkers:  make(map[string]worker.IContextWorker),
+		aggregatorClients: make(map[string]aggregatorV1.AggregatorServiceClient),
 	}
 }
 
+// RegisterInProcessWorker links a worker into the executor for an AVS configured with the
+// inprocess process type. It must be called before Initialize.
+func (e *Executor) RegisterInProcessWorker(avsAddress string, w worker.IWorker) {
+	e.RegisterInProcessContextWorker(avsAddress, worker.NewContextWorkerAdapter(w))
+}
+
+// RegisterInProcessContextWorker is the context-aware variant of RegisterInProcessWorker.
+func (e *Executor) RegisterInProcessContextWorker(avsAddress string, w worker.IContextWorker) {
+	e.inProcessWorkers[strings.ToLower(avsAddress)] = w
+}
+
+// newContainerOptions converts the container settings of an AVS performer config
+func newContainerOptions(avs *executorConfig.AvsPerformerConfig) avsPerformer.PerformerContainerOptions {
+	opts := avsPerformer.PerformerContainerOptions{
+		User:     avs.User,
+		Networks: avs.Networks,
+	}
+	if avs.Resources != nil {
+		opts.NanoCPUs = int64(avs.Resources.Cpus * 1e9)
+		opts.MemoryBytes = avs.Resources.MemoryMb * 1024 * 1024
+	}
+	for _, m := range avs.Mounts {
+		opts.Mounts = append(opts.Mounts, avsPerformer.PerformerMount{HostPath: m.HostPath, ContainerPath: m.ContainerPath})
+	}
+	return opts
+}
+
 func (e *Executor) Initialize() error {
+	outboxDir := ""
+	if e.config.DataDir != "" {
+		outboxDir = filepath.Join(e.config.DataDir, "outbox")
+	}
+	outbox, err := resultOutbox.NewResultOutbox(&resultOutbox.ResultOutboxConfig{
+		Dir: outboxDir,
+	}, e.submitTaskResult, e.logger)
+	if err != nil {
+		e.logger.Sugar().Errorw("Failed to create result outbox",
+			zap.String("dataDir", e.config.DataDir),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to create result outbox: %w", err)
+	}
+	e.resultOutbox = outbox
+
+	history, err := signingHistory.NewSigningHistory(&signingHistory.SigningHistoryConfig{
+		Dir: e.config.DataDir,
+	}, e.logger)
+	if err != nil {
+		e.logger.Sugar().Errorw("Failed to open signing history",
+			zap.String("dataDir", e.config.DataDir),
+			zap.Error(err),
+		)
+		return fmt.Errorf("failed to open signing history: %w", err)
+	}
+	e.signingHistory = history
+
 	e.logger.Sugar().Infow("Initializing AVS performers")
 
 	for _, avs := range e.config.AvsPerformers {
@@ -58,17 +139,36 @@ func (e *Executor) Initialize() error {
 			)
 		}
 
+		performerConfig := &avsPerformer.AvsPerformerConfig{
+			AvsAddress:           avsAddress,
+			ProcessType:          avsPerformer.AvsProcessType(avs.ProcessType),
+			WorkerCount:          avs.WorkerCount,
+			TaskBacklogSize:      avs.TaskBacklogSize,
+			TaskTimeout:          time.Duration(avs.TaskTimeoutSeconds) * time.Second,
+			PerformerNetworkName: e.config.PerformerNetworkName,
+			SigningCurve:         avs.SigningCurve,
+		}
+		if avs.Image != nil {
+			performerConfig.Image = avsPerformer.PerformerImage{Repository: avs.Image.Repository, Tag: avs.Image.Tag}
+		}
+		if avs.Process != nil {
+			performerConfig.Process = avsPerformer.PerformerProcess{Path: avs.Process.Path, Args: avs.Process.Args, Env: avs.Process.Env}
+		}
+		env, err := avs.ResolveEnv()
+		if err != nil {
+			e.logger.Sugar().Errorw("Failed to resolve AVS performer env",
+				zap.String("avsAddress", avsAddress),
+				zap.Error(err),
+			)
+			return fmt.Errorf("failed to resolve env for AVS performer %s: %w", avsAddress, err)
+		}
+		performerConfig.Env = env
+		performerConfig.Container = newContainerOptions(avs)
+
 		switch avs.ProcessType {
 		case string(avsPerformer.AvsProcessTypeServer):
 			performer, err := serverPerformer.NewAvsPerformerServer(
-				&avsPerformer.AvsPerformerConfig{
-					AvsAddress:           avsAddress,
-					ProcessType:          avsPerformer.AvsProcessType(avs.ProcessType),
-					Image:                avsPerformer.PerformerImage{Repository: avs.Image.Repository, Tag: avs.Image.Tag},
-					WorkerCount:          avs.WorkerCount,
-					PerformerNetworkName: e.config.PerformerNetworkName,
-					SigningCurve:         avs.SigningCurve,
-				},
+				performerConfig,
 				e.peeringFetcher,
 				e.receiveTaskResponse,
 				e.logger,
@@ -82,6 +182,55 @@ func (e *Executor) Initialize() error {
 			}
 			e.avsPerformers[avsAddress] = performer
 
+		case string(avsPerformer.AvsProcessTypeOneOff):
+			performer, err := oneOffPerformer.NewAvsPerformerOneOff(
+				performerConfig,
+				e.peeringFetcher,
+				e.receiveTaskResponse,
+				e.logger,
+			)
+			if err != nil {
+				e.logger.Sugar().Errorw("Failed to create one-off AVS performer",
+					zap.String("avsAddress", avsAddress),
+					zap.Error(err),
+				)
+				return fmt.Errorf("failed to create one-off AVS performer: %v", err)
+			}
+			e.avsPerformers[avsAddress] = performer
+
+		case string(avsPerformer.AvsProcessTypeProcess):
+			performer, err := processPerformer.NewAvsPerformerProcess(
+				performerConfig,
+				e.peeringFetcher,
+				e.receiveTaskResponse,
+				e.logger,
+			)
+			if err != nil {
+				e.logger.Sugar().Errorw("Failed to create AVS performer process",
+					zap.String("avsAddress", avsAddress),
+					zap.Error(err),
+				)
+				return fmt.Errorf("failed to create AVS performer process: %v", err)
+			}
+			e.avsPerformers[avsAddress] = performer
+
+		case string(avsPerformer.AvsProcessTypeInProcess):
+			performer, err := inProcessPerformer.NewAvsPerformerInProcess(
+				performerConfig,
+				e.inProcessWorkers[avsAddress],
+				e.peeringFetcher,
+				e.receiveTaskResponse,
+				e.logger,
+			)
+			if err != nil {
+				e.logger.Sugar().Errorw("Failed to create in-process AVS performer",
+					zap.String("avsAddress", avsAddress),
+					zap.Error(err),
+				)
+				return fmt.Errorf("failed to create in-process AVS performer: %v", err)
+			}
+			e.avsPerformers[avsAddress] = performer
+
 		default:
 			e.logger.Sugar().Errorw("Unsupported AVS performer process type",
 				zap.String("avsAddress", avsAddress),
@@ -102,6 +251,8 @@ func (e *Executor) Initialize() error {
 }
 
 func (e *Executor) BootPerformers(ctx context.Context) error {
+	e.resultOutbox.Start(ctx)
+
 	e.logger.Sugar().Infow("Booting AVS performers")
 	for avsAddress, performer := range e.avsPerformers {
 		if err := performer.Initialize(ctx); err != nil {
@@ -119,6 +270,7 @@ func (e *Executor) BootPerformers(ctx context.Context) error {
 			return fmt.Errorf("failed to process tasks: %v", err)
 		}
 	}
+	go e.logPerformerStatuses(ctx)
 	go func() {
 		<-ctx.Done()
 		e.logger.Sugar().Info("Shutting down AVS performers")
@@ -130,10 +282,56 @@ func (e *Executor) BootPerformers(ctx context.Context) error {
 				)
 			}
 		}
+		// performers are drained, so nothing is signed anymore
+		if err := e.signingHistory.Close(); err != nil {
+			e.logger.Sugar().Errorw("Failed to close signing history", zap.Error(err))
+		}
 	}()
 	return nil
 }
 
+// PerformerStatuses returns the status of every supervised AVS performer, keyed by AVS address
+func (e *Executor) PerformerStatuses() map[string]avsPerformer.PerformerStatus {
+	statuses := make(map[string]avsPerformer.PerformerStatus)
+	for avsAddress, performer := range e.avsPerformers {
+		if reporter, ok := performer.(avsPerformer.IPerformerStatusReporter); ok {
+			statuses[avsAddress] = reporter.Status()
+		}
+	}
+	return statuses
+}
+
+// logPerformerStatuses periodically logs the performers that are not ready, with their restart
+// and backoff state, until ctx is done
+func (e *Executor) logPerformerStatuses(ctx context.Context) {
+	ticker := time.NewTicker(performerStatusLogInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for avsAddress, status := range e.PerformerStatuses() {
+			if status.State == avsPerformer.PerformerStateReady {
+				continue
+			}
+			fields := []interface{}{
+				zap.String("avsAddress", avsAddress),
+				zap.String("state", string(status.State)),
+				zap.Int("restartCount", status.RestartCount),
+				zap.Duration("inStateFor", time.Since(status.Since)),
+				zap.String("lastError", status.LastError),
+			}
+			if !status.NextRestartAt.IsZero() {
+				fields = append(fields, zap.Duration("nextRestartIn", time.Until(status.NextRestartAt)))
+			}
+			e.logger.Sugar().Warnw("AVS performer is not ready", fields...)
+		}
+	}
+}
+
 func (e *Executor) Run(ctx context.Context) error {
 	e.logger.Info("Worker node is running", zap.String("version", "1.0.0"))
 	if err := e.rpcServer.Start(ctx); err != nil {
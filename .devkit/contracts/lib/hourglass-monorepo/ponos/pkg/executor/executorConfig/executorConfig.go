@@ -2,11 +2,18 @@ package executorConfig
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/avsPerformer"
 	"github.com/spf13/viper"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
 	"sigs.k8s.io/yaml"
 	"slices"
+	"strings"
 )
 
 const (
@@ -15,6 +22,7 @@ const (
 	Debug                = "debug"
 	GrpcPort             = "grpc-port"
 	PerformerNetworkName = "performer-network-name"
+	DataDir              = "data-dir"
 )
 
 type PerformerImage struct {
@@ -22,12 +30,175 @@ type PerformerImage struct {
 	Tag        string
 }
 
+// PerformerProcess configures the local performer binary run by the "process" process type
+type PerformerProcess struct {
+	// Path to the performer binary, e.g. ./bin/performer
+	Path string
+	Args []string
+
+	// Env holds additional KEY=VALUE environment variables for the process
+	Env []string
+}
+
+// PerformerEnv is an environment variable passed to the performer. The value is either set inline
+// or read from a file when the executor starts, so that secrets can be kept out of the config.
+type PerformerEnv struct {
+	Name          string
+	Value         string
+	ValueFromFile string
+}
+
+// Resolve returns the variable as KEY=VALUE, reading its value from ValueFromFile if set.
+// Trailing newlines are trimmed from values read from files.
+func (pe *PerformerEnv) Resolve() (string, error) {
+	if pe.ValueFromFile == "" {
+		return fmt.Sprintf("%s=%s", pe.Name, pe.Value), nil
+	}
+	value, err := os.ReadFile(pe.ValueFromFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read value of env %s: %w", pe.Name, err)
+	}
+	return fmt.Sprintf("%s=%s", pe.Name, strings.TrimRight(string(value), "\r\n")), nil
+}
+
+// PerformerResources limits the resources of the performer container
+type PerformerResources struct {
+	// Cpus is the number of CPUs the container may use, e.g. 0.5
+	Cpus     float64
+	MemoryMb int64
+}
+
+// PerformerMount mounts a host path read-only into the performer container
+type PerformerMount struct {
+	HostPath      string
+	ContainerPath string
+}
+
 type AvsPerformerConfig struct {
 	Image        *PerformerImage
+	Process      *PerformerProcess
 	ProcessType  string
 	AvsAddress   string
 	WorkerCount  int
 	SigningCurve string // bn254, bls381, etc
+
+	// TaskBacklogSize is the number of tasks that can be queued for the AVS while all workers are busy.
+	// Defaults to 50 when not set.
+	TaskBacklogSize int
+
+	// TaskTimeoutSeconds bounds how long a single task may take once it is picked up:
+	//   - one-off: starting the task's container, waiting for it to be ready and executing the
+	//     task. Time spent waiting for a free slot and tearing the container down is not counted.
+	//     Defaults to 60 when not set.
+	//   - inprocess: validating and handling the task in the worker. Unbounded when not set.
+	// The server and process types do not use it; their performers apply their own timeout.
+	TaskTimeoutSeconds int
+
+	// Env is passed to the performer container, or to the performer binary with the process type
+	Env []*PerformerEnv
+
+	// Resources, Mounts, User and Networks configure the performer container and are only
+	// supported by the server and one-off process types
+	Resources *PerformerResources
+	Mounts    []*PerformerMount
+	User      string
+	Networks  []string
+}
+
+// minMemoryMb is the smallest memory limit Docker accepts for a container
+const minMemoryMb = 6
+
+var (
+	envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	userRegex    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*(:[A-Za-z0-9_][A-Za-z0-9_.-]*)?$`)
+)
+
+// ResolveEnv returns the performer env as KEY=VALUE pairs, reading values from files where configured
+func (ap *AvsPerformerConfig) ResolveEnv() ([]string, error) {
+	env := make([]string, 0, len(ap.Env))
+	for _, e := range ap.Env {
+		resolved, err := e.Resolve()
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, resolved)
+	}
+	return env, nil
+}
+
+func (ap *AvsPerformerConfig) validateEnv() field.ErrorList {
+	var allErrors field.ErrorList
+	names := map[string]bool{}
+	for i, env := range ap.Env {
+		envPath := field.NewPath("env").Index(i)
+		if env == nil {
+			allErrors = append(allErrors, field.Required(envPath, "env entry must not be empty"))
+			continue
+		}
+		if env.Name == "" {
+			allErrors = append(allErrors, field.Required(envPath.Child("name"), "name is required"))
+		} else if !envNameRegex.MatchString(env.Name) {
+			allErrors = append(allErrors, field.Invalid(envPath.Child("name"), env.Name, "name must consist of letters, digits and underscores and not start with a digit"))
+		} else if names[env.Name] {
+			allErrors = append(allErrors, field.Duplicate(envPath.Child("name"), env.Name))
+		}
+		names[env.Name] = true
+
+		if env.ValueFromFile != "" {
+			if env.Value != "" {
+				allErrors = append(allErrors, field.Invalid(envPath, env.Name, "only one of value and valueFromFile may be set"))
+			} else if info, err := os.Stat(env.ValueFromFile); err != nil {
+				allErrors = append(allErrors, field.Invalid(envPath.Child("valueFromFile"), env.ValueFromFile, fmt.Sprintf("file can not be read: %v", err)))
+			} else if info.IsDir() {
+				allErrors = append(allErrors, field.Invalid(envPath.Child("valueFromFile"), env.ValueFromFile, "valueFromFile must be a file"))
+			}
+		}
+	}
+	return allErrors
+}
+
+func (ap *AvsPerformerConfig) validateContainerOptions() field.ErrorList {
+	var allErrors field.ErrorList
+	if ap.Resources != nil {
+		if ap.Resources.Cpus < 0 {
+			allErrors = append(allErrors, field.Invalid(field.NewPath("resources.cpus"), ap.Resources.Cpus, "cpus must not be negative"))
+		}
+		if ap.Resources.MemoryMb < 0 || (ap.Resources.MemoryMb > 0 && ap.Resources.MemoryMb < minMemoryMb) {
+			allErrors = append(allErrors, field.Invalid(field.NewPath("resources.memoryMb"), ap.Resources.MemoryMb, fmt.Sprintf("memoryMb must be 0 (unlimited) or at least %d", minMemoryMb)))
+		}
+	}
+	for i, m := range ap.Mounts {
+		mountPath := field.NewPath("mounts").Index(i)
+		if m == nil {
+			allErrors = append(allErrors, field.Required(mountPath, "mount must not be empty"))
+			continue
+		}
+		if m.HostPath == "" {
+			allErrors = append(allErrors, field.Required(mountPath.Child("hostPath"), "hostPath is required"))
+		} else if !filepath.IsAbs(m.HostPath) {
+			allErrors = append(allErrors, field.Invalid(mountPath.Child("hostPath"), m.HostPath, "hostPath must be an absolute path"))
+		}
+		if m.ContainerPath == "" {
+			allErrors = append(allErrors, field.Required(mountPath.Child("containerPath"), "containerPath is required"))
+		} else if !path.IsAbs(m.ContainerPath) {
+			allErrors = append(allErrors, field.Invalid(mountPath.Child("containerPath"), m.ContainerPath, "containerPath must be an absolute path"))
+		}
+	}
+	if ap.User != "" && !userRegex.MatchString(ap.User) {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("user"), ap.User, "user must be in the form user, uid, user:group or uid:gid"))
+	}
+	for i, network := range ap.Networks {
+		if network == "" {
+			allErrors = append(allErrors, field.Required(field.NewPath("networks").Index(i), "network name must not be empty"))
+		} else if slices.Contains(ap.Networks[:i], network) {
+			allErrors = append(allErrors, field.Duplicate(field.NewPath("networks").Index(i), network))
+		}
+	}
+	return allErrors
+}
+
+func (ap *AvsPerformerConfig) hasContainerOptions() bool {
+	return ap.Resources != nil || len(ap.Mounts) > 0 || ap.User != "" || len(ap.Networks) > 0
 }
 
 func (ap *AvsPerformerConfig) Validate() error {
@@ -35,14 +206,35 @@ func (ap *AvsPerformerConfig) Validate() error {
 	if ap.AvsAddress == "" {
 		allErrors = append(allErrors, field.Required(field.NewPath("avsAddress"), "avsAddress is required"))
 	}
-	if ap.Image == nil {
-		allErrors = append(allErrors, field.Required(field.NewPath("image"), "image is required"))
-	} else {
-		if ap.Image.Repository == "" {
-			allErrors = append(allErrors, field.Required(field.NewPath("image.repository"), "image.repository is required"))
+	switch ap.ProcessType {
+	case string(avsPerformer.AvsProcessTypeInProcess):
+		// the worker is registered with the executor in code, so there is nothing to run
+		if len(ap.Env) > 0 {
+			allErrors = append(allErrors, field.Forbidden(field.NewPath("env"), "env is not supported by the inprocess process type"))
 		}
-		if ap.Image.Tag == "" {
-			allErrors = append(allErrors, field.Required(field.NewPath("image.tag"), "image.tag is required"))
+	case string(avsPerformer.AvsProcessTypeProcess):
+		if ap.Process == nil {
+			allErrors = append(allErrors, field.Required(field.NewPath("process"), "process is required for the process type"))
+		} else {
+			if ap.Process.Path == "" {
+				allErrors = append(allErrors, field.Required(field.NewPath("process.path"), "process.path is required"))
+			}
+			for i, env := range ap.Process.Env {
+				if !strings.Contains(env, "=") {
+					allErrors = append(allErrors, field.Invalid(field.NewPath("process.env").Index(i), env, "env must be in the form KEY=VALUE"))
+				}
+			}
+		}
+	default:
+		if ap.Image == nil {
+			allErrors = append(allErrors, field.Required(field.NewPath("image"), "image is required"))
+		} else {
+			if ap.Image.Repository == "" {
+				allErrors = append(allErrors, field.Required(field.NewPath("image.repository"), "image.repository is required"))
+			}
+			if ap.Image.Tag == "" {
+				allErrors = append(allErrors, field.Required(field.NewPath("image.tag"), "image.tag is required"))
+			}
 		}
 	}
 	if ap.SigningCurve == "" {
@@ -53,6 +245,33 @@ func (ap *AvsPerformerConfig) Validate() error {
 
 	if ap.WorkerCount == 0 {
 		allErrors = append(allErrors, field.Required(field.NewPath("workerCount"), "workerCount is required"))
+	} else if ap.WorkerCount < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("workerCount"), ap.WorkerCount, "workerCount must be greater than 0"))
+	}
+	if ap.TaskBacklogSize < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("taskBacklogSize"), ap.TaskBacklogSize, "taskBacklogSize must not be negative"))
+	}
+	if ap.ProcessType == "" {
+		allErrors = append(allErrors, field.Required(field.NewPath("processType"), "processType is required"))
+	} else if !slices.Contains([]string{
+		string(avsPerformer.AvsProcessTypeServer),
+		string(avsPerformer.AvsProcessTypeOneOff),
+		string(avsPerformer.AvsProcessTypeProcess),
+		string(avsPerformer.AvsProcessTypeInProcess),
+	}, ap.ProcessType) {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("processType"), ap.ProcessType, "processType must be one of [server, one-off, process, inprocess]"))
+	}
+	if ap.TaskTimeoutSeconds < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("taskTimeoutSeconds"), ap.TaskTimeoutSeconds, "taskTimeoutSeconds must not be negative"))
+	}
+	allErrors = append(allErrors, ap.validateEnv()...)
+	if ap.hasContainerOptions() {
+		switch ap.ProcessType {
+		case string(avsPerformer.AvsProcessTypeProcess), string(avsPerformer.AvsProcessTypeInProcess):
+			allErrors = append(allErrors, field.Forbidden(field.NewPath("processType"), "resources, mounts, user and networks are only supported by the server and one-off process types"))
+		default:
+			allErrors = append(allErrors, ap.validateContainerOptions()...)
+		}
 	}
 	if len(allErrors) > 0 {
 		return allErrors.ToAggregate()
@@ -64,6 +283,25 @@ type SimulationConfig struct {
 	SimulatePeering *config.SimulatedPeeringConfig `json:"simulatePeering" yaml:"simulatePeering"`
 }
 
+// L1ChainConfig is the chain the executor reads operator peering data from
+type L1ChainConfig struct {
+	ChainId config.ChainId `json:"chainId" yaml:"chainId"`
+	RpcUrl  string         `json:"rpcUrl" yaml:"rpcUrl"`
+}
+
+func (lc *L1ChainConfig) Validate() field.ErrorList {
+	var allErrors field.ErrorList
+	if lc.ChainId == 0 {
+		allErrors = append(allErrors, field.Required(field.NewPath("l1Chain.chainId"), "chainId is required"))
+	} else if !slices.Contains(config.SupportedChainIds, lc.ChainId) {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("l1Chain.chainId"), lc.ChainId, "unsupported chainId"))
+	}
+	if lc.RpcUrl == "" {
+		allErrors = append(allErrors, field.Required(field.NewPath("l1Chain.rpcUrl"), "rpcUrl is required"))
+	}
+	return allErrors
+}
+
 type ExecutorConfig struct {
 	Debug                bool
 	GrpcPort             int                    `json:"grpcPort" yaml:"grpcPort"`
@@ -71,6 +309,28 @@ type ExecutorConfig struct {
 	Operator             *config.OperatorConfig `json:"operator" yaml:"operator"`
 	AvsPerformers        []*AvsPerformerConfig  `json:"avsPerformers" yaml:"avsPerformers"`
 	Simulation           *SimulationConfig      `json:"simulation" yaml:"simulation"`
+
+	// DataDir is where the executor keeps state that must survive restarts, such as task results
+	// that have not been delivered to the aggregator yet. When empty, that state is kept in memory.
+	DataDir string `json:"dataDir" yaml:"dataDir"`
+
+	// L1Chain is required unless peering is simulated
+	L1Chain *L1ChainConfig `json:"l1Chain" yaml:"l1Chain"`
+
+	// AvsRegistrars override the TaskAVSRegistrar peering data is read from for an AVS on a chain.
+	// AVSs without an entry for the L1 chain use the registrar found in the contract store.
+	AvsRegistrars []*config.AvsRegistrarConfig `json:"avsRegistrars" yaml:"avsRegistrars"`
+
+	// PeeringRefreshIntervalSeconds is how long peers read from the chain are cached. Defaults to 60.
+	PeeringRefreshIntervalSeconds int `json:"peeringRefreshIntervalSeconds" yaml:"peeringRefreshIntervalSeconds"`
+
+	// Contracts is an optional field to override the addresses and ABIs for the core contracts that are loaded
+	Contracts json.RawMessage `json:"contracts" yaml:"contracts"`
+}
+
+// IsPeeringSimulated returns true if peers come from the simulation config instead of the chain
+func (ec *ExecutorConfig) IsPeeringSimulated() bool {
+	return ec.Simulation != nil && ec.Simulation.SimulatePeering != nil && ec.Simulation.SimulatePeering.Enabled
 }
 
 func (ec *ExecutorConfig) Validate() error {
@@ -83,6 +343,20 @@ func (ec *ExecutorConfig) Validate() error {
 		}
 	}
 
+	if !ec.IsPeeringSimulated() {
+		if ec.L1Chain == nil {
+			allErrors = append(allErrors, field.Required(field.NewPath("l1Chain"), "l1Chain is required unless peering is simulated"))
+		} else {
+			allErrors = append(allErrors, ec.L1Chain.Validate()...)
+		}
+	}
+	for i, registrar := range ec.AvsRegistrars {
+		allErrors = append(allErrors, registrar.Validate(field.NewPath("avsRegistrars").Index(i))...)
+	}
+	if ec.PeeringRefreshIntervalSeconds < 0 {
+		allErrors = append(allErrors, field.Invalid(field.NewPath("peeringRefreshIntervalSeconds"), ec.PeeringRefreshIntervalSeconds, "peeringRefreshIntervalSeconds must not be negative"))
+	}
+
 	if len(ec.AvsPerformers) == 0 {
 		allErrors = append(allErrors, field.Required(field.NewPath("avss"), "at least one AVS performer is required"))
 	} else {
@@ -102,6 +376,7 @@ func NewExecutorConfig() *ExecutorConfig {
 	return &ExecutorConfig{
 		Debug:    viper.GetBool(config.NormalizeFlagName(Debug)),
 		GrpcPort: viper.GetInt(config.NormalizeFlagName(GrpcPort)),
+		DataDir:  viper.GetString(config.NormalizeFlagName(DataDir)),
 		// PerformerNetworkName: viper.GetString(config.NormalizeFlagName(PerformerNetworkName)),
 	}
 }
@@ -1,7 +1,11 @@
 package executorConfig
 
 import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -48,6 +52,157 @@ func Test_ExecutorConfig(t *testing.T) {
 	})
 }
 
+func Test_ExecutorConfigPeering(t *testing.T) {
+	t.Run("Should require an L1 chain unless peering is simulated", func(t *testing.T) {
+		ec, err := NewExecutorConfigFromYamlBytes([]byte(yamlValid))
+		assert.Nil(t, err)
+		err = ec.Validate()
+		assert.ErrorContains(t, err, "l1Chain")
+
+		ec.Simulation = &SimulationConfig{SimulatePeering: &config.SimulatedPeeringConfig{Enabled: true}}
+		err = ec.Validate()
+		if err != nil {
+			assert.NotContains(t, err.Error(), "l1Chain")
+		}
+	})
+	t.Run("Should validate the L1 chain", func(t *testing.T) {
+		ec, err := NewExecutorConfigFromYamlBytes([]byte(yamlValid + `
+l1Chain:
+  chainId: 12345
+peeringRefreshIntervalSeconds: -1
+`))
+		assert.Nil(t, err)
+		err = ec.Validate()
+		assert.ErrorContains(t, err, "l1Chain.chainId")
+		assert.ErrorContains(t, err, "l1Chain.rpcUrl")
+		assert.ErrorContains(t, err, "peeringRefreshIntervalSeconds")
+
+		ec.L1Chain = &L1ChainConfig{ChainId: config.ChainId_EthereumMainnet, RpcUrl: "http://localhost:8545"}
+		ec.PeeringRefreshIntervalSeconds = 30
+		err = ec.Validate()
+		if err != nil {
+			assert.NotContains(t, err.Error(), "l1Chain")
+			assert.NotContains(t, err.Error(), "peeringRefreshIntervalSeconds")
+		}
+	})
+}
+
+func Test_AvsPerformerContainerConfig(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "api-key")
+	if err := os.WriteFile(secretFile, []byte("s3cr3t\n"), 0600); err != nil {
+		t.Fatalf("Failed to write secret file: %v", err)
+	}
+
+	parsePerformer := func(t *testing.T, performerYaml string) *AvsPerformerConfig {
+		ec, err := NewExecutorConfigFromYamlBytes([]byte(fmt.Sprintf("avsPerformers:\n%s", performerYaml)))
+		if err != nil {
+			t.Fatalf("Failed to parse config: %v", err)
+		}
+		return ec.AvsPerformers[0]
+	}
+
+	t.Run("Should parse and validate container options", func(t *testing.T) {
+		ap := parsePerformer(t, fmt.Sprintf(`
+- image:
+    repository: "eigenlabs/avs"
+    tag: "v1.0.0"
+  processType: "server"
+  avsAddress: "0xavs1..."
+  workerCount: 1
+  signingCurve: "bn254"
+  env:
+    - name: "LOG_LEVEL"
+      value: "debug"
+    - name: "API_KEY"
+      valueFromFile: "%s"
+  resources:
+    cpus: 1.5
+    memoryMb: 512
+  mounts:
+    - hostPath: "/var/lib/avs"
+      containerPath: "/data"
+  user: "1000:1000"
+  networks:
+    - "avs-db"
+`, secretFile))
+		assert.Nil(t, ap.Validate())
+		assert.Equal(t, 1.5, ap.Resources.Cpus)
+		assert.Equal(t, int64(512), ap.Resources.MemoryMb)
+		assert.Equal(t, "/data", ap.Mounts[0].ContainerPath)
+		assert.Equal(t, "1000:1000", ap.User)
+		assert.Equal(t, []string{"avs-db"}, ap.Networks)
+
+		env, err := ap.ResolveEnv()
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"LOG_LEVEL=debug", "API_KEY=s3cr3t"}, env)
+	})
+
+	t.Run("Should reject invalid container options", func(t *testing.T) {
+		ap := parsePerformer(t, `
+- image:
+    repository: "eigenlabs/avs"
+    tag: "v1.0.0"
+  processType: "server"
+  avsAddress: "0xavs1..."
+  workerCount: 1
+  signingCurve: "bn254"
+  env:
+    - name: "1INVALID"
+      value: "x"
+    - name: "API_KEY"
+      value: "inline"
+      valueFromFile: "/does/not/matter"
+    - name: "API_KEY"
+      valueFromFile: "/does/not/exist"
+  resources:
+    cpus: -1
+    memoryMb: 1
+  mounts:
+    - hostPath: "relative/path"
+      containerPath: ""
+  user: "root:"
+  networks:
+    - "avs-db"
+    - "avs-db"
+`)
+		err := ap.Validate()
+		assert.NotNil(t, err)
+		for _, field := range []string{
+			"env[0].name",
+			"env[1]",
+			"env[2].name",
+			"env[2].valueFromFile",
+			"resources.cpus",
+			"resources.memoryMb",
+			"mounts[0].hostPath",
+			"mounts[0].containerPath",
+			"user",
+			"networks[1]",
+		} {
+			assert.Contains(t, err.Error(), field)
+		}
+	})
+
+	t.Run("Should reject container options for the process type", func(t *testing.T) {
+		ap := parsePerformer(t, `
+- process:
+    path: "./bin/performer"
+  processType: "process"
+  avsAddress: "0xavs1..."
+  workerCount: 1
+  signingCurve: "bn254"
+  env:
+    - name: "LOG_LEVEL"
+      value: "debug"
+  user: "1000"
+`)
+		err := ap.Validate()
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "only supported by the server and one-off process types")
+		assert.NotContains(t, err.Error(), "env")
+	})
+}
+
 const (
 	yamlValid = `
 ---
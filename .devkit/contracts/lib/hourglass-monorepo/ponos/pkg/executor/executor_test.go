@@ -17,6 +17,7 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/keystore"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/simulations/simulatedAggregator"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 	"math/big"
@@ -25,7 +26,35 @@ import (
 	"time"
 )
 
+type echoWorker struct{}
+
+func (w *echoWorker) ValidateTask(task *performerV1.TaskRequest) error {
+	return nil
+}
+
+func (w *echoWorker) HandleTask(task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	return &performerV1.TaskResponse{TaskId: task.TaskId, Result: task.Payload}, nil
+}
+
 func Test_Executor(t *testing.T) {
+	runExecutorTest(t, executorConfigYaml, 5678, 5*time.Second, nil)
+}
+
+// Test_ExecutorInProcess runs the full executor flow with a worker linked in-process,
+// so it needs neither Docker nor a performer binary.
+func Test_ExecutorInProcess(t *testing.T) {
+	runExecutorTest(t, inProcessExecutorConfigYaml, 5679, 500*time.Millisecond, func(exec *Executor) {
+		exec.RegisterInProcessWorker("0xavs1...", &echoWorker{})
+	})
+}
+
+func runExecutorTest(
+	t *testing.T,
+	executorYaml string,
+	simAggPort int,
+	startupDelay time.Duration,
+	registerWorkers func(exec *Executor),
+) {
 	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(15*time.Second))
 
 	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
@@ -34,7 +63,7 @@ func Test_Executor(t *testing.T) {
 	}
 
 	// executor setup
-	execConfig, err := executorConfig.NewExecutorConfigFromYamlBytes([]byte(executorConfigYaml))
+	execConfig, err := executorConfig.NewExecutorConfigFromYamlBytes([]byte(executorYaml))
 	if err != nil {
 		t.Fatalf("failed to create executor config: %v", err)
 	}
@@ -80,6 +109,7 @@ func Test_Executor(t *testing.T) {
 			{
 				OperatorAddress: simAggConfig.Operator.Address,
 				PublicKey:       pubKey,
+				CurveType:       "bn254",
 				OperatorSetIds:  []uint32{0},
 				NetworkAddress:  "localhost",
 			},
@@ -87,6 +117,9 @@ func Test_Executor(t *testing.T) {
 	}, l)
 
 	exec := NewExecutor(execConfig, baseRpcServer, l, execSigner, pdf)
+	if registerWorkers != nil {
+		registerWorkers(exec)
+	}
 
 	if err := exec.Initialize(); err != nil {
 		t.Fatalf("Failed to initialize executor: %v", err)
@@ -99,7 +132,6 @@ func Test_Executor(t *testing.T) {
 	// ------------------------------------------------------------------------
 	// aggregator sim setup
 	// ------------------------------------------------------------------------
-	simAggPort := 5678
 	aggBaseRpcServer, err := rpcServer.NewRpcServer(&rpcServer.RpcServerConfig{
 		GrpcPort: simAggPort,
 	}, l)
@@ -165,7 +197,7 @@ func Test_Executor(t *testing.T) {
 	}()
 
 	// give containers time to start.
-	time.Sleep(5 * time.Second)
+	time.Sleep(startupDelay)
 
 	payloadJsonBytes := util.BigIntToHex(new(big.Int).SetUint64(4))
 	payloadSig, err := aggSigner.SignMessage(payloadJsonBytes)
@@ -246,6 +278,45 @@ avsPerformers:
   signingCurve: "bn254"
 `
 
+	inProcessExecutorConfigYaml = `
+---
+grpcPort: 9091
+operator:
+  address: "0xoperator..."
+  operatorPrivateKey: "..."
+  signingKeys:
+    bls:
+      keystore: |
+        {
+          "publicKey": "2d6b7590f1fea33186b11a795b5a6c5c77b3ebdd5563ad11404098c8e4d92a8209e5d2e5fd537eb2c253a9d13735935079bcb8902f09bbd7a117d07f3142d5f9039ca163db601221d77db55b0fe3876aab1ff8bdf90a205f60cb244633789f0020d166cd401deed5dcac545ae8d58ba6e024b7aa626c51ef74b23ef5fa170ba4",
+          "crypto": {
+            "cipher": "aes-128-ctr",
+            "ciphertext": "de8e36c294f88c582d0f84ebadef0470b38dfd6209597e3f71013d780d033105",
+            "cipherparams": {
+              "iv": "780729b623bea9237293d11d949c6790"
+            },
+            "kdf": "scrypt",
+            "kdfparams": {
+              "dklen": 32,
+              "n": 262144,
+              "p": 1,
+              "r": 8,
+              "salt": "fc621449564675b56cfa22785b8fa362e63666a4f834e86f33683e5ccef700c2"
+            },
+            "mac": "a9e8175072147ef23ee6742aaeb96b4da0003a84925f1e74b78bedf4c6f8fd8a"
+          },
+          "uuid": "7c5feddd-b78f-404a-8548-7f84eac102e1",
+          "version": 4,
+          "curveType": "bn254"
+        }
+      password: ""
+avsPerformers:
+- processType: "inprocess"
+  avsAddress: "0xavs1..."
+  workerCount: 1
+  signingCurve: "bn254"
+`
+
 	aggregatorConfigYaml = `
 ---
 chains:
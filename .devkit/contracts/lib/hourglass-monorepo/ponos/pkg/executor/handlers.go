@@ -7,16 +7,21 @@ import (
 	aggregatorV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/hourglass/v1/aggregator"
 	executorV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/hourglass/v1/executor"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/aggregatorClient"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/executor/resultOutbox"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performerTask"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"strings"
+	"time"
 )
 
+// resultDeliveryWindow is how long the executor keeps trying to deliver a task result to the aggregator
+const resultDeliveryWindow = 10 * time.Minute
+
 func (e *Executor) SubmitTask(ctx context.Context, req *executorV1.TaskSubmission) (*commonV1.SubmitAck, error) {
-	err := e.handleReceivedTask(req)
+	err := e.handleReceivedTask(ctx, req)
 	if err != nil {
 		e.logger.Sugar().Errorw("Failed to handle received task",
 			"taskId", req.TaskId,
@@ -28,7 +33,7 @@ func (e *Executor) SubmitTask(ctx context.Context, req *executorV1.TaskSubmissio
 	return &commonV1.SubmitAck{Message: "Scheduled task", Success: true}, nil
 }
 
-func (e *Executor) handleReceivedTask(task *executorV1.TaskSubmission) error {
+func (e *Executor) handleReceivedTask(ctx context.Context, task *executorV1.TaskSubmission) error {
 	e.logger.Sugar().Infow("Received task from AVS avsPerformer",
 		"taskId", task.TaskId,
 		"avsAddress", task.AvsAddress,
@@ -37,6 +42,9 @@ func (e *Executor) handleReceivedTask(task *executorV1.TaskSubmission) error {
 	if avsAddress == "" {
 		return fmt.Errorf("AVS address is empty")
 	}
+	if deadline := task.GetDeadlineUnixSeconds(); deadline != 0 && time.Now().Unix() >= deadline {
+		return fmt.Errorf("task deadline %s has passed", time.Unix(deadline, 0).UTC().Format(time.RFC3339))
+	}
 
 	avsPerformer, ok := e.avsPerformers[task.AvsAddress]
 	if !ok {
@@ -45,14 +53,23 @@ func (e *Executor) handleReceivedTask(task *executorV1.TaskSubmission) error {
 
 	pt := performerTask.NewPerformerTaskFromTaskSubmissionProto(task)
 
-	if err := avsPerformer.ValidateTaskSignature(pt); err != nil {
+	if err := avsPerformer.ValidateTaskSignature(ctx, pt); err != nil {
 		return fmt.Errorf("failed to validate task signature: %w", err)
 	}
 
-	e.inflightTasks.Store(task.TaskId, task)
+	// the aggregator re-sends tasks it did not get a result for; a task that is still running is
+	// acknowledged again instead of being run twice
+	if _, running := e.inflightTasks.LoadOrStore(task.TaskId, task); running {
+		e.logger.Sugar().Infow("Task is already running",
+			"taskId", task.TaskId,
+			"avsAddress", task.AvsAddress,
+		)
+		return nil
+	}
 
 	err := avsPerformer.RunTask(context.Background(), pt)
 	if err != nil {
+		e.inflightTasks.Delete(task.TaskId)
 		e.logger.Sugar().Errorw("Failed to run task",
 			"taskId", task.TaskId,
 			"avsAddress", task.AvsAddress,
@@ -64,6 +81,10 @@ func (e *Executor) handleReceivedTask(task *executorV1.TaskSubmission) error {
 }
 
 func (e *Executor) receiveTaskResponse(originalTask *performerTask.PerformerTask, response *performerTask.PerformerTaskResult, err error) {
+	// the task is no longer running whatever the outcome, it runs again if the aggregator re-sends
+	// it before a result was queued
+	defer e.inflightTasks.Delete(originalTask.TaskID)
+
 	if err != nil {
 		e.logger.Sugar().Errorw("Encountered error while receiving task response",
 			zap.String("taskId", originalTask.TaskID),
@@ -86,18 +107,7 @@ func (e *Executor) receiveTaskResponse(originalTask *performerTask.PerformerTask
 	}
 	task := storedTask.(*executorV1.TaskSubmission)
 
-	// TODO(seanmcgary): should probably assume secure unless localhost or something...
-	aggClient, err := aggregatorClient.NewAggregatorClient(task.AggregatorUrl, true)
-	if err != nil {
-		e.logger.Sugar().Errorw("Failed to create aggregator client",
-			zap.String("taskId", task.TaskId),
-			zap.String("avsAddress", task.AvsAddress),
-			zap.Error(err),
-		)
-		return
-	}
-
-	sig, err := e.signResult(response)
+	sig, err := e.signResult(task.AvsAddress, response)
 	if err != nil {
 		e.logger.Sugar().Errorw("Failed to sign result",
 			zap.String("taskId", task.TaskId),
@@ -107,7 +117,7 @@ func (e *Executor) receiveTaskResponse(originalTask *performerTask.PerformerTask
 		return
 	}
 
-	e.logger.Sugar().Infow("Submitting task result to aggregator",
+	e.logger.Sugar().Infow("Queueing task result for submission to aggregator",
 		zap.String("taskId", task.TaskId),
 		zap.String("avsAddress", task.AvsAddress),
 		zap.String("aggregatorUrl", task.AggregatorUrl),
@@ -115,29 +125,92 @@ func (e *Executor) receiveTaskResponse(originalTask *performerTask.PerformerTask
 		zap.String("signature", string(sig)),
 	)
 
-	// TODO(seanmcgary): add a retry wrapper around this call to handle cases where the aggregator is unreachable
-	_, err = aggClient.SubmitTaskResult(context.Background(), &aggregatorV1.TaskResult{
+	// the outbox owns the signed result from here on and retries until the aggregator accepts it
+	err = e.resultOutbox.Enqueue(&resultOutbox.SignedResult{
 		TaskId:          response.TaskID,
+		AvsAddress:      task.AvsAddress,
+		AggregatorUrl:   task.AggregatorUrl,
 		OperatorAddress: e.config.Operator.Address,
 		Output:          response.Result,
 		Signature:       sig,
-		AvsAddress:      task.AvsAddress,
+		Deadline:        resultDeliveryDeadline(task),
 	})
 	if err != nil {
-		e.logger.Sugar().Errorw("Failed to submit task result",
+		e.logger.Sugar().Errorw("Failed to queue task result",
 			zap.String("taskId", task.TaskId),
 			zap.String("avsAddress", task.AvsAddress),
 			zap.Error(err),
 		)
-		return
 	}
-	e.inflightTasks.Delete(task.TaskId)
 }
 
-func (e *Executor) signResult(result *performerTask.PerformerTaskResult) ([]byte, error) {
+// resultDeliveryDeadline is when the executor stops trying to deliver the result of task: the task
+// deadline, or resultDeliveryWindow from now for aggregators that do not send one
+func resultDeliveryDeadline(task *executorV1.TaskSubmission) time.Time {
+	deadline := time.Now().Add(resultDeliveryWindow)
+	if taskDeadline := task.GetDeadlineUnixSeconds(); taskDeadline != 0 && time.Unix(taskDeadline, 0).Before(deadline) {
+		return time.Unix(taskDeadline, 0)
+	}
+	return deadline
+}
+
+// submitTaskResult makes a single attempt to deliver a signed result to its aggregator
+func (e *Executor) submitTaskResult(ctx context.Context, result *resultOutbox.SignedResult) error {
+	aggClient, err := e.getAggregatorClient(result.AggregatorUrl)
+	if err != nil {
+		return resultOutbox.Permanent(fmt.Errorf("failed to create aggregator client: %w", err))
+	}
+
+	ack, err := aggClient.SubmitTaskResult(ctx, &aggregatorV1.TaskResult{
+		TaskId:          result.TaskId,
+		OperatorAddress: result.OperatorAddress,
+		Output:          result.Output,
+		Signature:       result.Signature,
+		AvsAddress:      result.AvsAddress,
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.AlreadyExists:
+			// the aggregator already has our result
+			return nil
+		case codes.InvalidArgument, codes.PermissionDenied, codes.Unauthenticated:
+			return resultOutbox.Permanent(err)
+		}
+		return err
+	}
+	if ack != nil && !ack.Success {
+		// the aggregator looked at the result and rejected it, sending it again will not change that
+		return resultOutbox.Permanent(fmt.Errorf("aggregator did not accept task result: %s", ack.Message))
+	}
+	return nil
+}
+
+// getAggregatorClient returns a client for the aggregator at url, reusing its connection across tasks
+func (e *Executor) getAggregatorClient(url string) (aggregatorV1.AggregatorServiceClient, error) {
+	e.aggregatorClientsMu.Lock()
+	defer e.aggregatorClientsMu.Unlock()
+
+	if aggClient, ok := e.aggregatorClients[url]; ok {
+		return aggClient, nil
+	}
+	// TODO(seanmcgary): should probably assume secure unless localhost or something...
+	aggClient, err := aggregatorClient.NewAggregatorClient(url, true)
+	if err != nil {
+		return nil, err
+	}
+	e.aggregatorClients[url] = aggClient
+	return aggClient, nil
+}
+
+func (e *Executor) signResult(avsAddress string, result *performerTask.PerformerTaskResult) ([]byte, error) {
 	// Generate a keccak256 hash of the result so that our signature is fixed in size.
 	// This is for compatibility with the certificate verifier.
 	digestBytes := util.GetKeccak256Digest(result.Result)
 
+	// never sign two different results for the same task
+	if err := e.signingHistory.CheckAndRecord(avsAddress, result.TaskID, digestBytes[:]); err != nil {
+		return nil, err
+	}
+
 	return e.signer.SignMessage(digestBytes[:])
 }
@@ -0,0 +1,338 @@
+package resultOutbox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultInitialBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff     = 30 * time.Second
+
+	// DefaultAttemptTimeout bounds a single submission attempt
+	DefaultAttemptTimeout = 10 * time.Second
+
+	entryFileExtension = ".json"
+)
+
+// SignedResult is a signed task result waiting to be delivered to the aggregator
+type SignedResult struct {
+	TaskId          string    `json:"taskId"`
+	AvsAddress      string    `json:"avsAddress"`
+	AggregatorUrl   string    `json:"aggregatorUrl"`
+	OperatorAddress string    `json:"operatorAddress"`
+	Output          []byte    `json:"output"`
+	Signature       []byte    `json:"signature"`
+	Deadline        time.Time `json:"deadline"`
+	CreatedAt       time.Time `json:"createdAt"`
+	Attempts        int       `json:"attempts"`
+}
+
+func (sr *SignedResult) key() string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(sr.AvsAddress), sr.TaskId)
+}
+
+// SubmitFunc delivers a result to the aggregator. Errors wrapped with Permanent are not retried.
+type SubmitFunc func(ctx context.Context, result *SignedResult) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks a submission error as one that retrying will not fix
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var pe *permanentError
+	return errors.As(err, &pe)
+}
+
+type ResultOutboxConfig struct {
+	// Dir is where undelivered results are persisted. When empty, results are only kept in memory
+	// and are lost when the executor restarts.
+	Dir string
+
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	AttemptTimeout time.Duration
+}
+
+// ResultOutbox keeps signed task results until they are accepted by the aggregator, retrying with
+// a jittered exponential backoff until the result's deadline passes. Results are persisted as one
+// file per result so that they survive restarts of the executor.
+type ResultOutbox struct {
+	config *ResultOutboxConfig
+	submit SubmitFunc
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[string]*SignedResult
+	ctx     context.Context
+	wg      sync.WaitGroup
+}
+
+func NewResultOutbox(config *ResultOutboxConfig, submit SubmitFunc, logger *zap.Logger) (*ResultOutbox, error) {
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = DefaultInitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = DefaultMaxBackoff
+	}
+	if config.AttemptTimeout <= 0 {
+		config.AttemptTimeout = DefaultAttemptTimeout
+	}
+	ro := &ResultOutbox{
+		config:  config,
+		submit:  submit,
+		logger:  logger,
+		pending: make(map[string]*SignedResult),
+	}
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+		}
+		if err := ro.load(); err != nil {
+			return nil, err
+		}
+	}
+	return ro, nil
+}
+
+// load reads the results persisted by a previous run of the executor
+func (ro *ResultOutbox) load() error {
+	files, err := os.ReadDir(ro.config.Dir)
+	if err != nil {
+		return fmt.Errorf("failed to read outbox directory: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || filepath.Ext(f.Name()) != entryFileExtension {
+			continue
+		}
+		path := filepath.Join(ro.config.Dir, f.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read outbox entry %s: %w", path, err)
+		}
+		var result *SignedResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			ro.logger.Sugar().Warnw("Discarding unreadable outbox entry",
+				zap.String("path", path),
+				zap.Error(err),
+			)
+			_ = os.Remove(path)
+			continue
+		}
+		ro.pending[result.key()] = result
+	}
+	if len(ro.pending) > 0 {
+		ro.logger.Sugar().Infow("Loaded undelivered task results from outbox",
+			zap.Int("count", len(ro.pending)),
+		)
+	}
+	return nil
+}
+
+// Start delivers the persisted results and every result enqueued afterwards until ctx is done
+func (ro *ResultOutbox) Start(ctx context.Context) {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+	ro.ctx = ctx
+	for _, result := range ro.pending {
+		ro.deliverLocked(result)
+	}
+}
+
+// Enqueue persists the result and schedules its delivery. A result for a task that is already
+// in the outbox is ignored.
+func (ro *ResultOutbox) Enqueue(result *SignedResult) error {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+
+	if _, ok := ro.pending[result.key()]; ok {
+		ro.logger.Sugar().Infow("Task result is already in the outbox",
+			zap.String("taskId", result.TaskId),
+			zap.String("avsAddress", result.AvsAddress),
+		)
+		return nil
+	}
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+	if err := ro.persist(result); err != nil {
+		return err
+	}
+	ro.pending[result.key()] = result
+	if ro.ctx != nil {
+		ro.deliverLocked(result)
+	}
+	return nil
+}
+
+// Pending returns the number of results that have not been delivered yet
+func (ro *ResultOutbox) Pending() int {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+	return len(ro.pending)
+}
+
+// Wait blocks until all deliveries in progress have finished, e.g. after the context passed to
+// Start is cancelled
+func (ro *ResultOutbox) Wait() {
+	ro.wg.Wait()
+}
+
+func (ro *ResultOutbox) deliverLocked(result *SignedResult) {
+	ro.wg.Add(1)
+	go func() {
+		defer ro.wg.Done()
+		ro.deliver(ro.ctx, result)
+	}()
+}
+
+func (ro *ResultOutbox) deliver(ctx context.Context, result *SignedResult) {
+	backoff := ro.config.InitialBackoff
+	for {
+		if !result.Deadline.IsZero() && time.Now().After(result.Deadline) {
+			ro.logger.Sugar().Errorw("Dropping task result that could not be delivered before its deadline",
+				zap.String("taskId", result.TaskId),
+				zap.String("avsAddress", result.AvsAddress),
+				zap.Int("attempts", result.Attempts),
+			)
+			ro.remove(result)
+			return
+		}
+
+		err := ro.attempt(ctx, result)
+		if err == nil {
+			ro.logger.Sugar().Infow("Delivered task result to aggregator",
+				zap.String("taskId", result.TaskId),
+				zap.String("avsAddress", result.AvsAddress),
+				zap.Int("attempts", result.Attempts),
+			)
+			ro.remove(result)
+			return
+		}
+		if ctx.Err() != nil {
+			// keep the result on disk so it is delivered after a restart
+			return
+		}
+		if isPermanent(err) {
+			ro.logger.Sugar().Errorw("Aggregator rejected task result",
+				zap.String("taskId", result.TaskId),
+				zap.String("avsAddress", result.AvsAddress),
+				zap.Error(err),
+			)
+			ro.remove(result)
+			return
+		}
+
+		delay := jitter(backoff)
+		ro.logger.Sugar().Warnw("Failed to submit task result, retrying",
+			zap.String("taskId", result.TaskId),
+			zap.String("avsAddress", result.AvsAddress),
+			zap.Int("attempts", result.Attempts),
+			zap.Duration("retryIn", delay),
+			zap.Error(err),
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		backoff = min(backoff*2, ro.config.MaxBackoff)
+	}
+}
+
+func (ro *ResultOutbox) attempt(ctx context.Context, result *SignedResult) error {
+	ro.mu.Lock()
+	result.Attempts++
+	if err := ro.persist(result); err != nil {
+		ro.logger.Sugar().Warnw("Failed to persist outbox entry",
+			zap.String("taskId", result.TaskId),
+			zap.Error(err),
+		)
+	}
+	ro.mu.Unlock()
+
+	deadline := time.Now().Add(ro.config.AttemptTimeout)
+	if !result.Deadline.IsZero() && result.Deadline.Before(deadline) {
+		deadline = result.Deadline
+	}
+	attemptCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	return ro.submit(attemptCtx, result)
+}
+
+func (ro *ResultOutbox) remove(result *SignedResult) {
+	ro.mu.Lock()
+	defer ro.mu.Unlock()
+	delete(ro.pending, result.key())
+	if ro.config.Dir == "" {
+		return
+	}
+	if err := os.Remove(ro.entryPath(result)); err != nil && !os.IsNotExist(err) {
+		ro.logger.Sugar().Warnw("Failed to remove outbox entry",
+			zap.String("taskId", result.TaskId),
+			zap.Error(err),
+		)
+	}
+}
+
+// persist atomically writes the result to the outbox directory. The caller must hold mu.
+func (ro *ResultOutbox) persist(result *SignedResult) error {
+	if ro.config.Dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox entry: %w", err)
+	}
+	path := ro.entryPath(result)
+	tmp, err := os.CreateTemp(ro.config.Dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync outbox entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close outbox entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (ro *ResultOutbox) entryPath(result *SignedResult) string {
+	hash := sha256.Sum256([]byte(result.key()))
+	return filepath.Join(ro.config.Dir, hex.EncodeToString(hash[:])+entryFileExtension)
+}
+
+// jitter returns a random duration between half and all of d
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
@@ -0,0 +1,165 @@
+package resultOutbox
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_ResultOutbox(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	newResult := func(taskId string) *SignedResult {
+		return &SignedResult{
+			TaskId:        taskId,
+			AvsAddress:    "0xavs1",
+			AggregatorUrl: "localhost:5678",
+			Output:        []byte("output"),
+			Signature:     []byte("signature"),
+			Deadline:      time.Now().Add(time.Minute),
+		}
+	}
+	newConfig := func(dir string) *ResultOutboxConfig {
+		return &ResultOutboxConfig{
+			Dir:            dir,
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     50 * time.Millisecond,
+		}
+	}
+	waitFor := func(t *testing.T, cond func() bool) {
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for condition")
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	countEntries := func(t *testing.T, dir string) int {
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatalf("Failed to read outbox directory: %v", err)
+		}
+		return len(files)
+	}
+
+	t.Run("retries until the aggregator accepts the result", func(t *testing.T) {
+		dir := t.TempDir()
+		var attempts atomic.Int64
+		ro, err := NewResultOutbox(newConfig(dir), func(ctx context.Context, result *SignedResult) error {
+			if attempts.Add(1) < 3 {
+				return fmt.Errorf("aggregator unreachable")
+			}
+			return nil
+		}, l)
+		if err != nil {
+			t.Fatalf("Failed to create outbox: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ro.Start(ctx)
+
+		assert.Nil(t, ro.Enqueue(newResult("0x1")))
+		waitFor(t, func() bool { return ro.Pending() == 0 })
+		assert.Equal(t, int64(3), attempts.Load())
+		assert.Equal(t, 0, countEntries(t, dir))
+	})
+
+	t.Run("deduplicates results for the same task", func(t *testing.T) {
+		release := make(chan struct{})
+		var attempts atomic.Int64
+		ro, err := NewResultOutbox(newConfig(t.TempDir()), func(ctx context.Context, result *SignedResult) error {
+			attempts.Add(1)
+			<-release
+			return nil
+		}, l)
+		if err != nil {
+			t.Fatalf("Failed to create outbox: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ro.Start(ctx)
+
+		assert.Nil(t, ro.Enqueue(newResult("0x1")))
+		assert.Nil(t, ro.Enqueue(newResult("0x1")))
+		assert.Equal(t, 1, ro.Pending())
+		close(release)
+		waitFor(t, func() bool { return ro.Pending() == 0 })
+		assert.Equal(t, int64(1), attempts.Load())
+	})
+
+	t.Run("keeps undelivered results across restarts", func(t *testing.T) {
+		dir := t.TempDir()
+		ro, err := NewResultOutbox(newConfig(dir), func(ctx context.Context, result *SignedResult) error {
+			return fmt.Errorf("aggregator unreachable")
+		}, l)
+		if err != nil {
+			t.Fatalf("Failed to create outbox: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		ro.Start(ctx)
+		assert.Nil(t, ro.Enqueue(newResult("0x1")))
+		assert.Nil(t, ro.Enqueue(newResult("0x2")))
+		cancel()
+		ro.Wait()
+		assert.Equal(t, 2, countEntries(t, dir))
+
+		var mu sync.Mutex
+		delivered := map[string]*SignedResult{}
+		restarted, err := NewResultOutbox(newConfig(dir), func(ctx context.Context, result *SignedResult) error {
+			mu.Lock()
+			defer mu.Unlock()
+			delivered[result.TaskId] = result
+			return nil
+		}, l)
+		if err != nil {
+			t.Fatalf("Failed to create outbox: %v", err)
+		}
+		assert.Equal(t, 2, restarted.Pending())
+
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		restarted.Start(ctx)
+		waitFor(t, func() bool { return restarted.Pending() == 0 })
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Len(t, delivered, 2)
+		assert.Equal(t, []byte("signature"), delivered["0x1"].Signature)
+		assert.Greater(t, delivered["0x1"].Attempts, 1)
+		assert.Equal(t, 0, countEntries(t, dir))
+	})
+
+	t.Run("gives up after the deadline or a permanent error", func(t *testing.T) {
+		dir := t.TempDir()
+		ro, err := NewResultOutbox(newConfig(dir), func(ctx context.Context, result *SignedResult) error {
+			if result.TaskId == "0x1" {
+				return Permanent(fmt.Errorf("invalid signature"))
+			}
+			return fmt.Errorf("aggregator unreachable")
+		}, l)
+		if err != nil {
+			t.Fatalf("Failed to create outbox: %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ro.Start(ctx)
+
+		expiring := newResult("0x2")
+		expiring.Deadline = time.Now().Add(100 * time.Millisecond)
+		assert.Nil(t, ro.Enqueue(newResult("0x1")))
+		assert.Nil(t, ro.Enqueue(expiring))
+
+		waitFor(t, func() bool { return ro.Pending() == 0 })
+		assert.Equal(t, 0, countEntries(t, dir))
+	})
+}
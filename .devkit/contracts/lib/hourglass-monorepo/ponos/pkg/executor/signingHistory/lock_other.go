@@ -0,0 +1,13 @@
+//go:build !unix
+
+package signingHistory
+
+import (
+	"os"
+)
+
+// lockFile is a no-op on platforms without flock. Running two executors against the same data
+// directory is not detected there.
+func lockFile(file *os.File) error {
+	return nil
+}
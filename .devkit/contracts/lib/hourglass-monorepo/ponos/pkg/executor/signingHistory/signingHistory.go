@@ -0,0 +1,281 @@
+// Package signingHistory keeps a record of every task result digest the executor signed, so that it
+// never signs two different results for the same task.
+//
+// Records are appended to a JSON lines file in the executor's data directory. The history can be
+// exported to and imported from a JSON document so that it can be moved together with the signing
+// key when an operator migrates to a new host:
+//
+//	{
+//	  "version": 1,
+//	  "records": [
+//	    {
+//	      "avsAddress": "0xavs...",
+//	      "taskId": "0xtask...",
+//	      "digest": "0x<keccak256 of the result>",
+//	      "signedAt": "2025-01-01T00:00:00Z"
+//	    }
+//	  ]
+//	}
+package signingHistory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"go.uber.org/zap"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ExportVersion is the version of the export format written by Export
+	ExportVersion = 1
+
+	historyFileName = "signing-history.jsonl"
+)
+
+// ErrConflictingDigest is returned when a different digest was already signed for a task
+var ErrConflictingDigest = errors.New("a different result was already signed for this task")
+
+// ErrHistoryInUse is returned when another process, usually a running executor, has the history open
+var ErrHistoryInUse = errors.New("signing history is in use by another process")
+
+// errLocked is returned by lockFile when another process holds the lock on the history file
+var errLocked = errors.New("file is locked")
+
+// SigningRecord is a digest the executor signed for a task
+type SigningRecord struct {
+	AvsAddress string        `json:"avsAddress"`
+	TaskId     string        `json:"taskId"`
+	Digest     hexutil.Bytes `json:"digest"`
+	SignedAt   time.Time     `json:"signedAt"`
+}
+
+func (sr *SigningRecord) key() string {
+	return fmt.Sprintf("%s/%s", strings.ToLower(sr.AvsAddress), strings.ToLower(sr.TaskId))
+}
+
+// SigningHistoryExport is the documented format used to move the signing history between hosts
+type SigningHistoryExport struct {
+	Version int              `json:"version"`
+	Records []*SigningRecord `json:"records"`
+}
+
+type SigningHistoryConfig struct {
+	// Dir is where the history is persisted. When empty, the history is only kept in memory.
+	Dir string
+}
+
+type SigningHistory struct {
+	config *SigningHistoryConfig
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	records map[string]*SigningRecord
+	file    *os.File
+	closed  bool
+}
+
+func NewSigningHistory(config *SigningHistoryConfig, logger *zap.Logger) (*SigningHistory, error) {
+	sh := &SigningHistory{
+		config:  config,
+		logger:  logger,
+		records: make(map[string]*SigningRecord),
+	}
+	if config.Dir == "" {
+		logger.Sugar().Warnw("No data directory is set, the signing history is only kept in memory. " +
+			"Conflicting results may be signed for tasks that were signed before a restart.")
+		return sh, nil
+	}
+
+	if err := os.MkdirAll(config.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create signing history directory: %w", err)
+	}
+	path := filepath.Join(config.Dir, historyFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signing history: %w", err)
+	}
+	// the lock is held until the file is closed, so that two processes never write the history
+	if err := lockFile(file); err != nil {
+		_ = file.Close()
+		if errors.Is(err, errLocked) {
+			return nil, fmt.Errorf("%w: %s", ErrHistoryInUse, path)
+		}
+		return nil, fmt.Errorf("failed to lock signing history: %w", err)
+	}
+	if err := sh.load(path); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	sh.file = file
+	return sh, nil
+}
+
+// load reads the history file. A partially written last line, left behind by a crash while
+// appending, is ignored since the signature it describes was never produced.
+func (sh *SigningHistory) load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read signing history: %w", err)
+	}
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var record *SigningRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			if i == len(lines)-1 {
+				sh.logger.Sugar().Warnw("Ignoring partially written signing history record",
+					zap.String("path", path),
+					zap.Error(err),
+				)
+				continue
+			}
+			return fmt.Errorf("signing history %s is corrupt at line %d: %w", path, i+1, err)
+		}
+		if _, ok := sh.records[record.key()]; !ok {
+			sh.records[record.key()] = record
+		}
+	}
+	return nil
+}
+
+// CheckAndRecord records that digest is about to be signed for the task. It returns
+// ErrConflictingDigest if a different digest was signed for the task before. Signing the same
+// digest again is allowed.
+func (sh *SigningHistory) CheckAndRecord(avsAddress string, taskId string, digest []byte) error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	record := &SigningRecord{
+		AvsAddress: avsAddress,
+		TaskId:     taskId,
+		Digest:     slices.Clone(digest),
+		SignedAt:   time.Now().UTC(),
+	}
+	if existing, ok := sh.records[record.key()]; ok {
+		if bytes.Equal(existing.Digest, digest) {
+			return nil
+		}
+		sh.logger.Sugar().Errorw("Refusing to sign a conflicting result",
+			zap.String("avsAddress", avsAddress),
+			zap.String("taskId", taskId),
+			zap.String("signedDigest", existing.Digest.String()),
+			zap.String("digest", hexutil.Encode(digest)),
+		)
+		return fmt.Errorf("%w: avs %s, task %s", ErrConflictingDigest, avsAddress, taskId)
+	}
+	if err := sh.appendLocked(record); err != nil {
+		return err
+	}
+	sh.records[record.key()] = record
+	return nil
+}
+
+// appendLocked durably writes the record before the signature is produced. The caller must hold mu.
+func (sh *SigningHistory) appendLocked(record *SigningRecord) error {
+	if sh.closed {
+		return fmt.Errorf("signing history is closed")
+	}
+	if sh.file == nil {
+		return nil
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal signing record: %w", err)
+	}
+	if _, err := sh.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write signing record: %w", err)
+	}
+	if err := sh.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync signing history: %w", err)
+	}
+	return nil
+}
+
+// Export writes the full history to w in the documented export format
+func (sh *SigningHistory) Export(w io.Writer) error {
+	sh.mu.Lock()
+	export := &SigningHistoryExport{
+		Version: ExportVersion,
+		Records: make([]*SigningRecord, 0, len(sh.records)),
+	}
+	for _, record := range sh.records {
+		export.Records = append(export.Records, record)
+	}
+	sh.mu.Unlock()
+
+	slices.SortFunc(export.Records, func(a, b *SigningRecord) int {
+		return strings.Compare(a.key(), b.key())
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
+
+// Import merges an exported history into this one and returns the number of new records. If any
+// imported record conflicts with a digest already signed for the same task, nothing is imported.
+func (sh *SigningHistory) Import(r io.Reader) (int, error) {
+	var export *SigningHistoryExport
+	if err := json.NewDecoder(bufio.NewReader(r)).Decode(&export); err != nil {
+		return 0, fmt.Errorf("failed to decode signing history: %w", err)
+	}
+	if export == nil || export.Version != ExportVersion {
+		return 0, fmt.Errorf("unsupported signing history version, expected %d", ExportVersion)
+	}
+
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+
+	newRecords := make(map[string]*SigningRecord)
+	for i, record := range export.Records {
+		if record == nil || record.AvsAddress == "" || record.TaskId == "" || len(record.Digest) == 0 {
+			return 0, fmt.Errorf("signing history record %d is incomplete", i)
+		}
+		known, ok := sh.records[record.key()]
+		if !ok {
+			known, ok = newRecords[record.key()]
+		}
+		if ok {
+			if !bytes.Equal(known.Digest, record.Digest) {
+				return 0, fmt.Errorf("%w: avs %s, task %s", ErrConflictingDigest, record.AvsAddress, record.TaskId)
+			}
+			continue
+		}
+		newRecords[record.key()] = record
+	}
+
+	for key, record := range newRecords {
+		if err := sh.appendLocked(record); err != nil {
+			return 0, err
+		}
+		sh.records[key] = record
+	}
+	return len(newRecords), nil
+}
+
+// Close closes the history file
+func (sh *SigningHistory) Close() error {
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.closed = true
+	if sh.file == nil {
+		return nil
+	}
+	err := sh.file.Close()
+	sh.file = nil
+	return err
+}
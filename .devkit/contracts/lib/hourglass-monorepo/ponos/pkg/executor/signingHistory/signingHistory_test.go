@@ -0,0 +1,108 @@
+package signingHistory
+
+import (
+	"bytes"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_SigningHistory(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	newHistory := func(t *testing.T, dir string) *SigningHistory {
+		sh, err := NewSigningHistory(&SigningHistoryConfig{Dir: dir}, l)
+		if err != nil {
+			t.Fatalf("Failed to create signing history: %v", err)
+		}
+		t.Cleanup(func() { _ = sh.Close() })
+		return sh
+	}
+
+	t.Run("refuses to sign a different digest for the same task", func(t *testing.T) {
+		sh := newHistory(t, t.TempDir())
+
+		assert.Nil(t, sh.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+		assert.Nil(t, sh.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+		assert.ErrorIs(t, sh.CheckAndRecord("0xAVS1", "0x1", []byte{2}), ErrConflictingDigest)
+
+		// the same task id for a different AVS is a different task
+		assert.Nil(t, sh.CheckAndRecord("0xavs2", "0x1", []byte{2}))
+	})
+
+	t.Run("remembers signed digests across restarts", func(t *testing.T) {
+		dir := t.TempDir()
+		sh := newHistory(t, dir)
+		assert.Nil(t, sh.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+		assert.Nil(t, sh.Close())
+
+		// simulate a crash in the middle of appending a record
+		f, err := os.OpenFile(filepath.Join(dir, historyFileName), os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			t.Fatalf("Failed to open history file: %v", err)
+		}
+		_, _ = f.WriteString(`{"avsAddress":"0xavs1","taskId":"0x2","dig`)
+		_ = f.Close()
+
+		restarted := newHistory(t, dir)
+		assert.ErrorIs(t, restarted.CheckAndRecord("0xavs1", "0x1", []byte{2}), ErrConflictingDigest)
+		assert.Nil(t, restarted.CheckAndRecord("0xavs1", "0x2", []byte{2}))
+	})
+
+	t.Run("exports and imports the history", func(t *testing.T) {
+		source := newHistory(t, t.TempDir())
+		assert.Nil(t, source.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+		assert.Nil(t, source.CheckAndRecord("0xavs1", "0x2", []byte{2}))
+
+		var exported bytes.Buffer
+		assert.Nil(t, source.Export(&exported))
+		assert.Contains(t, exported.String(), `"version": 1`)
+		assert.Contains(t, exported.String(), `"digest": "0x01"`)
+
+		dir := t.TempDir()
+		target := newHistory(t, dir)
+		assert.Nil(t, target.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+
+		imported, err := target.Import(bytes.NewReader(exported.Bytes()))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, imported)
+		assert.ErrorIs(t, target.CheckAndRecord("0xavs1", "0x2", []byte{3}), ErrConflictingDigest)
+
+		// imported records are persisted
+		assert.Nil(t, target.Close())
+		reopened := newHistory(t, dir)
+		assert.ErrorIs(t, reopened.CheckAndRecord("0xavs1", "0x2", []byte{3}), ErrConflictingDigest)
+	})
+
+	t.Run("rejects imports that conflict with the local history", func(t *testing.T) {
+		source := newHistory(t, "")
+		assert.Nil(t, source.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+		assert.Nil(t, source.CheckAndRecord("0xavs1", "0x2", []byte{2}))
+		var exported bytes.Buffer
+		assert.Nil(t, source.Export(&exported))
+
+		target := newHistory(t, "")
+		assert.Nil(t, target.CheckAndRecord("0xavs1", "0x2", []byte{3}))
+
+		_, err := target.Import(&exported)
+		assert.ErrorIs(t, err, ErrConflictingDigest)
+		// nothing was imported
+		assert.Nil(t, target.CheckAndRecord("0xavs1", "0x1", []byte{4}))
+	})
+	t.Run("refuses to open a history that is in use", func(t *testing.T) {
+		dir := t.TempDir()
+		sh := newHistory(t, dir)
+
+		_, err := NewSigningHistory(&SigningHistoryConfig{Dir: dir}, l)
+		assert.ErrorIs(t, err, ErrHistoryInUse)
+
+		assert.Nil(t, sh.Close())
+		reopened := newHistory(t, dir)
+		assert.Nil(t, reopened.CheckAndRecord("0xavs1", "0x1", []byte{1}))
+	})
+}
@@ -0,0 +1,128 @@
+package cachedPeeringDataFetcher
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultRefreshInterval = 60 * time.Second
+
+type CachedPeeringDataFetcherConfig struct {
+	// RefreshInterval is how long fetched peers are served before they are fetched again.
+	// Defaults to DefaultRefreshInterval.
+	RefreshInterval time.Duration
+}
+
+type cacheEntry struct {
+	peers     []*peering.OperatorPeerInfo
+	fetchedAt time.Time
+}
+
+// pendingFetch is a fetch in progress. done is closed once peers and err are set.
+type pendingFetch struct {
+	done  chan struct{}
+	peers []*peering.OperatorPeerInfo
+	err   error
+}
+
+// CachedPeeringDataFetcher caches the peers returned by another fetcher. When refreshing fails,
+// the previously fetched peers keep being served so that an unreachable RPC node does not take
+// the executor or aggregator down.
+type CachedPeeringDataFetcher struct {
+	fetcher peering.IPeeringDataFetcher
+	config  *CachedPeeringDataFetcherConfig
+	logger  *zap.Logger
+
+	// mu guards cache and pending. It is not held while fetching, so that a slow RPC call does
+	// not block readers of other AVSs or of peers that are still cached.
+	mu      sync.Mutex
+	cache   map[string]*cacheEntry
+	pending map[string]*pendingFetch
+
+	now func() time.Time
+}
+
+func NewCachedPeeringDataFetcher(
+	fetcher peering.IPeeringDataFetcher,
+	config *CachedPeeringDataFetcherConfig,
+	logger *zap.Logger,
+) *CachedPeeringDataFetcher {
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultRefreshInterval
+	}
+	return &CachedPeeringDataFetcher{
+		fetcher: fetcher,
+		config:  config,
+		logger:  logger,
+		cache:   make(map[string]*cacheEntry),
+		pending: make(map[string]*pendingFetch),
+		now:     time.Now,
+	}
+}
+
+func (cpdf *CachedPeeringDataFetcher) ListExecutorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	return cpdf.get(ctx, "executor", avsAddress, cpdf.fetcher.ListExecutorOperators)
+}
+
+func (cpdf *CachedPeeringDataFetcher) ListAggregatorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	return cpdf.get(ctx, "aggregator", avsAddress, cpdf.fetcher.ListAggregatorOperators)
+}
+
+func (cpdf *CachedPeeringDataFetcher) get(
+	ctx context.Context,
+	kind string,
+	avsAddress string,
+	fetch func(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error),
+) ([]*peering.OperatorPeerInfo, error) {
+	key := fmt.Sprintf("%s/%s", kind, strings.ToLower(avsAddress))
+
+	cpdf.mu.Lock()
+	entry, ok := cpdf.cache[key]
+	if ok && cpdf.now().Sub(entry.fetchedAt) < cpdf.config.RefreshInterval {
+		cpdf.mu.Unlock()
+		return entry.peers, nil
+	}
+	if pending, fetching := cpdf.pending[key]; fetching {
+		cpdf.mu.Unlock()
+		// serve the stale peers while they are being refreshed, otherwise wait for the fetch
+		if ok {
+			return entry.peers, nil
+		}
+		select {
+		case <-pending.done:
+			return pending.peers, pending.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	pending := &pendingFetch{done: make(chan struct{})}
+	cpdf.pending[key] = pending
+	cpdf.mu.Unlock()
+
+	peers, err := fetch(ctx, avsAddress)
+
+	cpdf.mu.Lock()
+	delete(cpdf.pending, key)
+	if err == nil {
+		cpdf.cache[key] = &cacheEntry{peers: peers, fetchedAt: cpdf.now()}
+	}
+	cpdf.mu.Unlock()
+
+	if err != nil && ok {
+		cpdf.logger.Sugar().Warnw("Failed to refresh peers, using cached peers",
+			zap.String("avsAddress", avsAddress),
+			zap.String("operatorType", kind),
+			zap.Time("fetchedAt", entry.fetchedAt),
+			zap.Error(err),
+		)
+		peers, err = entry.peers, nil
+	}
+	pending.peers, pending.err = peers, err
+	close(pending.done)
+	return peers, err
+}
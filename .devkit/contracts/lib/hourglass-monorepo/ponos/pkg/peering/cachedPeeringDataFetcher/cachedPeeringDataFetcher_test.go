@@ -0,0 +1,142 @@
+package cachedPeeringDataFetcher
+
+import (
+	"context"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingPeeringDataFetcher blocks fetches for the AVS "0xslow" until release is closed
+type blockingPeeringDataFetcher struct {
+	release chan struct{}
+	calls   atomic.Int64
+}
+
+func (f *blockingPeeringDataFetcher) ListExecutorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	f.calls.Add(1)
+	if avsAddress == "0xslow" {
+		<-f.release
+	}
+	return []*peering.OperatorPeerInfo{{NetworkAddress: avsAddress}}, nil
+}
+
+func (f *blockingPeeringDataFetcher) ListAggregatorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	return f.ListExecutorOperators(ctx, avsAddress)
+}
+
+type fakePeeringDataFetcher struct {
+	calls int
+	err   error
+}
+
+func (f *fakePeeringDataFetcher) ListExecutorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*peering.OperatorPeerInfo{{NetworkAddress: fmt.Sprintf("executor-%d", f.calls)}}, nil
+}
+
+func (f *fakePeeringDataFetcher) ListAggregatorOperators(ctx context.Context, avsAddress string) ([]*peering.OperatorPeerInfo, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*peering.OperatorPeerInfo{{NetworkAddress: fmt.Sprintf("aggregator-%d", f.calls)}}, nil
+}
+
+func Test_CachedPeeringDataFetcher(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	ctx := context.Background()
+
+	newFetcher := func(fetcher peering.IPeeringDataFetcher) (*CachedPeeringDataFetcher, *time.Time) {
+		now := time.Now()
+		cpdf := NewCachedPeeringDataFetcher(fetcher, &CachedPeeringDataFetcherConfig{RefreshInterval: time.Minute}, l)
+		cpdf.now = func() time.Time { return now }
+		return cpdf, &now
+	}
+
+	t.Run("serves cached peers until the refresh interval passes", func(t *testing.T) {
+		fake := &fakePeeringDataFetcher{}
+		cpdf, now := newFetcher(fake)
+
+		peers, err := cpdf.ListExecutorOperators(ctx, "0xAVS1")
+		assert.Nil(t, err)
+		assert.Equal(t, "executor-1", peers[0].NetworkAddress)
+
+		peers, err = cpdf.ListExecutorOperators(ctx, "0xavs1")
+		assert.Nil(t, err)
+		assert.Equal(t, "executor-1", peers[0].NetworkAddress)
+		assert.Equal(t, 1, fake.calls)
+
+		// executors and aggregators are cached separately
+		peers, err = cpdf.ListAggregatorOperators(ctx, "0xavs1")
+		assert.Nil(t, err)
+		assert.Equal(t, "aggregator-2", peers[0].NetworkAddress)
+
+		*now = now.Add(time.Minute)
+		peers, err = cpdf.ListExecutorOperators(ctx, "0xavs1")
+		assert.Nil(t, err)
+		assert.Equal(t, "executor-3", peers[0].NetworkAddress)
+	})
+
+	t.Run("serves stale peers when refreshing fails", func(t *testing.T) {
+		fake := &fakePeeringDataFetcher{}
+		cpdf, now := newFetcher(fake)
+
+		_, err := cpdf.ListExecutorOperators(ctx, "0xavs1")
+		assert.Nil(t, err)
+
+		fake.err = fmt.Errorf("rpc unavailable")
+		*now = now.Add(2 * time.Minute)
+		peers, err := cpdf.ListExecutorOperators(ctx, "0xavs1")
+		assert.Nil(t, err)
+		assert.Equal(t, "executor-1", peers[0].NetworkAddress)
+		assert.Equal(t, 2, fake.calls)
+
+		// nothing cached yet, so the error is returned
+		_, err = cpdf.ListExecutorOperators(ctx, "0xavs2")
+		assert.ErrorContains(t, err, "rpc unavailable")
+	})
+	t.Run("a slow fetch does not block other readers", func(t *testing.T) {
+		fake := &blockingPeeringDataFetcher{release: make(chan struct{})}
+		cpdf, _ := newFetcher(fake)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				peers, err := cpdf.ListExecutorOperators(ctx, "0xslow")
+				assert.Nil(t, err)
+				assert.Equal(t, "0xslow", peers[0].NetworkAddress)
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, err := cpdf.ListExecutorOperators(ctx, "0xfast")
+			assert.Nil(t, err)
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("reading another AVS was blocked by a slow fetch")
+		}
+
+		close(fake.release)
+		wg.Wait()
+		// concurrent misses for the same AVS share a single fetch
+		assert.Equal(t, int64(2), fake.calls.Load())
+	})
+}
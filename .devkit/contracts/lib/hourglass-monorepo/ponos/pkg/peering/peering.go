@@ -2,26 +2,134 @@ package peering
 
 import (
 	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/keystore"
 )
 
 type OperatorPeerInfo struct {
-	NetworkAddress  string           `json:"networkAddress"`
-	PublicKey       *bn254.PublicKey `json:"publicKey"`
-	OperatorAddress string           `json:"operatorAddress"`
-	OperatorSetIds  []uint32         `json:"operatorSetIds"`
+	NetworkAddress string `json:"networkAddress"`
+
+	// PublicKey is the operator's signing key. It is stored using the generic signing interface
+	// so that peers can be described for any supported curve; CurveType records which one.
+	PublicKey signing.PublicKey `json:"publicKey"`
+
+	// CurveType is the curve the PublicKey belongs to (bn254, bls381)
+	CurveType string `json:"curveType"`
+
+	OperatorAddress string   `json:"operatorAddress"`
+	OperatorSetIds  []uint32 `json:"operatorSetIds"`
+}
+
+// GetPublicKeyForScheme re-creates the peer's public key using the provided scheme so that it
+// can be used with signatures created by that same scheme.
+func (opi *OperatorPeerInfo) GetPublicKeyForScheme(scheme signing.SigningScheme) (signing.PublicKey, error) {
+	if opi.PublicKey == nil {
+		return nil, fmt.Errorf("peer '%s' has no public key", opi.OperatorAddress)
+	}
+	return scheme.NewPublicKeyFromBytes(opi.PublicKey.Bytes())
+}
+
+// operatorPeerInfoJSON is the JSON form of OperatorPeerInfo. The public key is hex encoded and
+// re-created from CurveType when unmarshalled; bn254 keys also carry their G1 point.
+type operatorPeerInfoJSON struct {
+	NetworkAddress  string   `json:"networkAddress"`
+	PublicKey       string   `json:"publicKey,omitempty"`
+	PublicKeyG1     string   `json:"publicKeyG1,omitempty"`
+	CurveType       string   `json:"curveType"`
+	OperatorAddress string   `json:"operatorAddress"`
+	OperatorSetIds  []uint32 `json:"operatorSetIds"`
+}
+
+func (opi *OperatorPeerInfo) MarshalJSON() ([]byte, error) {
+	out := &operatorPeerInfoJSON{
+		NetworkAddress:  opi.NetworkAddress,
+		CurveType:       opi.CurveType,
+		OperatorAddress: opi.OperatorAddress,
+		OperatorSetIds:  opi.OperatorSetIds,
+	}
+	if opi.PublicKey != nil {
+		out.PublicKey = hex.EncodeToString(opi.PublicKey.Bytes())
+		if pubKey, ok := opi.PublicKey.(*bn254.PublicKey); ok && pubKey.GetG1Point() != nil {
+			out.PublicKeyG1 = hex.EncodeToString(pubKey.GetG1Point().Marshal())
+		}
+	}
+	return json.Marshal(out)
+}
+
+func (opi *OperatorPeerInfo) UnmarshalJSON(data []byte) error {
+	var in operatorPeerInfoJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*opi = OperatorPeerInfo{
+		NetworkAddress:  in.NetworkAddress,
+		CurveType:       in.CurveType,
+		OperatorAddress: in.OperatorAddress,
+		OperatorSetIds:  in.OperatorSetIds,
+	}
+	if in.PublicKey == "" {
+		return nil
+	}
+	pubKeyBytes, err := hex.DecodeString(in.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode public key of peer '%s': %w", in.OperatorAddress, err)
+	}
+	if in.PublicKeyG1 != "" {
+		g1Bytes, err := hex.DecodeString(in.PublicKeyG1)
+		if err != nil {
+			return fmt.Errorf("failed to decode G1 public key of peer '%s': %w", in.OperatorAddress, err)
+		}
+		pubKey, err := bn254.NewPublicKeyFromG1AndG2Bytes(g1Bytes, pubKeyBytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse public key of peer '%s': %w", in.OperatorAddress, err)
+		}
+		opi.PublicKey = pubKey
+		return nil
+	}
+	curveType := in.CurveType
+	if curveType == "" {
+		curveType = "bn254"
+	}
+	scheme, err := keystore.GetSigningSchemeForCurveType(curveType)
+	if err != nil {
+		return err
+	}
+	if opi.PublicKey, err = scheme.NewPublicKeyFromBytes(pubKeyBytes); err != nil {
+		return fmt.Errorf("failed to parse public key of peer '%s': %w", in.OperatorAddress, err)
+	}
+	return nil
 }
 
 func (opi *OperatorPeerInfo) Copy() (*OperatorPeerInfo, error) {
 	operatorSetIds := make([]uint32, len(opi.OperatorSetIds))
 	copy(operatorSetIds, opi.OperatorSetIds)
-	clonedPubKey, err := bn254.NewPublicKeyFromBytes(opi.PublicKey.Bytes())
-	if err != nil {
-		return nil, err
+
+	var clonedPubKey signing.PublicKey
+	if pubKey, ok := opi.PublicKey.(*bn254.PublicKey); ok {
+		// keep the G1 point, which does not survive a round trip through Bytes
+		clonedPubKey = pubKey.Clone()
+	} else if opi.PublicKey != nil {
+		curveType := opi.CurveType
+		if curveType == "" {
+			curveType = "bn254"
+		}
+		scheme, err := keystore.GetSigningSchemeForCurveType(curveType)
+		if err != nil {
+			return nil, err
+		}
+		clonedPubKey, err = opi.GetPublicKeyForScheme(scheme)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &OperatorPeerInfo{
 		NetworkAddress:  opi.NetworkAddress,
 		PublicKey:       clonedPubKey,
+		CurveType:       opi.CurveType,
 		OperatorAddress: opi.OperatorAddress,
 		OperatorSetIds:  operatorSetIds,
 	}, nil
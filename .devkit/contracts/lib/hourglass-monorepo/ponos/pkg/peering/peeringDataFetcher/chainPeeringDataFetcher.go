@@ -0,0 +1,75 @@
+package peeringDataFetcher
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractCaller/caller"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/contractStore"
+	"go.uber.org/zap"
+)
+
+// ChainPeeringDataFetcherConfig describes the L1 chain the peering data is read from
+type ChainPeeringDataFetcherConfig struct {
+	ChainId config.ChainId
+	RpcUrl  string
+
+	// FallbackRpcUrls are more RPC endpoints for the chain
+	FallbackRpcUrls []string
+
+	// TaskMailboxAddress is looked up in the contract store when empty
+	TaskMailboxAddress string
+
+	// AVSRegistrarAddresses overrides the TaskAVSRegistrar from the contract store for single AVSs,
+	// keyed by lower-cased AVS address
+	AVSRegistrarAddresses map[string]string
+}
+
+// NewPeeringDataFetcherForChain creates a PeeringDataFetcher that reads operator sets and peering
+// data from the TaskMailbox and TaskAVSRegistrar contracts on the given chain
+func NewPeeringDataFetcherForChain(
+	cfg *ChainPeeringDataFetcherConfig,
+	store contractStore.IContractStore,
+	logger *zap.Logger,
+) (*PeeringDataFetcher, error) {
+	// AVSs without an override read from the registrar in the contract store, which is only
+	// required when there are no overrides at all
+	avsRegistrarAddress, err := contractStore.ResolveContractAddress(store, "", config.ContractName_TaskAVSRegistrar, cfg.ChainId)
+	if err != nil {
+		if len(cfg.AVSRegistrarAddresses) == 0 {
+			return nil, err
+		}
+		avsRegistrarAddress = ""
+	}
+	taskMailboxAddress, err := contractStore.ResolveContractAddress(store, cfg.TaskMailboxAddress, config.ContractName_TaskMailbox, cfg.ChainId)
+	if err != nil {
+		return nil, err
+	}
+	logger.Sugar().Infow("Using on-chain peering data",
+		zap.Uint("chainId", uint(cfg.ChainId)),
+		zap.String("avsRegistrarAddress", avsRegistrarAddress),
+		zap.Any("avsRegistrarOverrides", cfg.AVSRegistrarAddresses),
+		zap.String("taskMailboxAddress", taskMailboxAddress),
+	)
+
+	ec := ethereum.NewEthereumClient(&ethereum.EthereumClientConfig{
+		BaseUrl:      cfg.RpcUrl,
+		FallbackUrls: cfg.FallbackRpcUrls,
+		BlockType:    ethereum.BlockType_Latest,
+	}, logger)
+	ethereumContractCaller, err := ec.GetEthereumContractCaller()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ethereum contract caller: %w", err)
+	}
+
+	// peering data is only read, so the caller does not need a private key
+	cc, err := caller.NewContractCaller(&caller.ContractCallerConfig{
+		AVSRegistrarAddress:   avsRegistrarAddress,
+		AVSRegistrarAddresses: cfg.AVSRegistrarAddresses,
+		TaskMailboxAddress:    taskMailboxAddress,
+	}, ethereumContractCaller, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract caller: %w", err)
+	}
+	return NewPeeringDataFetcher(cc, logger), nil
+}
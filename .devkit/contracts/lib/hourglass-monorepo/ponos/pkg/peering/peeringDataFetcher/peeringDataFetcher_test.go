@@ -178,7 +178,12 @@ func Test_PeeringDataFetcher(t *testing.T) {
 				t.Fatalf("Failed to sign message: %v", err)
 			}
 
-			valid, err := testSig.Verify(peers[0].PublicKey, testMessage)
+			peerPubKey, err := bn254.NewPublicKeyFromBytes(peers[0].PublicKey.Bytes())
+			if err != nil {
+				t.Fatalf("Failed to parse peer public key: %v", err)
+			}
+
+			valid, err := testSig.Verify(peerPubKey, testMessage)
 			if err != nil {
 				t.Fatalf("Failed to verify signature: %v", err)
 			}
@@ -0,0 +1,59 @@
+package peering
+
+import (
+	"encoding/json"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bls381"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_OperatorPeerInfoJSON(t *testing.T) {
+	t.Run("round trips a bn254 peer with its G1 point", func(t *testing.T) {
+		_, pubKey, err := bn254.GenerateKeyPair()
+		require.NoError(t, err)
+
+		peer := &OperatorPeerInfo{
+			NetworkAddress:  "localhost:9000",
+			PublicKey:       pubKey,
+			CurveType:       "bn254",
+			OperatorAddress: "0xoperator",
+			OperatorSetIds:  []uint32{1, 2},
+		}
+		data, err := json.Marshal(peer)
+		require.NoError(t, err)
+
+		var decoded *OperatorPeerInfo
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, peer.NetworkAddress, decoded.NetworkAddress)
+		assert.Equal(t, peer.OperatorSetIds, decoded.OperatorSetIds)
+		decodedKey, ok := decoded.PublicKey.(*bn254.PublicKey)
+		require.True(t, ok)
+		assert.Equal(t, pubKey.Bytes(), decodedKey.Bytes())
+		assert.True(t, pubKey.GetG1Point().Equal(decodedKey.GetG1Point()))
+	})
+
+	t.Run("round trips a bls381 peer", func(t *testing.T) {
+		_, pubKey, err := bls381.GenerateKeyPair()
+		require.NoError(t, err)
+
+		data, err := json.Marshal(&OperatorPeerInfo{PublicKey: pubKey, CurveType: "bls381"})
+		require.NoError(t, err)
+
+		var decoded OperatorPeerInfo
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		require.NotNil(t, decoded.PublicKey)
+		assert.Equal(t, pubKey.Bytes(), decoded.PublicKey.Bytes())
+	})
+
+	t.Run("round trips a peer without a public key", func(t *testing.T) {
+		data, err := json.Marshal(&OperatorPeerInfo{OperatorAddress: "0xoperator"})
+		require.NoError(t, err)
+
+		var decoded OperatorPeerInfo
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, "0xoperator", decoded.OperatorAddress)
+		assert.Nil(t, decoded.PublicKey)
+	})
+}
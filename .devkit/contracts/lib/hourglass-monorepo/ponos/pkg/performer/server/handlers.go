@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -9,21 +10,29 @@ import (
 )
 
 func (pp *PonosPerformer) ExecuteTask(ctx context.Context, task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, pp.config.Timeout)
+	defer cancel()
 
-	if err := pp.taskWorker.ValidateTask(task); err != nil {
+	if err := pp.taskWorker.ValidateTask(ctx, task); err != nil {
 		pp.logger.Sugar().Errorw("task is invalid",
 			zap.String("taskId", string(task.TaskId)),
 			zap.Error(err),
 		)
+		if s, ok := contextErrorStatus(ctx, err); ok {
+			return nil, s.Err()
+		}
 		return nil, status.Errorf(codes.Internal, "task is invalid: %s", err.Error())
 	}
 
-	res, err := pp.taskWorker.HandleTask(task)
+	res, err := pp.taskWorker.HandleTask(ctx, task)
 	if err != nil {
 		pp.logger.Sugar().Errorw("Failed to handle task",
 			zap.String("taskId", string(task.TaskId)),
 			zap.Error(err),
 		)
+		if s, ok := contextErrorStatus(ctx, err); ok {
+			return nil, s.Err()
+		}
 		return nil, status.Errorf(codes.Internal, "Failed to handle task: %s", err.Error())
 	}
 
@@ -33,6 +42,17 @@ func (pp *PonosPerformer) ExecuteTask(ctx context.Context, task *performerV1.Tas
 	}, nil
 }
 
+// contextErrorStatus maps a deadline or cancellation of the task context to the matching gRPC status
+func contextErrorStatus(ctx context.Context, err error) (*status.Status, bool) {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return status.Newf(codes.DeadlineExceeded, "task exceeded deadline: %s", err.Error()), true
+	case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+		return status.Newf(codes.Canceled, "task was cancelled: %s", err.Error()), true
+	}
+	return nil, false
+}
+
 func (pp *PonosPerformer) HealthCheck(ctx context.Context, request *performerV1.HealthCheckRequest) (*performerV1.HealthCheckResponse, error) {
 	return &performerV1.HealthCheckResponse{
 		Status: performerV1.PerformerStatus_READY_FOR_TASK,
@@ -7,25 +7,44 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/rpcServer"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
 	"go.uber.org/zap"
+	"os"
+	"strconv"
 	"time"
 )
 
+// PortEnvVar overrides the configured Port when set. Executors that run the performer as a
+// local process use it to assign a free port.
+const PortEnvVar = "PERFORMER_PORT"
+
 type PonosPerformerConfig struct {
-	Port    int
+	Port int
+
+	// Timeout is the maximum amount of time a single task (validation + handling) may run for
 	Timeout time.Duration
 }
 
 type PonosPerformer struct {
 	config     *PonosPerformerConfig
 	rpcServer  *rpcServer.RpcServer
-	taskWorker worker.IWorker
+	taskWorker worker.IContextWorker
 	logger     *zap.Logger
 }
 
+// NewPonosPerformer creates a performer for a worker that is not context-aware.
+// The worker is wrapped with a worker.ContextWorkerAdapter so the configured timeout is still enforced.
 func NewPonosPerformer(
 	cfg *PonosPerformerConfig,
 	rpcServer *rpcServer.RpcServer,
-	worker worker.IWorker,
+	w worker.IWorker,
+	logger *zap.Logger,
+) *PonosPerformer {
+	return NewPonosPerformerWithContextWorker(cfg, rpcServer, worker.NewContextWorkerAdapter(w), logger)
+}
+
+func NewPonosPerformerWithContextWorker(
+	cfg *PonosPerformerConfig,
+	rpcServer *rpcServer.RpcServer,
+	worker worker.IContextWorker,
 	logger *zap.Logger,
 ) *PonosPerformer {
 	if cfg.Timeout == 0 {
@@ -44,16 +63,31 @@ func NewPonosPerformer(
 
 func NewPonosPerformerWithRpcServer(
 	cfg *PonosPerformerConfig,
-	worker worker.IWorker,
+	w worker.IWorker,
 	logger *zap.Logger,
 ) (*PonosPerformer, error) {
+	return NewPonosPerformerWithRpcServerAndContextWorker(cfg, worker.NewContextWorkerAdapter(w), logger)
+}
+
+func NewPonosPerformerWithRpcServerAndContextWorker(
+	cfg *PonosPerformerConfig,
+	worker worker.IContextWorker,
+	logger *zap.Logger,
+) (*PonosPerformer, error) {
+	if portStr := os.Getenv(PortEnvVar); portStr != "" {
+		port, err := strconv.Atoi(portStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", PortEnvVar, portStr, err)
+		}
+		cfg.Port = port
+	}
 	rpc, err := rpcServer.NewRpcServer(&rpcServer.RpcServerConfig{
 		GrpcPort: cfg.Port,
 	}, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create RPC server: %w", err)
 	}
-	return NewPonosPerformer(cfg, rpc, worker, logger), nil
+	return NewPonosPerformerWithContextWorker(cfg, rpc, worker, logger), nil
 }
 
 func (pp *PonosPerformer) registerHandlers() {
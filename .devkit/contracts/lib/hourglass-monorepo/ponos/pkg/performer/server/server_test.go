@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/rpcServer"
+	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+	"time"
+)
+
+type sleepyContextWorker struct {
+	sleep     time.Duration
+	cancelled chan struct{}
+}
+
+func (w *sleepyContextWorker) ValidateTask(ctx context.Context, task *performerV1.TaskRequest) error {
+	return nil
+}
+
+func (w *sleepyContextWorker) HandleTask(ctx context.Context, task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	select {
+	case <-time.After(w.sleep):
+		return &performerV1.TaskResponse{TaskId: task.TaskId, Result: []byte("done")}, nil
+	case <-ctx.Done():
+		close(w.cancelled)
+		return nil, ctx.Err()
+	}
+}
+
+type sleepyWorker struct {
+	sleep time.Duration
+}
+
+func (w *sleepyWorker) ValidateTask(task *performerV1.TaskRequest) error {
+	return nil
+}
+
+func (w *sleepyWorker) HandleTask(task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	time.Sleep(w.sleep)
+	return &performerV1.TaskResponse{TaskId: task.TaskId, Result: []byte("done")}, nil
+}
+
+func newTestRpcServer(t *testing.T, l *zap.Logger) *rpcServer.RpcServer {
+	rpc, err := rpcServer.NewRpcServer(&rpcServer.RpcServerConfig{GrpcPort: 0}, l)
+	if err != nil {
+		t.Fatalf("Failed to create rpc server: %v", err)
+	}
+	return rpc
+}
+
+func Test_PonosPerformerTimeout(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	task := &performerV1.TaskRequest{TaskId: []byte("0x1234taskId"), Payload: []byte("payload")}
+
+	t.Run("context worker completes within the timeout", func(t *testing.T) {
+		w := &sleepyContextWorker{sleep: 10 * time.Millisecond, cancelled: make(chan struct{})}
+		pp := NewPonosPerformerWithContextWorker(&PonosPerformerConfig{Timeout: time.Second}, newTestRpcServer(t, l), w, l)
+
+		res, err := pp.ExecuteTask(context.Background(), task)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("done"), res.Result)
+	})
+
+	t.Run("context worker observes the deadline and DeadlineExceeded is returned", func(t *testing.T) {
+		w := &sleepyContextWorker{sleep: 5 * time.Second, cancelled: make(chan struct{})}
+		pp := NewPonosPerformerWithContextWorker(&PonosPerformerConfig{Timeout: 50 * time.Millisecond}, newTestRpcServer(t, l), w, l)
+
+		res, err := pp.ExecuteTask(context.Background(), task)
+		assert.Nil(t, res)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+
+		select {
+		case <-w.cancelled:
+		case <-time.After(time.Second):
+			t.Fatalf("worker never observed the cancelled context")
+		}
+	})
+
+	t.Run("legacy worker is bounded by the timeout through the adapter", func(t *testing.T) {
+		w := &sleepyWorker{sleep: 2 * time.Second}
+		pp := NewPonosPerformer(&PonosPerformerConfig{Timeout: 50 * time.Millisecond}, newTestRpcServer(t, l), w, l)
+
+		start := time.Now()
+		res, err := pp.ExecuteTask(context.Background(), task)
+		assert.Nil(t, res)
+		assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("cancelled caller context returns Canceled", func(t *testing.T) {
+		w := &sleepyContextWorker{sleep: 5 * time.Second, cancelled: make(chan struct{})}
+		pp := NewPonosPerformerWithContextWorker(&PonosPerformerConfig{Timeout: 5 * time.Second}, newTestRpcServer(t, l), w, l)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+		_, err := pp.ExecuteTask(ctx, task)
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	})
+}
@@ -1,6 +1,7 @@
 package worker
 
 import (
+	"context"
 	performerV1 "github.com/Layr-Labs/protocol-apis/gen/protos/eigenlayer/hourglass/v1/performer"
 )
 
@@ -8,3 +9,64 @@ type IWorker interface {
 	HandleTask(task *performerV1.TaskRequest) (*performerV1.TaskResponse, error)
 	ValidateTask(task *performerV1.TaskRequest) error
 }
+
+// IContextWorker is the context-aware variant of IWorker. The context passed to each call
+// is cancelled when the task is abandoned or when the performer's task timeout elapses,
+// allowing long-running AVS logic to stop early and clean up.
+type IContextWorker interface {
+	HandleTask(ctx context.Context, task *performerV1.TaskRequest) (*performerV1.TaskResponse, error)
+	ValidateTask(ctx context.Context, task *performerV1.TaskRequest) error
+}
+
+// ContextWorkerAdapter wraps an existing IWorker so that it satisfies IContextWorker.
+//
+// The wrapped worker cannot observe the context, so the adapter runs each call in its own
+// goroutine and returns ctx.Err() as soon as the context is done. The underlying call keeps
+// running in the background until it returns on its own.
+type ContextWorkerAdapter struct {
+	worker IWorker
+}
+
+func NewContextWorkerAdapter(worker IWorker) *ContextWorkerAdapter {
+	return &ContextWorkerAdapter{
+		worker: worker,
+	}
+}
+
+func (cwa *ContextWorkerAdapter) ValidateTask(ctx context.Context, task *performerV1.TaskRequest) error {
+	_, err := runWithContext(ctx, func() (struct{}, error) {
+		return struct{}{}, cwa.worker.ValidateTask(task)
+	})
+	return err
+}
+
+func (cwa *ContextWorkerAdapter) HandleTask(ctx context.Context, task *performerV1.TaskRequest) (*performerV1.TaskResponse, error) {
+	return runWithContext(ctx, func() (*performerV1.TaskResponse, error) {
+		return cwa.worker.HandleTask(task)
+	})
+}
+
+func runWithContext[T any](ctx context.Context, fn func() (T, error)) (T, error) {
+	var empty T
+	if err := ctx.Err(); err != nil {
+		return empty, err
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	// buffered so the goroutine can always exit, even if nobody is left to read the result
+	resultChan := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		resultChan <- result{value: value, err: err}
+	}()
+
+	select {
+	case res := <-resultChan:
+		return res.value, res.err
+	case <-ctx.Done():
+		return empty, ctx.Err()
+	}
+}
@@ -4,25 +4,63 @@ import (
 	"context"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"math/big"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
 )
 
 type Operator struct {
-	Address   string
+	Address string
+
+	// PublicKey is a *bn254.PublicKey or a *bls381.PublicKey, matching the aggregator's signing curve
+	PublicKey signing.PublicKey
+
+	// Weight is the operator's allocated stake at the task's reference block. When no operator has a
+	// weight, every operator counts equally.
+	Weight *big.Int
+}
+
+// MaxThresholdBips is a threshold of 100%, expressed in basis points like the on-chain task config
+const MaxThresholdBips = 10_000
+
+// OperatorTableEntry is an operator in the certificate verifier's operator table of an operator set,
+// as read from the chain at the task's reference block
+type OperatorTableEntry struct {
+	Address string
+
+	// OperatorId is the hash of the G1 public key the operator registered with the TaskAVSRegistrar
+	OperatorId [32]byte
+
+	// PublicKey is the operator's registered public key, including its G1 point
 	PublicKey *bn254.PublicKey
+
+	// Weights are the operator's stake weights, one per stake type
+	Weights []*big.Int
+}
+
+// NonSigner is an operator that did not sign the certificate, along with its position in the operator table
+type NonSigner struct {
+	OperatorIndex uint32
+
+	// OperatorId is only set when the aggregator has an operator table
+	OperatorId [32]byte
+	Operator   *Operator
 }
 
 // Error variables for input validation
 var (
 	ErrInvalidTaskId       = fmt.Errorf("taskId must not be empty")
 	ErrNoOperatorAddresses = fmt.Errorf("operatorAddresses must not be empty")
-	ErrInvalidThreshold    = fmt.Errorf("thresholdPercentage must be between 1 and 100")
+	ErrInvalidThreshold    = fmt.Errorf("thresholdBips must be between 1 and 10000")
+	ErrMissingWeight       = fmt.Errorf("either all or none of the operators must have a weight")
+	ErrNoStake             = fmt.Errorf("operators have no stake")
+	ErrThresholdNotMet     = fmt.Errorf("no response has reached the signing threshold")
 )
 
 type AggregatedCertificate struct {
@@ -35,77 +73,134 @@ type AggregatedCertificate struct {
 	// keccak256 hash of the task response
 	TaskResponseDigest []byte
 
-	// public keys for all operators that did not sign the task
-	NonSignersPubKeys []*bn254.PublicKey
+	// operators that did not sign the task, in operator table order
+	NonSigners []*NonSigner
+
+	// the operator table the non-signer indices refer to, empty when the aggregator had none
+	OperatorTable []*OperatorTableEntry
+
+	// public keys for all operators that did not sign the task, in operator table order
+	NonSignersPubKeys []signing.PublicKey
+
+	// public keys for all operators that were selected to participate in the task, in operator table order
+	AllOperatorsPubKeys []signing.PublicKey
 
-	// public keys for all operators that were selected to participate in the task
-	AllOperatorsPubKeys []*bn254.PublicKey
+	// the curve the operators signed with
+	SigningCurve string
 
-	// aggregated signature of the signers
+	// aggregated signature of the signers, set for bn254
 	SignersSignature *bn254.Signature
 
-	// aggregated public key of the signers
+	// aggregated public key of the signers, set for bn254
 	SignersPublicKey *bn254.G2Point
 
+	// aggregated signature and public key of the signers, set for bls381
+	BLS381 *BLS381Aggregate
+
+	// operators that signed a different response than the one in the certificate
+	DisagreeingOperators []*OperatorDisagreement
+
 	// the time the certificate was signed
 	SignedAt *time.Time
 }
 
 // TaskResultAggregator represents the data needed to initialize a new aggregation task window.
 type TaskResultAggregator struct {
-	mu                  sync.Mutex
-	TaskId              string
-	TaskCreatedBlock    uint64
-	OperatorSetId       uint32
-	ThresholdPercentage uint8
-	TaskData            []byte
-	TaskExpirationTime  *time.Time
-	Operators           []*Operator
-	ReceivedSignatures  map[string]*ReceivedResponseWithDigest // operator address -> signature
-	AggregatePublicKey  *bn254.PublicKey
-
-	aggregatedOperators *aggregatedOperators
-	// Add more fields as needed for aggregation
+	mu                 sync.Mutex
+	TaskId             string
+	TaskCreatedBlock   uint64
+	OperatorSetId      uint32
+	ThresholdBips      uint16
+	TaskData           []byte
+	TaskExpirationTime *time.Time
+	Operators          []*Operator
+	OperatorTable      []*OperatorTableEntry
+	ReceivedSignatures map[string]*ReceivedResponseWithDigest // operator address -> signature
+	AggregatePublicKey signing.PublicKey
+	SigningCurve       string
+
+	curve signingCurve
+
+	// TotalWeight is the combined weight of all operators the task was sent to, or of every operator
+	// in the operator table once one is set
+	TotalWeight *big.Int
+
+	// digestBuckets groups the received signatures by response digest (hex digest --> signers)
+	digestBuckets map[string]*aggregatedOperators
+
+	// consensusDigest is the digest of the first response to reach the signing threshold
+	consensusDigest string
 }
 
-// NewTaskResultAggregator initializes a new aggregation certificate for a task window.
+// NewTaskResultAggregator initializes a new bn254 aggregation certificate for a task window.
 // All required data must be provided as arguments; no network or chain calls are performed.
 func NewTaskResultAggregator(
 	ctx context.Context,
 	taskId string,
 	taskCreatedBlock uint64,
 	operatorSetId uint32,
-	thresholdPercentage uint8,
+	thresholdBips uint16,
 	taskData []byte,
 	taskExpirationTime *time.Time,
 	operators []*Operator,
 ) (*TaskResultAggregator, error) {
+	return NewTaskResultAggregatorForCurve(ctx, SigningCurve_BN254, taskId, taskCreatedBlock, operatorSetId, thresholdBips, taskData, taskExpirationTime, operators)
+}
+
+// NewTaskResultAggregatorForCurve initializes a new aggregation certificate for a task window whose
+// operators sign with signingCurve (bn254 or bls381).
+func NewTaskResultAggregatorForCurve(
+	ctx context.Context,
+	signingCurve string,
+	taskId string,
+	taskCreatedBlock uint64,
+	operatorSetId uint32,
+	thresholdBips uint16,
+	taskData []byte,
+	taskExpirationTime *time.Time,
+	operators []*Operator,
+) (*TaskResultAggregator, error) {
+	curve, err := newSigningCurve(signingCurve)
+	if err != nil {
+		return nil, err
+	}
 	if len(taskId) == 0 {
 		return nil, ErrInvalidTaskId
 	}
 	if len(operators) == 0 {
 		return nil, ErrNoOperatorAddresses
 	}
-	if thresholdPercentage == 0 || thresholdPercentage > 100 {
+	if thresholdBips == 0 || thresholdBips > MaxThresholdBips {
 		return nil, ErrInvalidThreshold
 	}
+	totalWeight, err := totalOperatorWeight(operators)
+	if err != nil {
+		return nil, err
+	}
 
-	aggPub, err := AggregatePublicKeys(util.Map(operators, func(o *Operator, i uint64) *bn254.PublicKey {
-		return o.PublicKey
-	}))
+	for _, operator := range operators {
+		if err := curve.checkPublicKey(operator); err != nil {
+			return nil, err
+		}
+	}
+
+	aggPub, err := curve.aggregatePublicKeys(operators)
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate public keys: %w", err)
 	}
 
 	cert := &TaskResultAggregator{
-		TaskId:              taskId,
-		TaskCreatedBlock:    taskCreatedBlock,
-		OperatorSetId:       operatorSetId,
-		ThresholdPercentage: thresholdPercentage,
-		TaskData:            taskData,
-		TaskExpirationTime:  taskExpirationTime,
-		Operators:           operators,
-		AggregatePublicKey:  aggPub,
+		TaskId:             taskId,
+		TaskCreatedBlock:   taskCreatedBlock,
+		OperatorSetId:      operatorSetId,
+		ThresholdBips:      thresholdBips,
+		TotalWeight:        totalWeight,
+		TaskData:           taskData,
+		TaskExpirationTime: taskExpirationTime,
+		Operators:          operators,
+		AggregatePublicKey: aggPub,
+		SigningCurve:       strings.ToLower(signingCurve),
+		curve:              curve,
 	}
 	return cert, nil
 }
@@ -118,39 +213,108 @@ type ReceivedResponseWithDigest struct {
 	TaskResult *types.TaskResult
 
 	// signature is the signature of the task result from the operator signed with their bls key
-	Signature *bn254.Signature
+	Signature Signature
 
 	// digest is a keccak256 hash of the task result
 	Digest []byte
 }
 
+// aggregatedOperators aggregates the signatures of all operators that returned the same response
 type aggregatedOperators struct {
-	// aggregated public keys of signers
-	signersG2 *bn254.G2Point
-
-	// aggregated signatures of signers
-	signersAggSig *bn254.Signature
+	// aggregated signatures and public keys of signers
+	aggregate signatureAggregate
 
 	// operators that have signed (operatorAddress --> true)
 	signersOperatorSet map[string]bool
 
-	// simple count of signers. eventually this could represent stake weight or something
-	totalSigners int
+	// combined weight of the signers
+	signedWeight *big.Int
 
-	lastReceivedResponse *ReceivedResponseWithDigest
+	// the first response received with this digest. All responses in a bucket have the same output.
+	response *ReceivedResponseWithDigest
 }
 
-func (tra *TaskResultAggregator) SigningThresholdMet() bool {
-	// Check if threshold is met (by count)
-	required := int((float64(tra.ThresholdPercentage) / 100.0) * float64(len(tra.Operators)))
-	if required == 0 {
-		required = 1 // Always require at least one
+// OperatorDisagreement describes an operator whose response differs from the one the certificate was built from
+type OperatorDisagreement struct {
+	OperatorAddress string
+
+	// Digest is the keccak256 hash of the operator's response
+	Digest []byte
+}
+
+// bucketThresholdMet checks signedWeight / TotalWeight >= ThresholdBips / 10000, the same proportion
+// the certificate verifier checks on chain
+func (tra *TaskResultAggregator) bucketThresholdMet(bucket *aggregatedOperators) bool {
+	signed := new(big.Int).Mul(bucket.signedWeight, big.NewInt(MaxThresholdBips))
+	required := new(big.Int).Mul(tra.TotalWeight, big.NewInt(int64(tra.ThresholdBips)))
+	return signed.Cmp(required) >= 0
+}
+
+// totalOperatorWeight sums the operators' weights. Operators without weights count as one each.
+func totalOperatorWeight(operators []*Operator) (*big.Int, error) {
+	weighted := util.Filter(operators, func(o *Operator) bool {
+		return o.Weight != nil
+	})
+	if len(weighted) == 0 {
+		return big.NewInt(int64(len(operators))), nil
+	}
+	if len(weighted) != len(operators) {
+		return nil, ErrMissingWeight
+	}
+	total := new(big.Int)
+	for _, o := range operators {
+		if o.Weight.Sign() < 0 {
+			return nil, fmt.Errorf("operator %s has a negative weight", o.Address)
+		}
+		total.Add(total, o.Weight)
+	}
+	if total.Sign() == 0 {
+		return nil, ErrNoStake
+	}
+	return total, nil
+}
+
+// totalTableWeight sums the weights of every operator in the table across all stake types. When the
+// aggregator's operators are not weighted, every operator in the table counts as one.
+func totalTableWeight(table []*OperatorTableEntry, weighted bool) (*big.Int, error) {
+	if !weighted {
+		return big.NewInt(int64(len(table))), nil
+	}
+	total := new(big.Int)
+	for _, entry := range table {
+		if len(entry.Weights) == 0 {
+			return nil, ErrMissingWeight
+		}
+		for _, weight := range entry.Weights {
+			if weight.Sign() < 0 {
+				return nil, fmt.Errorf("operator %s has a negative weight", entry.Address)
+			}
+			total.Add(total, weight)
+		}
+	}
+	if total.Sign() == 0 {
+		return nil, ErrNoStake
 	}
-	return tra.aggregatedOperators.totalSigners >= required
+	return total, nil
+}
+
+func operatorWeight(operator *Operator) *big.Int {
+	if operator.Weight == nil {
+		return big.NewInt(1)
+	}
+	return operator.Weight
+}
+
+// SigningThresholdMet returns true once enough operators agree on the same response
+func (tra *TaskResultAggregator) SigningThresholdMet() bool {
+	tra.mu.Lock()
+	defer tra.mu.Unlock()
+	return tra.consensusDigest != ""
 }
 
 // ProcessNewSignature processes a new signature submission from an operator.
-// Returns true if the threshold is met after this submission, false otherwise.
+// Signatures are grouped by the digest of the operator's response, so that only operators that
+// agree on the same output are aggregated together.
 func (tra *TaskResultAggregator) ProcessNewSignature(
 	ctx context.Context,
 	taskId string,
@@ -196,103 +360,175 @@ func (tra *TaskResultAggregator) ProcessNewSignature(
 
 	tra.ReceivedSignatures[taskResponse.OperatorAddress] = rr
 
-	// Begin aggregating signatures and public keys.
-	// The lastReceivedResponse will end up being the value used to for the final certificate.
-	//
-	// TODO: probably need some kind of comparison on results, otherwise the last operator in
-	// will always be the one that is used for the final certificate and could potentially be
-	// wrong or malicious.
-	if tra.aggregatedOperators == nil {
-		// no signers yet, initialize the aggregated operators
-		tra.aggregatedOperators = &aggregatedOperators{
-			// operator's public key to start an aggregated public key
-			signersG2: bn254.NewZeroG2Point().AddPublicKey(operator.PublicKey),
-
-			// signature of the task result payload
-			signersAggSig: sig,
-
-			// initialize the map of signers (operatorAddress --> true) to track who actually signed
-			signersOperatorSet: map[string]bool{taskResponse.OperatorAddress: true},
-
-			// initialize the count of signers (could eventually be weight or something else)
-			totalSigners: 1,
-
-			// store the last received response
-			lastReceivedResponse: rr,
+	if tra.digestBuckets == nil {
+		tra.digestBuckets = make(map[string]*aggregatedOperators)
+	}
+	digestKey := hexutil.Encode(digest)
+	bucket, ok := tra.digestBuckets[digestKey]
+	if !ok {
+		// first operator with this response, start a new aggregate
+		bucket = &aggregatedOperators{
+			aggregate:          tra.curve.newAggregate(),
+			signersOperatorSet: make(map[string]bool),
+			signedWeight:       new(big.Int),
+			response:           rr,
 		}
-	} else {
-		tra.aggregatedOperators.signersG2.AddPublicKey(operator.PublicKey)
-		tra.aggregatedOperators.signersAggSig.Add(sig)
-		tra.aggregatedOperators.signersOperatorSet[taskResponse.OperatorAddress] = true
-		tra.aggregatedOperators.totalSigners++
-		tra.aggregatedOperators.lastReceivedResponse = rr
+		tra.digestBuckets[digestKey] = bucket
+	}
+	bucket.aggregate.add(operator, sig)
+	bucket.signersOperatorSet[taskResponse.OperatorAddress] = true
+	bucket.signedWeight.Add(bucket.signedWeight, operatorWeight(operator))
+
+	// the first response to reach the threshold is the one the certificate is built from
+	if tra.consensusDigest == "" && tra.bucketThresholdMet(bucket) {
+		tra.consensusDigest = digestKey
 	}
 
 	return nil
 }
 
+// Disagreements returns the operators whose response differs from the one that reached the
+// signing threshold. It returns nil while no response has reached the threshold.
+func (tra *TaskResultAggregator) Disagreements() []*OperatorDisagreement {
+	tra.mu.Lock()
+	defer tra.mu.Unlock()
+	return tra.disagreementsLocked()
+}
+
+func (tra *TaskResultAggregator) disagreementsLocked() []*OperatorDisagreement {
+	if tra.consensusDigest == "" {
+		return nil
+	}
+	disagreements := make([]*OperatorDisagreement, 0)
+	for _, operator := range tra.Operators {
+		rr, ok := tra.ReceivedSignatures[operator.Address]
+		if !ok || hexutil.Encode(rr.Digest) == tra.consensusDigest {
+			continue
+		}
+		disagreements = append(disagreements, &OperatorDisagreement{
+			OperatorAddress: operator.Address,
+			Digest:          rr.Digest,
+		})
+	}
+	return disagreements
+}
+
 // VerifyResponseSignature verifies that the signature of the response is valid against
 // the operators public key.
-func (tra *TaskResultAggregator) VerifyResponseSignature(taskResponse *types.TaskResult, operator *Operator) (*bn254.Signature, []byte, error) {
+func (tra *TaskResultAggregator) VerifyResponseSignature(taskResponse *types.TaskResult, operator *Operator) (Signature, []byte, error) {
 	digestBytes := util.GetKeccak256Digest(taskResponse.Output)
-	sig, err := bn254.NewSignatureFromBytes(taskResponse.Signature)
+	sig, err := tra.curve.verify(operator, taskResponse.Signature, digestBytes[:])
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create signature from bytes: %w", err)
-	}
-
-	if verified, err := sig.Verify(operator.PublicKey, digestBytes[:]); err != nil {
-		return nil, nil, fmt.Errorf("signature verification failed: %w", err)
-	} else if !verified {
-		return nil, nil, fmt.Errorf("signature verification failed: signature does not match operator public key")
+		return nil, nil, err
 	}
 	return sig, digestBytes[:], nil
 }
 
-// GenerateFinalCertificate generates the final aggregated certificate for the task.
+// GenerateFinalCertificate generates the final aggregated certificate for the task from the operators
+// that agree on the response that reached the signing threshold. Operators that returned a different
+// response are treated as non-signers.
 func (tra *TaskResultAggregator) GenerateFinalCertificate() (*AggregatedCertificate, error) {
-	// TODO(seanmcgary): nonSignerOperatorIds should be a list of operatorIds which is the hash of their public key
-	nonSignerOperatorIds := make([]*Operator, 0)
-	for _, operator := range tra.Operators {
-		if _, ok := tra.aggregatedOperators.signersOperatorSet[operator.Address]; !ok {
-			nonSignerOperatorIds = append(nonSignerOperatorIds, operator)
-		}
-	}
-
-	// TODO: add this based on the avs registry
-	// the contract requires a sorted nonSignersOperatorIds
-	// sort.SliceStable(nonSignerOperatorIds, func(i, j int) bool {
-	// 	iOprInt := new(big.Int).SetBytes(nonSignerOperatorIds[i][:])
-	// 	jOprInt := new(big.Int).SetBytes(nonSignerOperatorIds[j][:])
-	// 	return iOprInt.Cmp(jOprInt) == -1
-	// })
+	tra.mu.Lock()
+	defer tra.mu.Unlock()
 
-	nonSignerPublicKeys := make([]*bn254.PublicKey, 0)
-	for _, operatorId := range nonSignerOperatorIds {
-		operator := util.Find(tra.Operators, func(op *Operator) bool {
-			return strings.EqualFold(op.Address, operatorId.Address)
-		})
-		nonSignerPublicKeys = append(nonSignerPublicKeys, operator.PublicKey)
+	if tra.consensusDigest == "" {
+		return nil, ErrThresholdNotMet
 	}
+	consensus := tra.digestBuckets[tra.consensusDigest]
 
-	allPublicKeys := util.Map(tra.Operators, func(o *Operator, i uint64) *bn254.PublicKey {
+	nonSigners := tra.nonSignersLocked(consensus)
+	nonSignerPublicKeys := util.Map(nonSigners, func(ns *NonSigner, i uint64) signing.PublicKey {
+		return ns.Operator.PublicKey
+	})
+	allPublicKeys := util.Map(tra.Operators, func(o *Operator, i uint64) signing.PublicKey {
 		return o.PublicKey
 	})
+	if len(tra.OperatorTable) > 0 {
+		allPublicKeys = util.Map(tra.OperatorTable, func(e *OperatorTableEntry, i uint64) signing.PublicKey {
+			return e.PublicKey
+		})
+	}
 
 	taskIdBytes, err := hexutil.Decode(tra.TaskId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode taskId: %w", err)
 	}
 
-	return &AggregatedCertificate{
-		TaskId:              taskIdBytes,
-		TaskResponse:        tra.aggregatedOperators.lastReceivedResponse.TaskResult.Output,
-		TaskResponseDigest:  tra.aggregatedOperators.lastReceivedResponse.Digest,
-		NonSignersPubKeys:   nonSignerPublicKeys,
-		AllOperatorsPubKeys: allPublicKeys,
-		SignersPublicKey:    tra.aggregatedOperators.signersG2,
-		SignersSignature:    tra.aggregatedOperators.signersAggSig,
-		SignedAt:            new(time.Time),
-	}, nil
+	signedAt := time.Now().UTC()
+	cert := &AggregatedCertificate{
+		TaskId:               taskIdBytes,
+		TaskResponse:         consensus.response.TaskResult.Output,
+		TaskResponseDigest:   consensus.response.Digest,
+		NonSigners:           nonSigners,
+		OperatorTable:        tra.OperatorTable,
+		NonSignersPubKeys:    nonSignerPublicKeys,
+		AllOperatorsPubKeys:  allPublicKeys,
+		SigningCurve:         tra.SigningCurve,
+		DisagreeingOperators: tra.disagreementsLocked(),
+		SignedAt:             &signedAt,
+	}
+	if err := consensus.aggregate.setCertificate(cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// SetOperatorTable sets the operator table of the task's operator set. Non-signers are then reported by
+// their index in it, including operators in the table that the task was not sent to. The signing
+// threshold is then measured against the weight of the whole table, which is what the certificate
+// verifier checks the signers' stake against.
+func (tra *TaskResultAggregator) SetOperatorTable(table []*OperatorTableEntry) error {
+	tra.mu.Lock()
+	defer tra.mu.Unlock()
+	totalWeight, err := totalTableWeight(table, tra.Operators[0].Weight != nil)
+	if err != nil {
+		return err
+	}
+	tra.OperatorTable = table
+	tra.TotalWeight = totalWeight
+	return nil
+}
+
+// nonSignersLocked returns the operators that did not sign the consensus response in ascending index
+// order, which is the order the certificate verifier requires the non-signer indices in. With an
+// operator table, the index is the operator's position in the table as returned by the operator set's
+// members, which is also its leaf index in the verifier's operator info tree. Without an operator
+// table, operators are indexed in the order the aggregator was given them.
+func (tra *TaskResultAggregator) nonSignersLocked(consensus *aggregatedOperators) []*NonSigner {
+	nonSigners := make([]*NonSigner, 0)
+	if len(tra.OperatorTable) == 0 {
+		for i, operator := range tra.Operators {
+			if _, ok := consensus.signersOperatorSet[operator.Address]; ok {
+				continue
+			}
+			nonSigners = append(nonSigners, &NonSigner{
+				OperatorIndex: uint32(i),
+				Operator:      operator,
+			})
+		}
+		return nonSigners
+	}
+
+	for i, entry := range tra.OperatorTable {
+		operator := util.Find(tra.Operators, func(o *Operator) bool {
+			return strings.EqualFold(o.Address, entry.Address)
+		})
+		if operator == nil {
+			// the task was not sent to the operator, so it did not sign either
+			operator = &Operator{
+				Address:   entry.Address,
+				PublicKey: entry.PublicKey,
+			}
+		} else if _, ok := consensus.signersOperatorSet[operator.Address]; ok {
+			continue
+		}
+		nonSigners = append(nonSigners, &NonSigner{
+			OperatorIndex: uint32(i),
+			OperatorId:    entry.OperatorId,
+			Operator:      operator,
+		})
+	}
+	return nonSigners
 }
 
 // AggregatePublicKeys aggregates a list of public keys into a single public key.
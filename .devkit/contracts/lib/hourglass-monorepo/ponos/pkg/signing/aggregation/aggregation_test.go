@@ -3,9 +3,12 @@ package aggregation
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bls381"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
@@ -36,9 +39,9 @@ func Test_Aggregation(t *testing.T) {
 	agg, err := NewTaskResultAggregator(
 		context.Background(),
 		taskId,
-		100, // taskCreatedBlock
-		1,   // operatorSetId
-		75,  // thresholdPercentage (3/4)
+		100,   // taskCreatedBlock
+		1,     // operatorSetId
+		7_500, // thresholdBips (3/4)
 		taskData,
 		&deadline,
 		operators,
@@ -69,7 +72,7 @@ func Test_Aggregation(t *testing.T) {
 		require.NoError(t, err)
 		taskResult.Signature = sig.Bytes()
 		individualSigs[i] = sig
-		remainingPubKeys[i] = operator.PublicKey
+		remainingPubKeys[i] = operator.PublicKey.(*bn254.PublicKey)
 		remainingSigs[i] = sig
 
 		// Process the signature
@@ -109,9 +112,391 @@ func Test_Aggregation(t *testing.T) {
 
 	// Test: Verify that the non-signer's signature is not included
 	// Create a new signature array including the non-signer's signature
-	allSigs := append(remainingSigs, individualSigs[0])            // Add a duplicate signature
-	allPubKeys := append(remainingPubKeys, operators[3].PublicKey) // Add non-signer's public key
+	allSigs := append(remainingSigs, individualSigs[0])                               // Add a duplicate signature
+	allPubKeys := append(remainingPubKeys, operators[3].PublicKey.(*bn254.PublicKey)) // Add non-signer's public key
 	verified, err = bn254.BatchVerify(allPubKeys, cert.TaskResponseDigest, allSigs)
 	require.NoError(t, err)
 	assert.False(t, verified, "Verification should fail when including non-signer's public key")
 }
+
+func Test_AggregationConsensus(t *testing.T) {
+	operators := make([]*Operator, 4)
+	privateKeys := make([]*bn254.PrivateKey, 4)
+	for i := 0; i < 4; i++ {
+		privKey, pubKey, err := bn254.GenerateKeyPair()
+		require.NoError(t, err)
+		operators[i] = &Operator{
+			Address:   fmt.Sprintf("0x%d", i+1),
+			PublicKey: pubKey,
+		}
+		privateKeys[i] = privKey
+	}
+	taskId := "0x29cebefe301c6ce1bb36b58654fea275e1cacc83"
+	deadline := time.Now().Add(10 * time.Minute)
+
+	submit := func(t *testing.T, agg *TaskResultAggregator, i int, output []byte) {
+		digest := util.GetKeccak256Digest(output)
+		sig, err := privateKeys[i].Sign(digest[:])
+		require.NoError(t, err)
+		err = agg.ProcessNewSignature(context.Background(), taskId, &types.TaskResult{
+			OperatorAddress: operators[i].Address,
+			Output:          output,
+			Signature:       sig.Bytes(),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("builds the certificate from operators that agree on the output", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 5_000, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		honest := []byte("honest-response")
+		malicious := []byte("malicious-response")
+
+		submit(t, agg, 0, honest)
+		assert.False(t, agg.SigningThresholdMet())
+		_, err = agg.GenerateFinalCertificate()
+		assert.ErrorIs(t, err, ErrThresholdNotMet)
+
+		// a disagreeing operator does not count towards the honest response
+		submit(t, agg, 1, malicious)
+		assert.False(t, agg.SigningThresholdMet())
+		assert.Nil(t, agg.Disagreements())
+
+		submit(t, agg, 2, honest)
+		assert.True(t, agg.SigningThresholdMet())
+
+		// the last response received does not decide the certificate
+		submit(t, agg, 3, malicious)
+
+		cert, err := agg.GenerateFinalCertificate()
+		require.NoError(t, err)
+		assert.Equal(t, honest, cert.TaskResponse)
+		assert.Equal(t, 2, len(cert.NonSignersPubKeys))
+
+		signersPubKey, err := bn254.NewPublicKeyFromBytes(cert.SignersPublicKey.Marshal())
+		require.NoError(t, err)
+		verified, err := cert.SignersSignature.Verify(signersPubKey, cert.TaskResponseDigest)
+		require.NoError(t, err)
+		assert.True(t, verified, "Aggregated signature should only cover the agreeing operators")
+
+		maliciousDigest := util.GetKeccak256Digest(malicious)
+		require.Len(t, cert.DisagreeingOperators, 2)
+		assert.Equal(t, operators[1].Address, cert.DisagreeingOperators[0].OperatorAddress)
+		assert.Equal(t, operators[3].Address, cert.DisagreeingOperators[1].OperatorAddress)
+		assert.Equal(t, maliciousDigest[:], cert.DisagreeingOperators[0].Digest)
+	})
+
+	t.Run("does not meet the threshold when operators are split", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 7_500, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		submit(t, agg, 0, []byte("a"))
+		submit(t, agg, 1, []byte("a"))
+		submit(t, agg, 2, []byte("b"))
+		submit(t, agg, 3, []byte("b"))
+		assert.False(t, agg.SigningThresholdMet())
+	})
+}
+
+func Test_AggregationStakeWeighted(t *testing.T) {
+	weights := []int64{70, 10, 10, 10}
+	operators := make([]*Operator, len(weights))
+	privateKeys := make([]*bn254.PrivateKey, len(weights))
+	for i, weight := range weights {
+		privKey, pubKey, err := bn254.GenerateKeyPair()
+		require.NoError(t, err)
+		operators[i] = &Operator{
+			Address:   fmt.Sprintf("0x%d", i+1),
+			PublicKey: pubKey,
+			Weight:    big.NewInt(weight),
+		}
+		privateKeys[i] = privKey
+	}
+	taskId := "0x29cebefe301c6ce1bb36b58654fea275e1cacc83"
+	deadline := time.Now().Add(10 * time.Minute)
+	output := []byte("response")
+	digest := util.GetKeccak256Digest(output)
+
+	submit := func(t *testing.T, agg *TaskResultAggregator, i int) {
+		sig, err := privateKeys[i].Sign(digest[:])
+		require.NoError(t, err)
+		err = agg.ProcessNewSignature(context.Background(), taskId, &types.TaskResult{
+			OperatorAddress: operators[i].Address,
+			Output:          output,
+			Signature:       sig.Bytes(),
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("three of four operators do not meet the threshold without enough stake", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 6_600, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+		assert.Equal(t, big.NewInt(100), agg.TotalWeight)
+
+		submit(t, agg, 1)
+		submit(t, agg, 2)
+		submit(t, agg, 3)
+		assert.False(t, agg.SigningThresholdMet())
+	})
+
+	t.Run("a single operator with enough stake meets the threshold", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 6_600, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		submit(t, agg, 0)
+		assert.True(t, agg.SigningThresholdMet())
+	})
+
+	t.Run("rejects invalid weights and thresholds", func(t *testing.T) {
+		_, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 10_001, []byte("test-data"), &deadline, operators)
+		assert.ErrorIs(t, err, ErrInvalidThreshold)
+
+		partial := []*Operator{operators[0], {Address: "0x5", PublicKey: operators[1].PublicKey}}
+		_, err = NewTaskResultAggregator(context.Background(), taskId, 100, 1, 6_600, []byte("test-data"), &deadline, partial)
+		assert.ErrorIs(t, err, ErrMissingWeight)
+
+		noStake := []*Operator{{Address: "0x1", PublicKey: operators[0].PublicKey, Weight: big.NewInt(0)}}
+		_, err = NewTaskResultAggregator(context.Background(), taskId, 100, 1, 6_600, []byte("test-data"), &deadline, noStake)
+		assert.ErrorIs(t, err, ErrNoStake)
+	})
+}
+
+func Test_AggregationNonSigners(t *testing.T) {
+	operators := make([]*Operator, 5)
+	privateKeys := make([]*bn254.PrivateKey, 5)
+	for i := range operators {
+		privKey, pubKey, err := bn254.GenerateKeyPair()
+		require.NoError(t, err)
+		operators[i] = &Operator{
+			Address:   fmt.Sprintf("0x%d", i+1),
+			PublicKey: pubKey,
+			Weight:    big.NewInt(10),
+		}
+		privateKeys[i] = privKey
+	}
+	taskId := "0x29cebefe301c6ce1bb36b58654fea275e1cacc83"
+	deadline := time.Now().Add(10 * time.Minute)
+
+	signWith := func(t *testing.T, agg *TaskResultAggregator, signers ...int) {
+		output := []byte("response")
+		digest := util.GetKeccak256Digest(output)
+		for _, i := range signers {
+			sig, err := privateKeys[i].Sign(digest[:])
+			require.NoError(t, err)
+			require.NoError(t, agg.ProcessNewSignature(context.Background(), taskId, &types.TaskResult{
+				OperatorAddress: operators[i].Address,
+				Output:          output,
+				Signature:       sig.Bytes(),
+			}))
+		}
+		require.True(t, agg.SigningThresholdMet())
+	}
+
+	t.Run("non-signers are indexed by their position in the operator table", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 4_000, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		// the table orders operators as the chain does, which is not the order they were given in
+		table := make([]*OperatorTableEntry, 0, len(operators))
+		for _, i := range []int{3, 0, 4, 2, 1} {
+			table = append(table, &OperatorTableEntry{
+				Address:    operators[i].Address,
+				OperatorId: [32]byte{byte(i + 1)},
+				PublicKey:  operators[i].PublicKey.(*bn254.PublicKey),
+				Weights:    []*big.Int{operators[i].Weight},
+			})
+		}
+		require.NoError(t, agg.SetOperatorTable(table))
+		signWith(t, agg, 0, 2)
+
+		cert, err := agg.GenerateFinalCertificate()
+		require.NoError(t, err)
+		require.Len(t, cert.NonSigners, 3)
+		assert.Equal(t, []uint32{0, 2, 4}, util.Map(cert.NonSigners, func(ns *NonSigner, i uint64) uint32 {
+			return ns.OperatorIndex
+		}))
+		for i, ns := range cert.NonSigners {
+			assert.Equal(t, table[ns.OperatorIndex].Address, ns.Operator.Address)
+			assert.Equal(t, table[ns.OperatorIndex].OperatorId, ns.OperatorId)
+			assert.Equal(t, ns.Operator.PublicKey, cert.NonSignersPubKeys[i])
+		}
+		assert.Equal(t, table, cert.OperatorTable)
+		assert.Equal(t, signing.PublicKey(table[0].PublicKey), cert.AllOperatorsPubKeys[0])
+		require.NotNil(t, cert.SignedAt)
+		assert.WithinDuration(t, time.Now(), *cert.SignedAt, time.Minute)
+	})
+
+	t.Run("operators in the table that were not sent the task are non-signers", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 4_000, []byte("test-data"), &deadline, operators[:3])
+		require.NoError(t, err)
+
+		table := make([]*OperatorTableEntry, 0, len(operators))
+		for i, op := range operators {
+			table = append(table, &OperatorTableEntry{
+				Address:    op.Address,
+				OperatorId: [32]byte{byte(i + 1)},
+				PublicKey:  op.PublicKey.(*bn254.PublicKey),
+				Weights:    []*big.Int{op.Weight},
+			})
+		}
+		require.NoError(t, agg.SetOperatorTable(table))
+		signWith(t, agg, 0, 1)
+
+		cert, err := agg.GenerateFinalCertificate()
+		require.NoError(t, err)
+		assert.Equal(t, []string{operators[2].Address, operators[3].Address, operators[4].Address}, util.Map(cert.NonSigners, func(ns *NonSigner, i uint64) string {
+			return ns.Operator.Address
+		}))
+		assert.Equal(t, operators[4].PublicKey, cert.NonSigners[2].Operator.PublicKey)
+	})
+
+	t.Run("operators in the table that were not sent the task count towards the threshold", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 7_000, []byte("test-data"), &deadline, operators[:3])
+		require.NoError(t, err)
+
+		table := make([]*OperatorTableEntry, 0, len(operators))
+		for i, op := range operators {
+			table = append(table, &OperatorTableEntry{
+				Address:    op.Address,
+				OperatorId: [32]byte{byte(i + 1)},
+				PublicKey:  op.PublicKey.(*bn254.PublicKey),
+				Weights:    []*big.Int{op.Weight},
+			})
+		}
+		require.NoError(t, agg.SetOperatorTable(table))
+		assert.Equal(t, big.NewInt(50), agg.TotalWeight)
+
+		// every operator the task was sent to signs, but that is only 30 of the table's 50
+		output := []byte("response")
+		digest := util.GetKeccak256Digest(output)
+		for i := range 3 {
+			sig, err := privateKeys[i].Sign(digest[:])
+			require.NoError(t, err)
+			require.NoError(t, agg.ProcessNewSignature(context.Background(), taskId, &types.TaskResult{
+				OperatorAddress: operators[i].Address,
+				Output:          output,
+				Signature:       sig.Bytes(),
+			}))
+		}
+		assert.False(t, agg.SigningThresholdMet())
+		_, err = agg.GenerateFinalCertificate()
+		assert.ErrorIs(t, err, ErrThresholdNotMet)
+	})
+
+	t.Run("sums the weights of every stake type in the operator table", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 4_000, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		table := make([]*OperatorTableEntry, 0, len(operators))
+		for i, op := range operators {
+			table = append(table, &OperatorTableEntry{
+				Address:    op.Address,
+				OperatorId: [32]byte{byte(i + 1)},
+				PublicKey:  op.PublicKey.(*bn254.PublicKey),
+				Weights:    []*big.Int{big.NewInt(6), big.NewInt(4)},
+			})
+		}
+		require.NoError(t, agg.SetOperatorTable(table))
+		assert.Equal(t, big.NewInt(50), agg.TotalWeight)
+
+		table[0].Weights = nil
+		assert.ErrorIs(t, agg.SetOperatorTable(table), ErrMissingWeight)
+	})
+
+	t.Run("without an operator table non-signers are indexed in the given order", func(t *testing.T) {
+		agg, err := NewTaskResultAggregator(context.Background(), taskId, 100, 1, 4_000, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+		signWith(t, agg, 0, 2)
+
+		cert, err := agg.GenerateFinalCertificate()
+		require.NoError(t, err)
+		assert.Equal(t, []uint32{1, 3, 4}, util.Map(cert.NonSigners, func(ns *NonSigner, i uint64) uint32 {
+			return ns.OperatorIndex
+		}))
+		assert.Empty(t, cert.OperatorTable)
+	})
+}
+
+func Test_AggregationBLS381(t *testing.T) {
+	operators := make([]*Operator, 4)
+	privateKeys := make([]*bls381.PrivateKey, 4)
+	for i := range operators {
+		privKey, pubKey, err := bls381.GenerateKeyPair()
+		require.NoError(t, err)
+		operators[i] = &Operator{
+			Address:   fmt.Sprintf("0x%d", i+1),
+			PublicKey: pubKey,
+		}
+		privateKeys[i] = privKey
+	}
+	taskId := "0x29cebefe301c6ce1bb36b58654fea275e1cacc83"
+	deadline := time.Now().Add(10 * time.Minute)
+
+	t.Run("aggregates bls381 signatures into a certificate", func(t *testing.T) {
+		agg, err := NewTaskResultAggregatorForCurve(context.Background(), SigningCurve_BLS381, taskId, 100, 1, 7_500, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		output := []byte("response")
+		digest := util.GetKeccak256Digest(output)
+		for i := 0; i < 3; i++ {
+			sig, err := privateKeys[i].Sign(digest[:])
+			require.NoError(t, err)
+			require.NoError(t, agg.ProcessNewSignature(context.Background(), taskId, &types.TaskResult{
+				OperatorAddress: operators[i].Address,
+				Output:          output,
+				Signature:       sig.Bytes(),
+			}))
+		}
+		require.True(t, agg.SigningThresholdMet())
+
+		cert, err := agg.GenerateFinalCertificate()
+		require.NoError(t, err)
+		assert.Equal(t, SigningCurve_BLS381, cert.SigningCurve)
+		assert.Nil(t, cert.SignersSignature)
+		require.NotNil(t, cert.BLS381)
+
+		verified, err := cert.BLS381.SignersSignature.Verify(cert.BLS381.SignersPublicKey, cert.TaskResponseDigest)
+		require.NoError(t, err)
+		assert.True(t, verified, "Aggregated signature verification failed")
+
+		require.Len(t, cert.NonSigners, 1)
+		assert.Equal(t, operators[3].Address, cert.NonSigners[0].Operator.Address)
+
+		blsCert, err := cert.BLS381Certificate()
+		require.NoError(t, err)
+		encoded, err := blsCert.Encode()
+		require.NoError(t, err)
+		decoded, err := DecodeBLS381Certificate(encoded)
+		require.NoError(t, err)
+		assert.Equal(t, blsCert, decoded)
+		assert.Equal(t, []uint32{cert.NonSigners[0].OperatorIndex}, decoded.NonSignerIndices)
+		assert.Equal(t, [][]byte{operators[3].PublicKey.Bytes()}, decoded.NonSignerPubkeys)
+	})
+
+	t.Run("rejects signatures that do not verify", func(t *testing.T) {
+		agg, err := NewTaskResultAggregatorForCurve(context.Background(), SigningCurve_BLS381, taskId, 100, 1, 7_500, []byte("test-data"), &deadline, operators)
+		require.NoError(t, err)
+
+		digest := util.GetKeccak256Digest([]byte("response"))
+		sig, err := privateKeys[1].Sign(digest[:])
+		require.NoError(t, err)
+		err = agg.ProcessNewSignature(context.Background(), taskId, &types.TaskResult{
+			OperatorAddress: operators[0].Address,
+			Output:          []byte("response"),
+			Signature:       sig.Bytes(),
+		})
+		assert.ErrorContains(t, err, "signature verification failed")
+	})
+
+	t.Run("rejects operators with keys on another curve", func(t *testing.T) {
+		_, pubKey, err := bn254.GenerateKeyPair()
+		require.NoError(t, err)
+		mixed := []*Operator{operators[0], {Address: "0x5", PublicKey: pubKey}}
+		_, err = NewTaskResultAggregatorForCurve(context.Background(), SigningCurve_BLS381, taskId, 100, 1, 7_500, []byte("test-data"), &deadline, mixed)
+		assert.ErrorContains(t, err, "does not have a bls381 public key")
+
+		_, err = NewTaskResultAggregatorForCurve(context.Background(), "secp256k1", taskId, 100, 1, 7_500, []byte("test-data"), &deadline, operators)
+		assert.ErrorIs(t, err, ErrUnsupportedCurve)
+	})
+}
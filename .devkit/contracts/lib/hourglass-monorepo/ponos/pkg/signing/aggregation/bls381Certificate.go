@@ -0,0 +1,97 @@
+package aggregation
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bls381"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// BLS381Aggregate is the aggregated signature and public key of the operators that signed a bls381 task
+type BLS381Aggregate struct {
+	SignersSignature *bls381.Signature
+	SignersPublicKey *bls381.PublicKey
+}
+
+// BLS381Certificate is the onchain representation of a bls381 certificate. It is ABI encoded as
+//
+//	(uint32 referenceTimestamp, bytes32 messageHash, bytes signature, bytes apk, uint32[] nonSignerIndices, bytes[] nonSignerPubkeys)
+//
+// where signature is the compressed G1 aggregate signature, apk the compressed G2 aggregate public
+// key of the signers and nonSignerPubkeys the non-signer public keys in nonSignerIndices order.
+type BLS381Certificate struct {
+	ReferenceTimestamp uint32
+	MessageHash        [32]byte
+	Signature          []byte
+	Apk                []byte
+	NonSignerIndices   []uint32
+	NonSignerPubkeys   [][]byte
+}
+
+var bls381CertificateArguments = func() abi.Arguments {
+	mustType := func(t string) abi.Type {
+		typ, err := abi.NewType(t, "", nil)
+		if err != nil {
+			panic(err)
+		}
+		return typ
+	}
+	return abi.Arguments{
+		{Name: "referenceTimestamp", Type: mustType("uint32")},
+		{Name: "messageHash", Type: mustType("bytes32")},
+		{Name: "signature", Type: mustType("bytes")},
+		{Name: "apk", Type: mustType("bytes")},
+		{Name: "nonSignerIndices", Type: mustType("uint32[]")},
+		{Name: "nonSignerPubkeys", Type: mustType("bytes[]")},
+	}
+}()
+
+// BLS381Certificate builds the onchain representation of a certificate aggregated on bls381
+func (ac *AggregatedCertificate) BLS381Certificate() (*BLS381Certificate, error) {
+	if ac.SigningCurve != SigningCurve_BLS381 || ac.BLS381 == nil {
+		return nil, fmt.Errorf("certificate was not aggregated with bls381 signatures")
+	}
+	if len(ac.TaskResponseDigest) != 32 {
+		return nil, fmt.Errorf("task response digest must be 32 bytes, got %d", len(ac.TaskResponseDigest))
+	}
+
+	cert := &BLS381Certificate{
+		Signature:        ac.BLS381.SignersSignature.Bytes(),
+		Apk:              ac.BLS381.SignersPublicKey.Bytes(),
+		NonSignerIndices: make([]uint32, 0, len(ac.NonSigners)),
+		NonSignerPubkeys: make([][]byte, 0, len(ac.NonSigners)),
+	}
+	if ac.SignedAt != nil {
+		cert.ReferenceTimestamp = uint32(ac.SignedAt.Unix())
+	}
+	copy(cert.MessageHash[:], ac.TaskResponseDigest)
+	for _, ns := range ac.NonSigners {
+		cert.NonSignerIndices = append(cert.NonSignerIndices, ns.OperatorIndex)
+		cert.NonSignerPubkeys = append(cert.NonSignerPubkeys, ns.Operator.PublicKey.Bytes())
+	}
+	return cert, nil
+}
+
+// Encode ABI encodes the certificate
+func (c *BLS381Certificate) Encode() ([]byte, error) {
+	return bls381CertificateArguments.Pack(
+		c.ReferenceTimestamp,
+		c.MessageHash,
+		c.Signature,
+		c.Apk,
+		c.NonSignerIndices,
+		c.NonSignerPubkeys,
+	)
+}
+
+// DecodeBLS381Certificate decodes a certificate produced by BLS381Certificate.Encode
+func DecodeBLS381Certificate(data []byte) (*BLS381Certificate, error) {
+	cert := &BLS381Certificate{}
+	values, err := bls381CertificateArguments.Unpack(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bls381 certificate: %w", err)
+	}
+	if err := bls381CertificateArguments.Copy(cert, values); err != nil {
+		return nil, fmt.Errorf("failed to decode bls381 certificate: %w", err)
+	}
+	return cert, nil
+}
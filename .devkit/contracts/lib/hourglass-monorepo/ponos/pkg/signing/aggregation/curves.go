@@ -0,0 +1,171 @@
+package aggregation
+
+import (
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bls381"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"strings"
+)
+
+// Signing curves supported by the TaskResultAggregator, matching AggregatorAvs.SigningCurve
+const (
+	SigningCurve_BN254  = "bn254"
+	SigningCurve_BLS381 = "bls381"
+)
+
+var ErrUnsupportedCurve = fmt.Errorf("unsupported signing curve")
+
+// Signature is an operator signature that was verified on the aggregator's curve
+type Signature interface {
+	Bytes() []byte
+}
+
+// signingCurve implements the parts of result aggregation that differ between curves
+type signingCurve interface {
+	// checkPublicKey returns an error if the operator's public key is not on the curve
+	checkPublicKey(operator *Operator) error
+
+	// verify parses an operator's signature and verifies it over digest
+	verify(operator *Operator, signature []byte, digest []byte) (Signature, error)
+
+	aggregatePublicKeys(operators []*Operator) (signing.PublicKey, error)
+
+	newAggregate() signatureAggregate
+}
+
+// signatureAggregate accumulates the signatures and public keys of operators that signed the same response
+type signatureAggregate interface {
+	add(operator *Operator, sig Signature)
+
+	// setCertificate fills in the curve specific fields of the certificate
+	setCertificate(cert *AggregatedCertificate) error
+}
+
+func newSigningCurve(name string) (signingCurve, error) {
+	switch strings.ToLower(name) {
+	case SigningCurve_BN254:
+		return &bn254Curve{}, nil
+	case SigningCurve_BLS381:
+		return &bls381Curve{}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurve, name)
+}
+
+type bn254Curve struct{}
+
+func (c *bn254Curve) checkPublicKey(operator *Operator) error {
+	if _, ok := operator.PublicKey.(*bn254.PublicKey); !ok {
+		return fmt.Errorf("operator %s does not have a bn254 public key", operator.Address)
+	}
+	return nil
+}
+
+func (c *bn254Curve) verify(operator *Operator, signature []byte, digest []byte) (Signature, error) {
+	sig, err := bn254.NewSignatureFromBytes(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature from bytes: %w", err)
+	}
+	if verified, err := sig.Verify(operator.PublicKey.(*bn254.PublicKey), digest); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	} else if !verified {
+		return nil, fmt.Errorf("signature verification failed: signature does not match operator public key")
+	}
+	return sig, nil
+}
+
+func (c *bn254Curve) aggregatePublicKeys(operators []*Operator) (signing.PublicKey, error) {
+	return bn254.AggregatePublicKeys(util.Map(operators, func(o *Operator, i uint64) *bn254.PublicKey {
+		return o.PublicKey.(*bn254.PublicKey)
+	}))
+}
+
+func (c *bn254Curve) newAggregate() signatureAggregate {
+	return &bn254Aggregate{}
+}
+
+type bn254Aggregate struct {
+	// aggregated public keys of signers
+	signersG2 *bn254.G2Point
+
+	// aggregated signatures of signers
+	signersAggSig *bn254.Signature
+}
+
+func (a *bn254Aggregate) add(operator *Operator, sig Signature) {
+	pubKey := operator.PublicKey.(*bn254.PublicKey)
+	if a.signersG2 == nil {
+		a.signersG2 = bn254.NewZeroG2Point().AddPublicKey(pubKey)
+		a.signersAggSig = sig.(*bn254.Signature)
+		return
+	}
+	a.signersG2.AddPublicKey(pubKey)
+	a.signersAggSig.Add(sig.(*bn254.Signature))
+}
+
+func (a *bn254Aggregate) setCertificate(cert *AggregatedCertificate) error {
+	cert.SignersPublicKey = a.signersG2
+	cert.SignersSignature = a.signersAggSig
+	return nil
+}
+
+type bls381Curve struct{}
+
+func (c *bls381Curve) checkPublicKey(operator *Operator) error {
+	if _, ok := operator.PublicKey.(*bls381.PublicKey); !ok {
+		return fmt.Errorf("operator %s does not have a bls381 public key", operator.Address)
+	}
+	return nil
+}
+
+func (c *bls381Curve) verify(operator *Operator, signature []byte, digest []byte) (Signature, error) {
+	sig, err := bls381.NewSignatureFromBytes(signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signature from bytes: %w", err)
+	}
+	if verified, err := sig.Verify(operator.PublicKey.(*bls381.PublicKey), digest); err != nil {
+		return nil, fmt.Errorf("signature verification failed: %w", err)
+	} else if !verified {
+		return nil, fmt.Errorf("signature verification failed: signature does not match operator public key")
+	}
+	return sig, nil
+}
+
+func (c *bls381Curve) aggregatePublicKeys(operators []*Operator) (signing.PublicKey, error) {
+	return bls381.AggregatePublicKeys(util.Map(operators, func(o *Operator, i uint64) *bls381.PublicKey {
+		return o.PublicKey.(*bls381.PublicKey)
+	}))
+}
+
+func (c *bls381Curve) newAggregate() signatureAggregate {
+	return &bls381Aggregate{}
+}
+
+// bls381Aggregate keeps the individual signatures and public keys, which are aggregated when the
+// certificate is generated
+type bls381Aggregate struct {
+	signatures []*bls381.Signature
+	publicKeys []*bls381.PublicKey
+}
+
+func (a *bls381Aggregate) add(operator *Operator, sig Signature) {
+	a.signatures = append(a.signatures, sig.(*bls381.Signature))
+	a.publicKeys = append(a.publicKeys, operator.PublicKey.(*bls381.PublicKey))
+}
+
+func (a *bls381Aggregate) setCertificate(cert *AggregatedCertificate) error {
+	aggSig, err := bls381.AggregateSignatures(a.signatures)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate signatures: %w", err)
+	}
+	aggPub, err := bls381.AggregatePublicKeys(a.publicKeys)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate public keys: %w", err)
+	}
+	cert.BLS381 = &BLS381Aggregate{
+		SignersSignature: aggSig,
+		SignersPublicKey: aggPub,
+	}
+	return nil
+}
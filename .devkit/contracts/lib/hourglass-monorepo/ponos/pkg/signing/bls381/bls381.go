@@ -374,6 +374,31 @@ func BatchVerify(publicKeys []*PublicKey, message []byte, signatures []*Signatur
 	return lhs.Equal(&rhs), nil
 }
 
+// AggregatePublicKeys combines multiple public keys into a single G2 public key
+func AggregatePublicKeys(pubKeys []*PublicKey) (*PublicKey, error) {
+	if len(pubKeys) == 0 {
+		return nil, fmt.Errorf("cannot aggregate empty set of public keys")
+	}
+
+	aggPk := new(bls12381.G2Jac)
+	aggPk.FromAffine(pubKeys[0].g2Point)
+
+	for i := 1; i < len(pubKeys); i++ {
+		var temp bls12381.G2Jac
+		temp.FromAffine(pubKeys[i].g2Point)
+		aggPk.AddAssign(&temp)
+	}
+
+	// Convert back to affine coordinates
+	result := new(bls12381.G2Affine)
+	result.FromJacobian(aggPk)
+
+	return &PublicKey{
+		g2Point:    result,
+		PointBytes: result.Marshal(),
+	}, nil
+}
+
 // AggregateVerify verifies an aggregated signature against multiple public keys and multiple messages
 func AggregateVerify(publicKeys []*PublicKey, messages [][]byte, aggSignature *Signature) (bool, error) {
 	if len(publicKeys) != len(messages) {
@@ -272,6 +272,24 @@ func NewPublicKeyFromBytes(data []byte) (*PublicKey, error) {
 	return nil, fmt.Errorf("invalid public key bytes: could not unmarshal as either G1 or G2 point")
 }
 
+// NewPublicKeyFromG1AndG2Bytes creates a public key from its marshalled G1 and G2 points. Unlike
+// NewPublicKeyFromBytes, the G1 point is the operator's real one rather than an approximation.
+func NewPublicKeyFromG1AndG2Bytes(g1Bytes []byte, g2Bytes []byte) (*PublicKey, error) {
+	g1Point := new(bn254.G1Affine)
+	if err := g1Point.Unmarshal(g1Bytes); err != nil {
+		return nil, fmt.Errorf("invalid G1 point: %w", err)
+	}
+	g2Point := new(bn254.G2Affine)
+	if err := g2Point.Unmarshal(g2Bytes); err != nil {
+		return nil, fmt.Errorf("invalid G2 point: %w", err)
+	}
+	return &PublicKey{
+		g1Point:    g1Point,
+		g2Point:    g2Point,
+		PointBytes: g2Bytes,
+	}, nil
+}
+
 func NewPublicKeyFromHexString(pubHex string) (*PublicKey, error) {
 	b, err := hex.DecodeString(pubHex)
 	if err != nil {
@@ -580,6 +598,22 @@ func (pk *PublicKey) GetG2Point() *bn254.G2Affine {
 	return pk.g2Point
 }
 
+// Clone returns a deep copy of the public key, keeping both its G1 and G2 points
+func (pk *PublicKey) Clone() *PublicKey {
+	clone := &PublicKey{
+		PointBytes: append([]byte(nil), pk.PointBytes...),
+	}
+	if pk.g1Point != nil {
+		g1 := *pk.g1Point
+		clone.g1Point = &g1
+	}
+	if pk.g2Point != nil {
+		g2 := *pk.g2Point
+		clone.g2Point = &g2
+	}
+	return clone
+}
+
 type G2Point struct {
 	*bn254.G2Affine
 }
@@ -15,6 +15,7 @@ func NewSimulatedPeerFromConfig(simulatedPeer config.SimulatedPeer) (*peering.Op
 		OperatorAddress: simulatedPeer.OperatorAddress,
 		NetworkAddress:  simulatedPeer.NetworkAddress,
 		PublicKey:       privKey.Public(),
+		CurveType:       "bn254",
 		OperatorSetIds:  []uint32{simulatedPeer.OperatorSetId},
 	}, nil
 }
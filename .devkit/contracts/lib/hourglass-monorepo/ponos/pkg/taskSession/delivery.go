@@ -0,0 +1,147 @@
+package taskSession
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBroadcastInitialBackoff = time.Second
+	DefaultBroadcastMaxBackoff     = 30 * time.Second
+	DefaultBroadcastAttemptTimeout = 10 * time.Second
+	DefaultBroadcastResultTimeout  = 30 * time.Second
+)
+
+// BroadcastRetryPolicy controls how a task is sent again to operators that did not accept it or
+// did not return a result. Retries stop at the task deadline, once the operator returned a
+// result, or once the signing threshold is met.
+type BroadcastRetryPolicy struct {
+	// InitialBackoff is how long to wait before sending the task again to an operator that did not
+	// accept it. It doubles with every failed attempt, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// AttemptTimeout bounds a single SubmitTask call
+	AttemptTimeout time.Duration
+
+	// ResultTimeout is how long an operator that accepted the task has to return a result before
+	// it is sent the task again
+	ResultTimeout time.Duration
+}
+
+func (p *BroadcastRetryPolicy) initialBackoff() time.Duration {
+	if p == nil || p.InitialBackoff <= 0 {
+		return DefaultBroadcastInitialBackoff
+	}
+	return p.InitialBackoff
+}
+
+func (p *BroadcastRetryPolicy) maxBackoff() time.Duration {
+	if p == nil || p.MaxBackoff <= 0 {
+		return DefaultBroadcastMaxBackoff
+	}
+	return p.MaxBackoff
+}
+
+func (p *BroadcastRetryPolicy) attemptTimeout() time.Duration {
+	if p == nil || p.AttemptTimeout <= 0 {
+		return DefaultBroadcastAttemptTimeout
+	}
+	return p.AttemptTimeout
+}
+
+func (p *BroadcastRetryPolicy) resultTimeout() time.Duration {
+	if p == nil || p.ResultTimeout <= 0 {
+		return DefaultBroadcastResultTimeout
+	}
+	return p.ResultTimeout
+}
+
+type DeliveryStatus string
+
+const (
+	// DeliveryStatus_Pending means the task was not sent to the operator yet
+	DeliveryStatus_Pending DeliveryStatus = "pending"
+	// DeliveryStatus_Failed means the last attempt to send the task failed or was rejected
+	DeliveryStatus_Failed DeliveryStatus = "failed"
+	// DeliveryStatus_Acked means the operator accepted the task and its result is outstanding
+	DeliveryStatus_Acked DeliveryStatus = "acked"
+	// DeliveryStatus_Responded means the operator returned a result
+	DeliveryStatus_Responded DeliveryStatus = "responded"
+)
+
+// OperatorDelivery is the outcome of sending the task to an operator
+type OperatorDelivery struct {
+	OperatorAddress string
+	Status          DeliveryStatus
+	Attempts        int
+	LastError       string
+	AckedAt         time.Time
+	RespondedAt     time.Time
+}
+
+// operatorDelivery tracks the delivery of the task to one operator
+type operatorDelivery struct {
+	mu       sync.Mutex
+	delivery OperatorDelivery
+
+	// responded is closed when the operator returns a result
+	responded     chan struct{}
+	respondedOnce sync.Once
+}
+
+func newOperatorDelivery(operatorAddress string) *operatorDelivery {
+	return &operatorDelivery{
+		delivery: OperatorDelivery{
+			OperatorAddress: operatorAddress,
+			Status:          DeliveryStatus_Pending,
+		},
+		responded: make(chan struct{}),
+	}
+}
+
+func (d *operatorDelivery) recordAttempt(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.delivery.Attempts++
+	if d.delivery.Status == DeliveryStatus_Responded {
+		return
+	}
+	if err != nil {
+		d.delivery.Status = DeliveryStatus_Failed
+		d.delivery.LastError = err.Error()
+		return
+	}
+	d.delivery.Status = DeliveryStatus_Acked
+	d.delivery.AckedAt = time.Now()
+}
+
+func (d *operatorDelivery) recordResponse() {
+	d.respondedOnce.Do(func() {
+		d.mu.Lock()
+		d.delivery.Status = DeliveryStatus_Responded
+		d.delivery.RespondedAt = time.Now()
+		d.mu.Unlock()
+		close(d.responded)
+	})
+}
+
+func (d *operatorDelivery) snapshot() OperatorDelivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.delivery
+}
+
+// Deliveries returns the delivery outcome of every recipient operator, ordered by address
+func (ts *TaskSession) Deliveries() []OperatorDelivery {
+	deliveries := make([]OperatorDelivery, 0, len(ts.deliveries))
+	for _, d := range ts.deliveries {
+		deliveries = append(deliveries, d.snapshot())
+	}
+	slices.SortFunc(deliveries, func(a, b OperatorDelivery) int {
+		return strings.Compare(a.OperatorAddress, b.OperatorAddress)
+	})
+	return deliveries
+}
@@ -2,15 +2,23 @@ package taskSession
 
 import (
 	"context"
+	"fmt"
+	commonV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/common/v1"
 	executorV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/hourglass/v1/executor"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/executorClient"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bls381"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
-	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"go.uber.org/zap"
+	"math/big"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 type TaskSession struct {
@@ -28,6 +36,15 @@ type TaskSession struct {
 	resultsQueue         chan *TaskSession
 	thresholdMet         atomic.Bool
 	AggregateCertificate *aggregation.AggregatedCertificate
+
+	retryPolicy *BroadcastRetryPolicy
+	// deliveries tracks the delivery of the task to each recipient operator (lowercased address)
+	deliveries map[string]*operatorDelivery
+	// broadcastDone is closed when the signing threshold is met, which stops re-sending the task
+	broadcastDone     chan struct{}
+	broadcastDoneOnce sync.Once
+	// submitTask sends the task to an operator's executor
+	submitTask func(ctx context.Context, peer *peering.OperatorPeerInfo, submission *executorV1.TaskSubmission) (*commonV1.SubmitAck, error)
 }
 
 func NewTaskSession(
@@ -37,22 +54,38 @@ func NewTaskSession(
 	aggregatorAddress string,
 	aggregatorUrl string,
 	aggregatorSignature []byte,
+	signingCurve string,
+	thresholdBips uint16,
+	operatorWeights map[string]*big.Int,
+	operatorTable []*aggregation.OperatorTableEntry,
+	retryPolicy *BroadcastRetryPolicy,
 	resultsQueue chan *TaskSession,
 	logger *zap.Logger,
 ) (*TaskSession, error) {
-	operators := util.Map(task.RecipientOperators, func(peer *peering.OperatorPeerInfo, i uint64) *aggregation.Operator {
-		return &aggregation.Operator{
-			Address:   peer.OperatorAddress,
-			PublicKey: peer.PublicKey,
+	operators := make([]*aggregation.Operator, 0, len(task.RecipientOperators))
+	for _, peer := range task.RecipientOperators {
+		pubKey, err := operatorPublicKey(peer, signingCurve)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key for operator %s: %w", peer.OperatorAddress, err)
 		}
-	})
+		weight, ok := operatorWeights[strings.ToLower(peer.OperatorAddress)]
+		if !ok {
+			return nil, fmt.Errorf("no stake weight for operator %s", peer.OperatorAddress)
+		}
+		operators = append(operators, &aggregation.Operator{
+			Address:   peer.OperatorAddress,
+			PublicKey: pubKey,
+			Weight:    weight,
+		})
+	}
 
-	ta, err := aggregation.NewTaskResultAggregator(
+	ta, err := aggregation.NewTaskResultAggregatorForCurve(
 		ctx,
+		signingCurve,
 		task.TaskId,
 		task.BlockNumber,
 		task.OperatorSetId,
-		100,
+		thresholdBips,
 		task.Payload,
 		task.DeadlineUnixSeconds,
 		operators,
@@ -60,6 +93,11 @@ func NewTaskSession(
 	if err != nil {
 		return nil, err
 	}
+	if len(operatorTable) > 0 {
+		if err := ta.SetOperatorTable(operatorTable); err != nil {
+			return nil, fmt.Errorf("failed to set operator table: %w", err)
+		}
+	}
 	ts := &TaskSession{
 		Task:                task,
 		aggregatorAddress:   aggregatorAddress,
@@ -72,6 +110,13 @@ func NewTaskSession(
 		resultsQueue:        resultsQueue,
 		taskAggregator:      ta,
 		thresholdMet:        atomic.Bool{},
+		retryPolicy:         retryPolicy,
+		deliveries:          make(map[string]*operatorDelivery, len(task.RecipientOperators)),
+		broadcastDone:       make(chan struct{}),
+		submitTask:          submitTaskToExecutor,
+	}
+	for _, peer := range task.RecipientOperators {
+		ts.deliveries[strings.ToLower(peer.OperatorAddress)] = newOperatorDelivery(peer.OperatorAddress)
 	}
 	ts.resultsCount.Store(0)
 	ts.thresholdMet.Store(false)
@@ -79,6 +124,30 @@ func NewTaskSession(
 	return ts, nil
 }
 
+// operatorPublicKey returns the peer's public key on the given signing curve, re-using the key
+// object when possible since the bn254 G1 point does not survive a round trip through Bytes
+func operatorPublicKey(peer *peering.OperatorPeerInfo, signingCurve string) (signing.PublicKey, error) {
+	if peer.PublicKey == nil {
+		return nil, fmt.Errorf("peer has no public key")
+	}
+	if peer.CurveType != "" && !strings.EqualFold(peer.CurveType, signingCurve) {
+		return nil, fmt.Errorf("peer key is on curve %s but the AVS signs with %s", peer.CurveType, signingCurve)
+	}
+	switch strings.ToLower(signingCurve) {
+	case aggregation.SigningCurve_BN254:
+		if pubKey, ok := peer.PublicKey.(*bn254.PublicKey); ok {
+			return pubKey, nil
+		}
+		return bn254.NewPublicKeyFromBytes(peer.PublicKey.Bytes())
+	case aggregation.SigningCurve_BLS381:
+		if pubKey, ok := peer.PublicKey.(*bls381.PublicKey); ok {
+			return pubKey, nil
+		}
+		return bls381.NewPublicKeyFromBytes(peer.PublicKey.Bytes())
+	}
+	return nil, fmt.Errorf("%w: %s", aggregation.ErrUnsupportedCurve, signingCurve)
+}
+
 func (ts *TaskSession) Process() error {
 	ts.logger.Sugar().Infow("task session started",
 		zap.String("taskId", ts.Task.TaskId),
@@ -92,6 +161,15 @@ func (ts *TaskSession) Process() error {
 	return nil
 }
 
+// Cancel stops the task session, e.g. when the block that created the task was orphaned
+func (ts *TaskSession) Cancel() {
+	ts.contextCancel()
+}
+
+// Broadcast sends the task to every recipient operator. Operators that do not accept the task are
+// sent it again with exponential backoff, and operators that accepted it but did not return a
+// result within the result timeout are sent it again, until the deadline or the signing threshold
+// is met, so that an executor that restarted does not cost the task its quorum.
 func (ts *TaskSession) Broadcast() {
 	ts.logger.Sugar().Infow("task session broadcast started",
 		zap.String("taskId", ts.Task.TaskId),
@@ -105,6 +183,9 @@ func (ts *TaskSession) Broadcast() {
 		AggregatorUrl:     ts.aggregatorUrl,
 		Signature:         ts.aggregatorSignature,
 	}
+	if ts.Task.DeadlineUnixSeconds != nil {
+		taskSubmission.DeadlineUnixSeconds = ts.Task.DeadlineUnixSeconds.Unix()
+	}
 	ts.logger.Sugar().Infow("broadcasting task session to operators",
 		zap.Any("taskSubmission", taskSubmission),
 	)
@@ -112,51 +193,95 @@ func (ts *TaskSession) Broadcast() {
 	var wg sync.WaitGroup
 	for _, peer := range ts.Task.RecipientOperators {
 		wg.Add(1)
-
-		go func(wg *sync.WaitGroup, peer *peering.OperatorPeerInfo) {
+		go func(peer *peering.OperatorPeerInfo) {
 			defer wg.Done()
-			ts.logger.Sugar().Infow("task session broadcast to operator",
+			ts.deliverToOperator(peer, taskSubmission)
+		}(peer)
+	}
+	wg.Wait()
+	ts.logger.Sugar().Infow("task submission completed",
+		zap.String("taskId", ts.Task.TaskId),
+		zap.Any("deliveries", ts.Deliveries()),
+	)
+}
+
+// deliverToOperator sends the task to the operator until it returns a result, the signing
+// threshold is met or the task session ends
+func (ts *TaskSession) deliverToOperator(peer *peering.OperatorPeerInfo, taskSubmission *executorV1.TaskSubmission) {
+	delivery := ts.deliveries[strings.ToLower(peer.OperatorAddress)]
+	backoff := ts.retryPolicy.initialBackoff()
+	for {
+		ts.logger.Sugar().Infow("task session broadcast to operator",
+			zap.String("taskId", ts.Task.TaskId),
+			zap.String("operatorAddress", peer.OperatorAddress),
+			zap.String("networkAddress", peer.NetworkAddress),
+		)
+		err := ts.submitToOperator(peer, taskSubmission)
+		delivery.recordAttempt(err)
+
+		var wait time.Duration
+		if err != nil {
+			ts.logger.Sugar().Errorw("Failed to submit task to executor",
+				zap.String("executorAddress", peer.OperatorAddress),
 				zap.String("taskId", ts.Task.TaskId),
-				zap.String("operatorAddress", peer.OperatorAddress),
-				zap.String("networkAddress", peer.NetworkAddress),
+				zap.Duration("retryIn", backoff),
+				zap.Error(err),
 			)
-			c, err := executorClient.NewExecutorClient(peer.NetworkAddress, true)
-			if err != nil {
-				ts.logger.Sugar().Errorw("Failed to create executor client",
-					zap.String("executorAddress", peer.OperatorAddress),
-					zap.String("taskId", ts.Task.TaskId),
-					zap.Error(err),
-				)
-				return
-			}
-
-			res, err := c.SubmitTask(ts.context, taskSubmission)
-			if err != nil {
-				ts.logger.Sugar().Errorw("Failed to submit task to executor",
-					zap.String("executorAddress", peer.OperatorAddress),
-					zap.String("taskId", ts.Task.TaskId),
-					zap.Error(err),
-				)
-				return
-			}
-			if !res.Success {
-				ts.logger.Sugar().Errorw("task submission failed",
-					zap.String("executorAddress", peer.OperatorAddress),
-					zap.String("taskId", ts.Task.TaskId),
-					zap.String("message", res.Message),
-				)
-				return
-			}
+			wait = backoff
+			backoff = min(backoff*2, ts.retryPolicy.maxBackoff())
+		} else {
 			ts.logger.Sugar().Debugw("Successfully submitted task to executor",
 				zap.String("executorAddress", peer.OperatorAddress),
 				zap.String("taskId", ts.Task.TaskId),
 			)
-		}(&wg, peer)
+			wait = ts.retryPolicy.resultTimeout()
+			backoff = ts.retryPolicy.initialBackoff()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ts.context.Done():
+			timer.Stop()
+			return
+		case <-ts.broadcastDone:
+			timer.Stop()
+			return
+		case <-delivery.responded:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
 	}
-	wg.Wait()
-	ts.logger.Sugar().Infow("task submission completed",
-		zap.String("taskId", ts.Task.TaskId),
-	)
+}
+
+// submitToOperator makes a single attempt to send the task to the operator
+func (ts *TaskSession) submitToOperator(peer *peering.OperatorPeerInfo, taskSubmission *executorV1.TaskSubmission) error {
+	ctx, cancel := context.WithTimeout(ts.context, ts.retryPolicy.attemptTimeout())
+	defer cancel()
+
+	res, err := ts.submitTask(ctx, peer, taskSubmission)
+	if err != nil {
+		return err
+	}
+	if !res.Success {
+		return fmt.Errorf("executor rejected the task: %s", res.Message)
+	}
+	return nil
+}
+
+func submitTaskToExecutor(ctx context.Context, peer *peering.OperatorPeerInfo, taskSubmission *executorV1.TaskSubmission) (*commonV1.SubmitAck, error) {
+	c, err := executorClient.NewExecutorClient(peer.NetworkAddress, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create executor client: %w", err)
+	}
+	return c.SubmitTask(ctx, taskSubmission)
+}
+
+// stopBroadcast stops sending the task to operators that have not returned a result
+func (ts *TaskSession) stopBroadcast() {
+	ts.broadcastDoneOnce.Do(func() {
+		close(ts.broadcastDone)
+	})
 }
 
 func (ts *TaskSession) RecordResult(taskResult *types.TaskResult) {
@@ -168,17 +293,29 @@ func (ts *TaskSession) RecordResult(taskResult *types.TaskResult) {
 		return
 	}
 	if err := ts.taskAggregator.ProcessNewSignature(ts.context, taskResult.TaskId, taskResult); err != nil {
+		// the operator keeps being sent the task until it returns a result that can be verified
 		ts.logger.Sugar().Errorw("Failed to process task result",
 			zap.String("taskId", taskResult.TaskId),
 			zap.String("operatorAddress", taskResult.OperatorAddress),
 			zap.Error(err),
 		)
+		return
+	}
+	operator := strings.ToLower(taskResult.OperatorAddress)
+	if delivery, ok := ts.deliveries[operator]; ok {
+		ts.results.Store(operator, taskResult)
+		delivery.recordResponse()
 	}
 
 	if !ts.taskAggregator.SigningThresholdMet() {
 		return
 	}
-	ts.thresholdMet.Store(true)
+	// results recorded concurrently can all see the threshold met, only one of them generates
+	// the certificate
+	if !ts.thresholdMet.CompareAndSwap(false, true) {
+		return
+	}
+	ts.stopBroadcast()
 	ts.logger.Sugar().Infow("task completion threshold met",
 		zap.String("taskId", taskResult.TaskId),
 		zap.String("operatorAddress", taskResult.OperatorAddress),
@@ -193,6 +330,14 @@ func (ts *TaskSession) RecordResult(taskResult *types.TaskResult) {
 		)
 		return
 	}
+	for _, d := range cert.DisagreeingOperators {
+		ts.logger.Sugar().Warnw("Operator returned a different result than the signing quorum",
+			zap.String("taskId", taskResult.TaskId),
+			zap.String("operatorAddress", d.OperatorAddress),
+			zap.String("digest", hexutil.Encode(d.Digest)),
+			zap.String("quorumDigest", hexutil.Encode(cert.TaskResponseDigest)),
+		)
+	}
 	ts.AggregateCertificate = cert
 
 	ts.resultsQueue <- ts
@@ -0,0 +1,238 @@
+package taskSession
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	commonV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/common/v1"
+	executorV1 "github.com/Layr-Labs/hourglass-monorepo/ponos/gen/protos/eigenlayer/hourglass/v1/executor"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/aggregation"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/signing/bn254"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/types"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeExecutors answers SubmitTask with the next response queued for the operator, and acks once
+// the queue is empty
+type fakeExecutors struct {
+	mu        sync.Mutex
+	responses map[string][]error
+	attempts  map[string]int
+}
+
+func (f *fakeExecutors) submitTask(ctx context.Context, peer *peering.OperatorPeerInfo, submission *executorV1.TaskSubmission) (*commonV1.SubmitAck, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts[peer.OperatorAddress]++
+	if queued := f.responses[peer.OperatorAddress]; len(queued) > 0 {
+		f.responses[peer.OperatorAddress] = queued[1:]
+		if queued[0] != nil {
+			return nil, queued[0]
+		}
+		return &commonV1.SubmitAck{Success: false, Message: "avs performer not found"}, nil
+	}
+	return &commonV1.SubmitAck{Success: true}, nil
+}
+
+func (f *fakeExecutors) attemptsFor(operator string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts[operator]
+}
+
+func Test_TaskSessionBroadcast(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	policy := &BroadcastRetryPolicy{
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		ResultTimeout:  50 * time.Millisecond,
+	}
+
+	newSession := func(t *testing.T, executors *fakeExecutors, operators ...string) *TaskSession {
+		peers := make([]*peering.OperatorPeerInfo, 0, len(operators))
+		for _, op := range operators {
+			peers = append(peers, &peering.OperatorPeerInfo{OperatorAddress: op})
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		t.Cleanup(cancel)
+
+		ts := &TaskSession{
+			Task:          &types.Task{TaskId: "0x01", RecipientOperators: peers},
+			context:       ctx,
+			contextCancel: cancel,
+			logger:        l,
+			retryPolicy:   policy,
+			deliveries:    make(map[string]*operatorDelivery),
+			broadcastDone: make(chan struct{}),
+			submitTask:    executors.submitTask,
+		}
+		for _, op := range operators {
+			ts.deliveries[strings.ToLower(op)] = newOperatorDelivery(op)
+		}
+		return ts
+	}
+
+	t.Run("re-sends the task to an operator until it accepts it", func(t *testing.T) {
+		executors := &fakeExecutors{
+			responses: map[string][]error{
+				"0xA": {errors.New("connection refused"), nil, errors.New("connection refused")},
+			},
+			attempts: map[string]int{},
+		}
+		ts := newSession(t, executors, "0xA")
+		go ts.Broadcast()
+
+		require.Eventually(t, func() bool {
+			return ts.Deliveries()[0].Status == DeliveryStatus_Acked
+		}, time.Second, time.Millisecond)
+		delivery := ts.Deliveries()[0]
+		assert.Equal(t, 4, delivery.Attempts)
+		assert.Equal(t, "connection refused", delivery.LastError)
+	})
+
+	t.Run("re-sends the task to an operator that did not return a result", func(t *testing.T) {
+		executors := &fakeExecutors{responses: map[string][]error{}, attempts: map[string]int{}}
+		ts := newSession(t, executors, "0xA", "0xB")
+		go ts.Broadcast()
+
+		require.Eventually(t, func() bool {
+			return executors.attemptsFor("0xA") == 1 && executors.attemptsFor("0xB") == 1
+		}, time.Second, time.Millisecond)
+		ts.deliveries["0xa"].recordResponse()
+
+		require.Eventually(t, func() bool {
+			return executors.attemptsFor("0xB") >= 2
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, 1, executors.attemptsFor("0xA"))
+		assert.Equal(t, DeliveryStatus_Responded, ts.Deliveries()[0].Status)
+		assert.Equal(t, DeliveryStatus_Acked, ts.Deliveries()[1].Status)
+	})
+
+	t.Run("stops re-sending once the signing threshold is met", func(t *testing.T) {
+		executors := &fakeExecutors{
+			responses: map[string][]error{
+				"0xA": {errors.New("unavailable"), errors.New("unavailable"), errors.New("unavailable")},
+			},
+			attempts: map[string]int{},
+		}
+		ts := newSession(t, executors, "0xA")
+		done := make(chan struct{})
+		go func() {
+			ts.Broadcast()
+			close(done)
+		}()
+
+		require.Eventually(t, func() bool {
+			return executors.attemptsFor("0xA") >= 1
+		}, time.Second, time.Millisecond)
+		ts.stopBroadcast()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("broadcast did not stop after the threshold was met")
+		}
+	})
+
+	t.Run("stops at the task deadline", func(t *testing.T) {
+		executors := &fakeExecutors{responses: map[string][]error{}, attempts: map[string]int{}}
+		ts := newSession(t, executors, "0xA")
+		done := make(chan struct{})
+		go func() {
+			ts.Broadcast()
+			close(done)
+		}()
+
+		ts.Cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("broadcast did not stop when the session ended")
+		}
+	})
+}
+
+func Test_TaskSessionRecordResult(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	output := []byte("result")
+	digest := util.GetKeccak256Digest(output)
+
+	type operator struct {
+		address    string
+		privateKey *bn254.PrivateKey
+	}
+
+	newSession := func(t *testing.T, count int) (*TaskSession, []*operator, chan *TaskSession) {
+		operators := make([]*operator, 0, count)
+		peers := make([]*peering.OperatorPeerInfo, 0, count)
+		weights := make(map[string]*big.Int, count)
+		for i := 0; i < count; i++ {
+			privateKey, publicKey, err := bn254.GenerateKeyPair()
+			require.NoError(t, err)
+			address := fmt.Sprintf("0x%d", i+1)
+			operators = append(operators, &operator{address: address, privateKey: privateKey})
+			peers = append(peers, &peering.OperatorPeerInfo{
+				OperatorAddress: address,
+				PublicKey:       publicKey,
+				CurveType:       aggregation.SigningCurve_BN254,
+			})
+			weights[address] = big.NewInt(1)
+		}
+		deadline := time.Now().Add(time.Minute)
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		resultsQueue := make(chan *TaskSession, count)
+
+		ts, err := NewTaskSession(
+			ctx,
+			cancel,
+			&types.Task{TaskId: "0x01", RecipientOperators: peers, DeadlineUnixSeconds: &deadline},
+			"0xaggregator",
+			"localhost:9000",
+			nil,
+			aggregation.SigningCurve_BN254,
+			5_000,
+			weights,
+			nil,
+			nil,
+			resultsQueue,
+			l,
+		)
+		require.NoError(t, err)
+		return ts, operators, resultsQueue
+	}
+
+	signedResult := func(t *testing.T, op *operator) *types.TaskResult {
+		sig, err := op.privateKey.Sign(digest[:])
+		require.NoError(t, err)
+		return &types.TaskResult{TaskId: "0x01", OperatorAddress: op.address, Output: output, Signature: sig.Bytes()}
+	}
+
+	t.Run("only records results whose signature was verified", func(t *testing.T) {
+		ts, operators, resultsQueue := newSession(t, 2)
+
+		forged := signedResult(t, operators[1])
+		forged.OperatorAddress = operators[0].address
+		ts.RecordResult(forged)
+		assert.Equal(t, DeliveryStatus_Pending, ts.Deliveries()[0].Status)
+		assert.Empty(t, ts.GetTaskResults())
+
+		ts.RecordResult(signedResult(t, operators[0]))
+		assert.Equal(t, DeliveryStatus_Responded, ts.Deliveries()[0].Status)
+		assert.Len(t, ts.GetTaskResults(), 1)
+		assert.Len(t, resultsQueue, 1)
+	})
+}
@@ -0,0 +1,177 @@
+package transactionManager
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pendingTransaction is a nonce and every transaction that was sent for it
+type pendingTransaction struct {
+	Nonce uint64 `json:"nonce"`
+	Tag   string `json:"tag"`
+
+	// Attempts are the signed transactions sent for the nonce, oldest first
+	Attempts []hexutil.Bytes `json:"attempts"`
+
+	// SentAt is when the newest attempt was sent
+	SentAt time.Time `json:"sentAt"`
+
+	txs []*types.Transaction
+}
+
+func (p *pendingTransaction) addAttempt(tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %w", err)
+	}
+	p.Attempts = append(p.Attempts, raw)
+	p.txs = append(p.txs, tx)
+	p.SentAt = time.Now()
+	return nil
+}
+
+func (p *pendingTransaction) latest() *types.Transaction {
+	return p.txs[len(p.txs)-1]
+}
+
+func (p *pendingTransaction) decode() error {
+	p.txs = make([]*types.Transaction, 0, len(p.Attempts))
+	for _, raw := range p.Attempts {
+		tx := &types.Transaction{}
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return fmt.Errorf("failed to decode transaction: %w", err)
+		}
+		p.txs = append(p.txs, tx)
+	}
+	if len(p.txs) == 0 {
+		return fmt.Errorf("pending transaction with nonce %d has no attempts", p.Nonce)
+	}
+	return nil
+}
+
+// pendingStore keeps the pending transactions of a manager, one file per nonce
+type pendingStore struct {
+	dir string
+
+	mu      sync.Mutex
+	pending map[uint64]*pendingTransaction
+}
+
+func newPendingStore(dir string) (*pendingStore, error) {
+	ps := &pendingStore{
+		dir:     dir,
+		pending: make(map[uint64]*pendingTransaction),
+	}
+	if dir == "" {
+		return ps, nil
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create pending transaction directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pending transaction directory: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pending transaction: %w", err)
+		}
+		p := &pendingTransaction{}
+		if err := json.Unmarshal(data, p); err != nil {
+			return nil, fmt.Errorf("pending transaction %s is corrupt: %w", entry.Name(), err)
+		}
+		if err := p.decode(); err != nil {
+			return nil, fmt.Errorf("pending transaction %s is corrupt: %w", entry.Name(), err)
+		}
+		ps.pending[p.Nonce] = p
+	}
+	return ps, nil
+}
+
+// list returns the pending transactions ordered by nonce
+func (ps *pendingStore) list() []*pendingTransaction {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	pending := make([]*pendingTransaction, 0, len(ps.pending))
+	for _, p := range ps.pending {
+		pending = append(pending, p)
+	}
+	slices.SortFunc(pending, func(a, b *pendingTransaction) int {
+		return cmp.Compare(a.Nonce, b.Nonce)
+	})
+	return pending
+}
+
+func (ps *pendingStore) save(p *pendingTransaction) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if err := ps.persist(p); err != nil {
+		return err
+	}
+	ps.pending[p.Nonce] = p
+	return nil
+}
+
+func (ps *pendingStore) remove(p *pendingTransaction) error {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	delete(ps.pending, p.Nonce)
+	if ps.dir == "" {
+		return nil
+	}
+	if err := os.Remove(ps.path(p.Nonce)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove pending transaction: %w", err)
+	}
+	return nil
+}
+
+// persist atomically writes the pending transaction to the directory. The caller must hold mu.
+func (ps *pendingStore) persist(p *pendingTransaction) error {
+	if ps.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending transaction: %w", err)
+	}
+	tmp, err := os.CreateTemp(ps.dir, ".pending-*")
+	if err != nil {
+		return fmt.Errorf("failed to create pending transaction: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write pending transaction: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync pending transaction: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close pending transaction: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), ps.path(p.Nonce)); err != nil {
+		return fmt.Errorf("failed to write pending transaction: %w", err)
+	}
+	return nil
+}
+
+func (ps *pendingStore) path(nonce uint64) string {
+	return filepath.Join(ps.dir, fmt.Sprintf("%d.json", nonce))
+}
@@ -0,0 +1,513 @@
+// Package transactionManager sends transactions for a single signer key on a single chain.
+//
+// Nonces are assigned locally so that concurrent submissions do not collide. A transaction that is
+// not mined within the fee bump interval is re-signed with the same nonce and higher fees, up to
+// the configured maximum fee, until one of its attempts is mined.
+//
+// Once the manager is started, a transaction whose sender stops waiting for it keeps being bumped
+// and watched by the manager. Pending transactions are written atomically to <dir>/<nonce>.json,
+// so that a restarted manager keeps bumping and watching them:
+//
+//	{
+//	  "nonce": 12,
+//	  "tag": "SubmitTaskResult",
+//	  "attempts": ["0x02f8...", "0x02f8..."],
+//	  "sentAt": "2025-01-01T00:00:00Z"
+//	}
+package transactionManager
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"go.uber.org/zap"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultFeeBumpInterval     = 30 * time.Second
+	DefaultFeeBumpPercent      = 20
+	DefaultReceiptPollInterval = 2 * time.Second
+
+	// minFeeBumpPercent is the smallest bump nodes accept for a replacement transaction
+	minFeeBumpPercent = 10
+)
+
+var (
+	// FallbackGasTipCap is used when the node does not support eth_maxPriorityFeePerGas
+	FallbackGasTipCap = big.NewInt(15000000000)
+
+	// ErrTransactionFailed is returned when the transaction was mined but reverted
+	ErrTransactionFailed = errors.New("transaction failed")
+
+	// ErrNonceConsumed is returned when the nonce of a pending transaction was used by a
+	// transaction the manager did not send
+	ErrNonceConsumed = errors.New("nonce was consumed by another transaction")
+)
+
+// Backend is the part of an ethclient.Client the manager uses
+type Backend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	EstimateGas(ctx context.Context, msg goethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+type ITransactionManager interface {
+	// Send signs and sends a transaction with the recipient, value and calldata of tx, and blocks
+	// until it is mined
+	Send(ctx context.Context, tx *types.Transaction, tag string) (*types.Receipt, error)
+}
+
+type TransactionManagerConfig struct {
+	ChainId    config.ChainId
+	PrivateKey *ecdsa.PrivateKey
+
+	// Dir is where pending transactions are persisted. When empty, they are only kept in memory.
+	Dir string
+
+	// MaxGasFeeCap is the highest fee per gas, in wei, fees are bumped to. Nil means no limit.
+	MaxGasFeeCap *big.Int
+
+	// FeeBumpInterval is how long a transaction may stay pending before it is replaced with
+	// higher fees
+	FeeBumpInterval time.Duration
+
+	// FeeBumpPercent is how much the fees of a replacement grow. Nodes require at least 10.
+	FeeBumpPercent uint64
+
+	// ReceiptPollInterval is how often pending transactions are checked for a receipt
+	ReceiptPollInterval time.Duration
+}
+
+type TransactionManager struct {
+	backend Backend
+	config  *TransactionManagerConfig
+	store   *pendingStore
+	address common.Address
+	signer  types.Signer
+	logger  *zap.Logger
+
+	// mu serializes nonce assignment and the first broadcast of each transaction
+	mu sync.Mutex
+	// nextNonce is the nonce the next transaction gets, unless the chain is ahead of it
+	nextNonce uint64
+	// watchCtx is the context the manager was started with. Transactions whose sender stops
+	// waiting are watched until it is done.
+	watchCtx context.Context
+}
+
+func NewTransactionManager(backend Backend, cfg *TransactionManagerConfig, logger *zap.Logger) (*TransactionManager, error) {
+	if cfg.PrivateKey == nil {
+		return nil, fmt.Errorf("a private key is required")
+	}
+	store, err := newPendingStore(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	tm := &TransactionManager{
+		backend: backend,
+		config:  cfg,
+		store:   store,
+		address: crypto.PubkeyToAddress(cfg.PrivateKey.PublicKey),
+		signer:  types.LatestSignerForChainID(new(big.Int).SetUint64(uint64(cfg.ChainId))),
+		logger:  logger,
+	}
+	for _, p := range store.list() {
+		tm.nextNonce = max(tm.nextNonce, p.Nonce+1)
+	}
+	return tm, nil
+}
+
+// Address is the account the manager sends transactions from
+func (tm *TransactionManager) Address() common.Address {
+	return tm.address
+}
+
+// Start resumes watching and bumping the transactions that were pending when the manager last
+// stopped
+func (tm *TransactionManager) Start(ctx context.Context) error {
+	// taken with the nonce lock, so that transactions broadcast from now on are not in the list
+	tm.mu.Lock()
+	tm.watchCtx = ctx
+	pending := tm.store.list()
+	tm.mu.Unlock()
+
+	tm.logger.Sugar().Infow("Starting transaction manager",
+		zap.Uint64("chainId", uint64(tm.config.ChainId)),
+		zap.String("address", tm.address.Hex()),
+		zap.Int("pendingTransactions", len(pending)),
+	)
+	for _, p := range pending {
+		go tm.watch(ctx, p)
+	}
+	return nil
+}
+
+// Send signs and sends a transaction with the recipient, value and calldata of tx, and blocks
+// until one of its attempts is mined. If ctx is done first, the started manager keeps watching and
+// bumping the transaction; a manager that was not started resumes it on the next Start.
+func (tm *TransactionManager) Send(ctx context.Context, tx *types.Transaction, tag string) (*types.Receipt, error) {
+	p, err := tm.broadcast(ctx, tx, tag)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := tm.waitMined(ctx, p)
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		tm.mu.Lock()
+		watchCtx := tm.watchCtx
+		tm.mu.Unlock()
+		if watchCtx != nil && watchCtx.Err() == nil {
+			tm.logger.Sugar().Infow("Sender stopped waiting, watching the pending transaction",
+				zap.String("tag", p.Tag),
+				zap.Uint64("nonce", p.Nonce),
+			)
+			go tm.watch(watchCtx, p)
+		}
+	}
+	return receipt, err
+}
+
+// watch waits for a pending transaction nobody is waiting for to be mined, bumping its fees
+func (tm *TransactionManager) watch(ctx context.Context, p *pendingTransaction) {
+	receipt, err := tm.waitMined(ctx, p)
+	if err != nil {
+		tm.logger.Sugar().Errorw("Watched transaction did not succeed",
+			zap.String("tag", p.Tag),
+			zap.Uint64("nonce", p.Nonce),
+			zap.Error(err),
+		)
+		return
+	}
+	tm.logger.Sugar().Infow("Watched transaction mined",
+		zap.String("tag", p.Tag),
+		zap.Uint64("nonce", p.Nonce),
+		zap.String("txHash", receipt.TxHash.Hex()),
+	)
+}
+
+// broadcast assigns the next nonce to tx and sends its first attempt
+func (tm *TransactionManager) broadcast(ctx context.Context, tx *types.Transaction, tag string) (*pendingTransaction, error) {
+	gasTipCap, gasFeeCap, err := tm.initialFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// estimated before a nonce is reserved, so a reverting call does not leave a gap
+	gasLimit, err := tm.backend.EstimateGas(ctx, goethereum.CallMsg{
+		From:      tm.address,
+		To:        tx.To(),
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to estimate gas (%s): %w", tag, err)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	chainNonce, err := tm.backend.PendingNonceAt(ctx, tm.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	nonce := max(tm.nextNonce, chainNonce)
+
+	signed, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   tm.signer.ChainID(),
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       addGasBuffer(gasLimit),
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Data:      tx.Data(),
+	}), tm.signer, tm.config.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction (%s): %w", tag, err)
+	}
+
+	p := &pendingTransaction{Nonce: nonce, Tag: tag}
+	if err := p.addAttempt(signed); err != nil {
+		return nil, err
+	}
+	// recorded before sending, so a crash after the broadcast does not lose the transaction
+	if err := tm.store.save(p); err != nil {
+		return nil, err
+	}
+	if err := tm.backend.SendTransaction(ctx, signed); err != nil {
+		if removeErr := tm.store.remove(p); removeErr != nil {
+			tm.logger.Sugar().Errorw("Failed to remove unsent transaction", zap.Error(removeErr))
+		}
+		return nil, fmt.Errorf("failed to send transaction (%s): %w", tag, err)
+	}
+	tm.nextNonce = nonce + 1
+
+	tm.logger.Sugar().Infow("Sent transaction",
+		zap.String("tag", tag),
+		zap.Uint64("nonce", nonce),
+		zap.String("txHash", signed.Hash().Hex()),
+		zap.String("gasTipCap", gasTipCap.String()),
+		zap.String("gasFeeCap", gasFeeCap.String()),
+		zap.Uint64("gasLimit", signed.Gas()),
+	)
+	return p, nil
+}
+
+// waitMined polls for a receipt of any of the transaction's attempts, replacing the transaction
+// with higher fees every fee bump interval
+func (tm *TransactionManager) waitMined(ctx context.Context, p *pendingTransaction) (*types.Receipt, error) {
+	ticker := time.NewTicker(tm.receiptPollInterval())
+	defer ticker.Stop()
+
+	for {
+		receipt, err := tm.checkMined(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			if err := tm.store.remove(p); err != nil {
+				tm.logger.Sugar().Errorw("Failed to remove mined transaction", zap.Error(err))
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				tm.logger.Sugar().Errorw("Transaction failed",
+					zap.String("tag", p.Tag),
+					zap.String("txHash", receipt.TxHash.Hex()),
+				)
+				return nil, fmt.Errorf("%w: %s (%s)", ErrTransactionFailed, receipt.TxHash.Hex(), p.Tag)
+			}
+			tm.logger.Sugar().Infow("Transaction mined",
+				zap.String("tag", p.Tag),
+				zap.Uint64("nonce", p.Nonce),
+				zap.String("txHash", receipt.TxHash.Hex()),
+				zap.Int("attempts", len(p.Attempts)),
+			)
+			return receipt, nil
+		}
+
+		if time.Since(p.SentAt) >= tm.feeBumpInterval() {
+			if err := tm.bumpFees(ctx, p); err != nil {
+				tm.logger.Sugar().Warnw("Failed to replace pending transaction",
+					zap.String("tag", p.Tag),
+					zap.Uint64("nonce", p.Nonce),
+					zap.Error(err),
+				)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkMined returns the receipt of the attempt that was mined, nil if none was mined yet, or
+// ErrNonceConsumed if the nonce was used by a transaction the manager did not send
+func (tm *TransactionManager) checkMined(ctx context.Context, p *pendingTransaction) (*types.Receipt, error) {
+	// read before the receipts, so a receipt mined in between is not mistaken for a foreign
+	// transaction
+	minedNonce, nonceErr := tm.backend.NonceAt(ctx, tm.address, nil)
+
+	for _, tx := range p.txs {
+		receipt, err := tm.backend.TransactionReceipt(ctx, tx.Hash())
+		if err == nil && receipt != nil {
+			return receipt, nil
+		}
+		if err != nil && !errors.Is(err, goethereum.NotFound) {
+			tm.logger.Sugar().Warnw("Failed to get transaction receipt",
+				zap.String("txHash", tx.Hash().Hex()),
+				zap.Error(err),
+			)
+			return nil, nil
+		}
+	}
+
+	if nonceErr == nil && minedNonce > p.Nonce {
+		if err := tm.store.remove(p); err != nil {
+			tm.logger.Sugar().Errorw("Failed to remove replaced transaction", zap.Error(err))
+		}
+		return nil, fmt.Errorf("%w: nonce %d (%s)", ErrNonceConsumed, p.Nonce, p.Tag)
+	}
+	return nil, nil
+}
+
+// bumpFees re-signs the newest attempt with higher fees and sends it with the same nonce
+func (tm *TransactionManager) bumpFees(ctx context.Context, p *pendingTransaction) error {
+	latest := p.latest()
+	gasTipCap, gasFeeCap, err := tm.bumpedFees(ctx, latest.GasTipCap(), latest.GasFeeCap())
+	if err != nil {
+		return err
+	}
+	// nodes reject replacements that do not raise both fees by the minimum bump, which happens
+	// when the max gas fee cap clamps them
+	if !isReplacement(latest, gasTipCap, gasFeeCap) {
+		tm.logger.Sugar().Warnw("Pending transaction is too close to the max gas fee cap to be replaced",
+			zap.String("tag", p.Tag),
+			zap.Uint64("nonce", p.Nonce),
+			zap.String("gasFeeCap", latest.GasFeeCap().String()),
+			zap.String("cappedGasFeeCap", gasFeeCap.String()),
+		)
+		// waits another interval before checking the fees again
+		p.SentAt = time.Now()
+		return nil
+	}
+
+	signed, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   tm.signer.ChainID(),
+		Nonce:     p.Nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       latest.Gas(),
+		To:        latest.To(),
+		Value:     latest.Value(),
+		Data:      latest.Data(),
+	}), tm.signer, tm.config.PrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign replacement transaction: %w", err)
+	}
+
+	if err := tm.backend.SendTransaction(ctx, signed); err != nil {
+		// an earlier attempt was mined, which the next receipt check picks up
+		if isNonceTooLow(err) {
+			return nil
+		}
+		p.SentAt = time.Now()
+		return fmt.Errorf("failed to send replacement transaction: %w", err)
+	}
+	if err := p.addAttempt(signed); err != nil {
+		return err
+	}
+	if err := tm.store.save(p); err != nil {
+		return err
+	}
+
+	tm.logger.Sugar().Infow("Replaced pending transaction with higher fees",
+		zap.String("tag", p.Tag),
+		zap.Uint64("nonce", p.Nonce),
+		zap.String("txHash", signed.Hash().Hex()),
+		zap.String("gasTipCap", gasTipCap.String()),
+		zap.String("gasFeeCap", gasFeeCap.String()),
+		zap.Int("attempts", len(p.Attempts)),
+	)
+	return nil
+}
+
+// initialFees suggests fees for a new transaction: the node's suggested tip on top of 1.5 times
+// the current base fee
+func (tm *TransactionManager) initialFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	gasTipCap, baseFee, err := tm.marketFees(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	overestimatedBaseFee := new(big.Int).Div(new(big.Int).Mul(baseFee, big.NewInt(3)), big.NewInt(2))
+	gasFeeCap := new(big.Int).Add(overestimatedBaseFee, gasTipCap)
+	gasTipCap, gasFeeCap = tm.capFees(gasTipCap, gasFeeCap)
+	return gasTipCap, gasFeeCap, nil
+}
+
+// bumpedFees raises both fees by the fee bump percent, or to what the market currently asks for
+// if that is higher
+func (tm *TransactionManager) bumpedFees(ctx context.Context, gasTipCap *big.Int, gasFeeCap *big.Int) (*big.Int, *big.Int, error) {
+	marketTip, baseFee, err := tm.marketFees(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	percent := max(tm.config.FeeBumpPercent, minFeeBumpPercent)
+	if tm.config.FeeBumpPercent == 0 {
+		percent = DefaultFeeBumpPercent
+	}
+
+	newTip := bumpByPercent(gasTipCap, percent)
+	if marketTip.Cmp(newTip) > 0 {
+		newTip = marketTip
+	}
+	newFeeCap := bumpByPercent(gasFeeCap, percent)
+	if marketFeeCap := new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), newTip); marketFeeCap.Cmp(newFeeCap) > 0 {
+		newFeeCap = marketFeeCap
+	}
+	newTip, newFeeCap = tm.capFees(newTip, newFeeCap)
+	return newTip, newFeeCap, nil
+}
+
+func (tm *TransactionManager) marketFees(ctx context.Context) (*big.Int, *big.Int, error) {
+	gasTipCap, err := tm.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		// not every node supports eth_maxPriorityFeePerGas
+		tm.logger.Sugar().Debugw("Failed to get gas tip cap, using the fallback",
+			zap.Error(err),
+		)
+		gasTipCap = FallbackGasTipCap
+	}
+	header, err := tm.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	baseFee := header.BaseFee
+	if baseFee == nil {
+		baseFee = big.NewInt(0)
+	}
+	return gasTipCap, baseFee, nil
+}
+
+// capFees limits the fee cap to the max gas fee cap, and the tip to the fee cap
+func (tm *TransactionManager) capFees(gasTipCap *big.Int, gasFeeCap *big.Int) (*big.Int, *big.Int) {
+	if tm.config.MaxGasFeeCap != nil && gasFeeCap.Cmp(tm.config.MaxGasFeeCap) > 0 {
+		gasFeeCap = new(big.Int).Set(tm.config.MaxGasFeeCap)
+	}
+	if gasTipCap.Cmp(gasFeeCap) > 0 {
+		gasTipCap = new(big.Int).Set(gasFeeCap)
+	}
+	return gasTipCap, gasFeeCap
+}
+
+func (tm *TransactionManager) feeBumpInterval() time.Duration {
+	if tm.config.FeeBumpInterval <= 0 {
+		return DefaultFeeBumpInterval
+	}
+	return tm.config.FeeBumpInterval
+}
+
+func (tm *TransactionManager) receiptPollInterval() time.Duration {
+	if tm.config.ReceiptPollInterval <= 0 {
+		return DefaultReceiptPollInterval
+	}
+	return tm.config.ReceiptPollInterval
+}
+
+// bumpByPercent returns value increased by percent, rounded up so that it always grows
+func bumpByPercent(value *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(value, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// isReplacement reports whether the fees raise both fees of tx by at least the minimum bump nodes
+// accept for a replacement
+func isReplacement(tx *types.Transaction, gasTipCap *big.Int, gasFeeCap *big.Int) bool {
+	return gasTipCap.Cmp(bumpByPercent(tx.GasTipCap(), minFeeBumpPercent)) >= 0 &&
+		gasFeeCap.Cmp(bumpByPercent(tx.GasFeeCap(), minFeeBumpPercent)) >= 0
+}
+
+func isNonceTooLow(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "already known")
+}
+
+func addGasBuffer(gasLimit uint64) uint64 {
+	return 6 * gasLimit / 5 // add 20% buffer to gas limit
+}
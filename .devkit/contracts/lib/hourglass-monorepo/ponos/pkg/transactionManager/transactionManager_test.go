@@ -0,0 +1,316 @@
+package transactionManager
+
+import (
+	"context"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/config"
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/logger"
+	goethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBackend is a node whose transactions are mined when the test says so, or right away with
+// autoMine
+type fakeBackend struct {
+	mu         sync.Mutex
+	autoMine   bool
+	revert     bool
+	minedNonce uint64
+	sent       []*types.Transaction
+	receipts   map[common.Hash]*types.Receipt
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{receipts: make(map[common.Hash]*types.Receipt)}
+}
+
+func (f *fakeBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.minedNonce, nil
+}
+
+func (f *fakeBackend) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.minedNonce, nil
+}
+
+func (f *fakeBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(params.GWei), nil
+}
+
+func (f *fakeBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: big.NewInt(10 * params.GWei)}, nil
+}
+
+func (f *fakeBackend) EstimateGas(ctx context.Context, msg goethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (f *fakeBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, tx)
+	if f.autoMine {
+		f.mineLocked(tx)
+	}
+	return nil
+}
+
+func (f *fakeBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if receipt, ok := f.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, goethereum.NotFound
+}
+
+func (f *fakeBackend) mine(tx *types.Transaction) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mineLocked(tx)
+}
+
+func (f *fakeBackend) mineLocked(tx *types.Transaction) {
+	status := types.ReceiptStatusSuccessful
+	if f.revert {
+		status = types.ReceiptStatusFailed
+	}
+	f.receipts[tx.Hash()] = &types.Receipt{TxHash: tx.Hash(), Status: status}
+	f.minedNonce = max(f.minedNonce, tx.Nonce()+1)
+}
+
+func (f *fakeBackend) sentTransactions() []*types.Transaction {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*types.Transaction{}, f.sent...)
+}
+
+func Test_TransactionManager(t *testing.T) {
+	l, err := logger.NewLogger(&logger.LoggerConfig{Debug: false})
+	require.NoError(t, err)
+
+	privateKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	call := types.NewTx(&types.DynamicFeeTx{To: &to, Data: []byte{0x01, 0x02}})
+
+	newManagerWithMaxFee := func(t *testing.T, backend *fakeBackend, dir string, maxGasFeeCap int64) *TransactionManager {
+		tm, err := NewTransactionManager(backend, &TransactionManagerConfig{
+			ChainId:             config.ChainId_EthereumAnvil,
+			PrivateKey:          privateKey,
+			Dir:                 dir,
+			MaxGasFeeCap:        big.NewInt(maxGasFeeCap),
+			FeeBumpInterval:     20 * time.Millisecond,
+			ReceiptPollInterval: 5 * time.Millisecond,
+		}, l)
+		require.NoError(t, err)
+		return tm
+	}
+	newManager := func(t *testing.T, backend *fakeBackend, dir string) *TransactionManager {
+		return newManagerWithMaxFee(t, backend, dir, 20*params.GWei)
+	}
+
+	t.Run("assigns a different nonce to every concurrent send", func(t *testing.T) {
+		backend := newFakeBackend()
+		tm := newManager(t, backend, "")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := tm.Send(context.Background(), call, "test")
+				assert.NoError(t, err)
+			}()
+		}
+		// nothing is mined until every transaction was sent, so the node's pending nonce never moves
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) >= 5
+		}, time.Second, time.Millisecond)
+		for _, tx := range backend.sentTransactions() {
+			backend.mine(tx)
+		}
+		wg.Wait()
+
+		nonces := make(map[uint64]bool)
+		for _, tx := range backend.sentTransactions() {
+			nonces[tx.Nonce()] = true
+		}
+		assert.Equal(t, map[uint64]bool{0: true, 1: true, 2: true, 3: true, 4: true}, nonces)
+	})
+
+	t.Run("replaces a stuck transaction with higher fees up to the max fee cap", func(t *testing.T) {
+		backend := newFakeBackend()
+		tm := newManager(t, backend, "")
+
+		type result struct {
+			receipt *types.Receipt
+			err     error
+		}
+		done := make(chan result, 1)
+		go func() {
+			receipt, err := tm.Send(context.Background(), call, "test")
+			done <- result{receipt, err}
+		}()
+
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) == 2
+		}, time.Second, time.Millisecond)
+		// at the cap, no further replacements are sent
+		time.Sleep(100 * time.Millisecond)
+		sent := backend.sentTransactions()
+		require.Len(t, sent, 2)
+
+		assert.Equal(t, sent[0].Nonce(), sent[1].Nonce())
+		assert.Equal(t, big.NewInt(16*params.GWei), sent[0].GasFeeCap())
+		assert.Equal(t, big.NewInt(20*params.GWei), sent[1].GasFeeCap())
+		assert.True(t, sent[1].GasTipCap().Cmp(sent[0].GasTipCap()) > 0)
+
+		// the original attempt can still be the one that is mined
+		backend.mine(sent[0])
+		res := <-done
+		require.NoError(t, res.err)
+		assert.Equal(t, sent[0].Hash(), res.receipt.TxHash)
+	})
+
+	t.Run("does not replace a transaction when the max fee cap leaves less than the minimum bump", func(t *testing.T) {
+		backend := newFakeBackend()
+		tm := newManagerWithMaxFee(t, backend, "", 17*params.GWei)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		done := make(chan error, 1)
+		go func() {
+			_, err := tm.Send(ctx, call, "test")
+			done <- err
+		}()
+
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) == 1
+		}, time.Second, time.Millisecond)
+		// 17 gwei is less than 10% above the first attempt's 16 gwei
+		time.Sleep(100 * time.Millisecond)
+		assert.Len(t, backend.sentTransactions(), 1)
+
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("keeps watching a transaction after its sender stops waiting", func(t *testing.T) {
+		dir := t.TempDir()
+		backend := newFakeBackend()
+		tm := newManager(t, backend, dir)
+		managerCtx, stop := context.WithCancel(context.Background())
+		t.Cleanup(stop)
+		require.NoError(t, tm.Start(managerCtx))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := tm.Send(ctx, call, "test")
+			done <- err
+		}()
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) == 1
+		}, time.Second, time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+
+		// the manager keeps bumping the transaction
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) >= 2
+		}, time.Second, time.Millisecond)
+
+		backend.mine(backend.sentTransactions()[1])
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(filepath.Join(dir, "0.json"))
+			return os.IsNotExist(err)
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("returns an error when the transaction reverts", func(t *testing.T) {
+		backend := newFakeBackend()
+		backend.autoMine = true
+		backend.revert = true
+		tm := newManager(t, backend, "")
+
+		_, err := tm.Send(context.Background(), call, "test")
+		assert.ErrorIs(t, err, ErrTransactionFailed)
+	})
+
+	t.Run("resumes pending transactions after a restart", func(t *testing.T) {
+		dir := t.TempDir()
+		backend := newFakeBackend()
+		tm := newManager(t, backend, dir)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := tm.Send(ctx, call, "test")
+			done <- err
+		}()
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) == 1
+		}, time.Second, time.Millisecond)
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+		assert.FileExists(t, filepath.Join(dir, "0.json"))
+
+		restarted := newManager(t, backend, dir)
+		ctx, cancel = context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		require.NoError(t, restarted.Start(ctx))
+
+		// the resumed transaction keeps being bumped
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) >= 2
+		}, time.Second, time.Millisecond)
+
+		backend.mine(backend.sentTransactions()[0])
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(filepath.Join(dir, "0.json"))
+			return os.IsNotExist(err)
+		}, time.Second, time.Millisecond)
+
+		backend.mu.Lock()
+		backend.autoMine = true
+		backend.mu.Unlock()
+		_, err := restarted.Send(context.Background(), call, "test")
+		require.NoError(t, err)
+		sent := backend.sentTransactions()
+		assert.Equal(t, uint64(1), sent[len(sent)-1].Nonce())
+	})
+
+	t.Run("gives up when another transaction used the nonce", func(t *testing.T) {
+		backend := newFakeBackend()
+		tm := newManager(t, backend, "")
+
+		done := make(chan error, 1)
+		go func() {
+			_, err := tm.Send(context.Background(), call, "test")
+			done <- err
+		}()
+		require.Eventually(t, func() bool {
+			return len(backend.sentTransactions()) == 1
+		}, time.Second, time.Millisecond)
+
+		backend.mu.Lock()
+		backend.minedNonce = 1
+		backend.mu.Unlock()
+		assert.ErrorIs(t, <-done, ErrNonceConsumed)
+	})
+}
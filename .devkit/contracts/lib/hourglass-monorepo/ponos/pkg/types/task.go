@@ -9,7 +9,6 @@ import (
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/peering"
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/transactionLogParser/log"
 	"github.com/ethereum/go-ethereum/common"
-	"math/big"
 	"strings"
 	"time"
 )
@@ -104,8 +103,7 @@ func NewTaskFromLog(log *log.DecodedLog, block *ethereum.EthereumBlock, inboxAdd
 	if err := json.Unmarshal(outputBytes, &od); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal output data: %w", err)
 	}
-	parsedTaskDeadline := new(big.Int).SetUint64(od.TaskDeadline)
-	taskDeadlineTime := time.Now().Add(time.Duration(parsedTaskDeadline.Int64()) * time.Second)
+	taskDeadlineTime := TaskDeadlineFromBlock(od.TaskDeadline, block)
 
 	return &Task{
 		TaskId:              taskId,
@@ -119,3 +117,20 @@ func NewTaskFromLog(log *log.DecodedLog, block *ethereum.EthereumBlock, inboxAdd
 		BlockHash:           block.Hash.Value(),
 	}, nil
 }
+
+// TaskDeadlineFromBlock anchors the deadline of a task to the timestamp of the block it was created
+// in, so a task processed late (e.g. while backfilling) does not get a fresh deadline.
+//
+// The TaskMailbox emits the deadline as a unix timestamp (block timestamp + task SLA). A value
+// earlier than the block timestamp is taken as an SLA in seconds from the block timestamp. Blocks
+// without a timestamp fall back to the current time.
+func TaskDeadlineFromBlock(taskDeadline uint64, block *ethereum.EthereumBlock) time.Time {
+	blockTime := time.Now()
+	if block != nil && block.Timestamp.Value() > 0 {
+		blockTime = time.Unix(int64(block.Timestamp.Value()), 0)
+	}
+	if taskDeadline >= uint64(blockTime.Unix()) {
+		return time.Unix(int64(taskDeadline), 0)
+	}
+	return blockTime.Add(time.Duration(taskDeadline) * time.Second)
+}
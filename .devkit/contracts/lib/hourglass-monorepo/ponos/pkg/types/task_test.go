@@ -0,0 +1,34 @@
+package types
+
+import (
+	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/clients/ethereum"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func Test_TaskDeadlineFromBlock(t *testing.T) {
+	blockTime := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	block := &ethereum.EthereumBlock{Timestamp: ethereum.EthereumQuantity(blockTime.Unix())}
+
+	t.Run("uses the unix timestamp emitted by the mailbox", func(t *testing.T) {
+		deadline := TaskDeadlineFromBlock(uint64(blockTime.Add(time.Minute).Unix()), block)
+		assert.Equal(t, blockTime.Add(time.Minute).Unix(), deadline.Unix())
+	})
+
+	t.Run("adds an SLA in seconds to the block timestamp", func(t *testing.T) {
+		deadline := TaskDeadlineFromBlock(60, block)
+		assert.Equal(t, blockTime.Add(time.Minute).Unix(), deadline.Unix())
+	})
+
+	t.Run("does not depend on when the task is processed", func(t *testing.T) {
+		deadline := TaskDeadlineFromBlock(60, block)
+		assert.True(t, deadline.Before(time.Now()))
+	})
+
+	t.Run("falls back to the current time for blocks without a timestamp", func(t *testing.T) {
+		before := time.Now()
+		deadline := TaskDeadlineFromBlock(60, &ethereum.EthereumBlock{})
+		assert.False(t, deadline.Before(before.Add(time.Minute).Truncate(time.Second)))
+	})
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/Layr-Labs/hourglass-monorepo/ponos/pkg/performer/server"
@@ -62,14 +64,36 @@ func (tw *TaskWorker) HandleTask(t *performerV1.TaskRequest) (*performerV1.TaskR
 	}, nil
 }
 
+// performerPortEnvVar is set by executors that run the performer as a local process to the port
+// they expect it to listen on
+const performerPortEnvVar = "PERFORMER_PORT"
+
+// performerPort returns the port to listen on, defaulting to 8080 when PERFORMER_PORT is not set
+func performerPort() (int, error) {
+	portStr := os.Getenv(performerPortEnvVar)
+	if portStr == "" {
+		return 8080, nil
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s '%s': %w", performerPortEnvVar, portStr, err)
+	}
+	return port, nil
+}
+
 func main() {
 	ctx := context.Background()
 	l, _ := zap.NewProduction()
 
 	w := NewTaskWorker(l)
 
+	port, err := performerPort()
+	if err != nil {
+		panic(err)
+	}
+
 	pp, err := server.NewPonosPerformerWithRpcServer(&server.PonosPerformerConfig{
-		Port:    8080,
+		Port:    port,
 		Timeout: 5 * time.Second,
 	}, w, l)
 	if err != nil {
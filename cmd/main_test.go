@@ -37,3 +37,20 @@ func Test_TaskRequestPayload(t *testing.T) {
 
 	t.Logf("Response: %v", resp)
 }
+
+func Test_PerformerPort(t *testing.T) {
+	t.Setenv(performerPortEnvVar, "")
+	if port, err := performerPort(); err != nil || port != 8080 {
+		t.Errorf("expected default port 8080, got %d (%v)", port, err)
+	}
+
+	t.Setenv(performerPortEnvVar, "9123")
+	if port, err := performerPort(); err != nil || port != 9123 {
+		t.Errorf("expected port 9123, got %d (%v)", port, err)
+	}
+
+	t.Setenv(performerPortEnvVar, "not-a-port")
+	if _, err := performerPort(); err == nil {
+		t.Errorf("expected an error for an invalid port")
+	}
+}